@@ -0,0 +1,80 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package clipboard gives ivy access to the system clipboard on desktop
+// platforms by running the platform's clipboard commands.
+package clipboard // import "robpike.io/ivy/clipboard"
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// System is a config.Clipboard that uses the system clipboard.
+type System struct{}
+
+// A command is a way to copy to and paste from the clipboard.
+type command struct {
+	copy, paste []string
+}
+
+// commands returns the clipboard commands to try on this system, in order.
+func commands() []command {
+	switch runtime.GOOS {
+	case "darwin":
+		return []command{{[]string{"pbcopy"}, []string{"pbpaste"}}}
+	case "windows":
+		return []command{{[]string{"clip"}, []string{"powershell", "-NoProfile", "-Command", "Get-Clipboard"}}}
+	}
+	var cmds []command
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, command{[]string{"wl-copy"}, []string{"wl-paste", "--no-newline"}})
+	}
+	return append(cmds,
+		command{[]string{"xclip", "-selection", "clipboard"}, []string{"xclip", "-selection", "clipboard", "-o"}},
+		command{[]string{"xsel", "--clipboard", "--input"}, []string{"xsel", "--clipboard", "--output"}},
+	)
+}
+
+// find returns the first of the clipboard commands that is installed.
+func find() (command, error) {
+	for _, c := range commands() {
+		if _, err := exec.LookPath(c.copy[0]); err == nil {
+			return c, nil
+		}
+	}
+	return command{}, errors.New("no clipboard command found")
+}
+
+// Copy puts the text on the clipboard.
+func (System) Copy(text string) error {
+	c, err := find()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(c.copy[0], c.copy[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// Paste returns the text on the clipboard.
+func (System) Paste() (string, error) {
+	c, err := find()
+	if err != nil {
+		return "", err
+	}
+	out, err := exec.Command(c.paste[0], c.paste[1:]...).Output()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "windows" {
+		// Get-Clipboard adds a line ending.
+		out = bytes.TrimSuffix(out, []byte("\r\n"))
+	}
+	return string(out), nil
+}
@@ -5,25 +5,32 @@
 package config // import "robpike.io/ivy/config"
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"math/big"
 	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Order here determines order in the Config.debug array.
 var DebugFlags = [...]string{
 	"cpu",
+	"fold",
+	"mem",
 	"panic",
 	"parse",
 	"tokens",
 	"trace",
+	"traceback",
 	"types",
 }
 
@@ -45,9 +52,11 @@ type Config struct {
 	traceLevel  int
 	source      *rand.ChaCha8
 	random      *rand.Rand
+	streams     map[string]*rand.ChaCha8 // Named random streams, created on first use.
 	randLock    sync.Mutex
 	maxBits     uint           // Maximum length of an integer; 0 means no limit.
 	maxDigits   uint           // Above this size, ints print in floating format.
+	maxElems    uint           // Maximum elements in a vector or matrix; 0 means no limit.
 	maxStack    uint           // Maximum call stack depth.
 	floatPrec   uint           // Length of mantissa of a BigFloat.
 	realTime    time.Duration  // Elapsed time of last interactive command.
@@ -58,10 +67,44 @@ type Config struct {
 	// Bases: 0 means C-like, base 10 with 07 for octal and 0xa for hex.
 	inputBase  int
 	outputBase int
-	mobile     bool // Running on a mobile platform.
-}
-
+	mobile     bool      // Running on a mobile platform.
+	strict     bool      // Ops must declare the globals they read.
+	maxRows    int       // Rows of a matrix to print before eliding; 0 means no limit.
+	maxCols    int       // Columns of a matrix to print before eliding; 0 means no limit.
+	align      []string  // Alignment of matrix columns; the last entry repeats.
+	columnSep  string    // Separator between matrix columns; "" means a blank.
+	tree       bool      // Print nested values as an indented tree.
+	raw        bool      // Print vectors and matrices as plain records, for other programs.
+	ieee       bool      // Floating-point infinities and NaN propagate rather than error.
+	polar      bool      // Print complex numbers as magnitude∠phase.
+	plotter    Plotter   // Draws plots; nil means plotting is unavailable.
+	plotFile   string    // Where plots are written; "" means ivy.svg.
+	clipboard  Clipboard // The system clipboard; nil means none.
+	exec       bool      // Sys "exec" may run commands.
+	network    bool      // Sys "get" may fetch URLs.
+	sandbox    bool      // No access to files, environment, clipboard, or other programs.
+	color      bool      // Highlight interactive input and errors in color.
+	check      bool      // Check the shapes of expressions before evaluating them.
+	passed     int       // Expect operations that have passed.
+	failed     int       // Expect operations that have failed.
+	args       []string  // Arguments for the program, from the command line.
+	history    []string  // Lines of interactive input, oldest first.
+	log        *transcript
+	tempTaken  atomic.Uint64 // Pooled temporaries taken, for )debug mem.
+	tempReused atomic.Uint64 // Of those, the ones that were not allocated.
+	initLock   sync.Mutex
+	ready      atomic.Bool // The defaults have been set.
+}
+
+// init sets the defaults the first time it is called. Contexts forked
+// to evaluate in parallel share the Config, so it may be called from
+// several goroutines at once.
 func (c *Config) init() {
+	if c.ready.Load() {
+		return
+	}
+	c.initLock.Lock()
+	defer c.initLock.Unlock()
 	if c.output == nil {
 		c.output = os.Stdout
 		c.errOutput = os.Stderr
@@ -72,6 +115,7 @@ func (c *Config) init() {
 		c.random = rand.New(c.source)
 		c.maxBits = 1e6
 		c.maxDigits = 1e4
+		c.maxElems = 1e8
 		c.maxStack = 1e5
 		c.floatPrec = 256
 		c.mobile = false
@@ -80,11 +124,15 @@ func (c *Config) init() {
 		c.location = t.Location()
 		c.timeZone, _ = t.Zone()
 	}
+	c.ready.Store(true)
 }
 
 // Output returns the writer to be used for program output.
 func (c *Config) Output() io.Writer {
 	c.init()
+	if c.log != nil {
+		return logWriter{c.output, c.log}
+	}
 	return c.output
 }
 
@@ -97,6 +145,9 @@ func (c *Config) SetOutput(output io.Writer) {
 // ErrOutput returns the writer to be used for error output.
 func (c *Config) ErrOutput() io.Writer {
 	c.init()
+	if c.log != nil {
+		return logWriter{c.errOutput, c.log}
+	}
 	return c.errOutput
 }
 
@@ -256,9 +307,96 @@ func (c *Config) SetRandomSeed(seed uint64) {
 	c.LockRandom()
 	c.seed = seed
 	c.source.Seed(makeSeed(seed)) // All we can do for now.
+	c.streams = nil
 	c.UnlockRandom()
 }
 
+// Stream returns the named random stream, creating it if necessary.
+// Its seed is derived from the random seed and the name, so each name
+// produces its own sequence, independent of the others and of the
+// main generator, and the same each time for a given seed.
+// The caller must hold the lock on the random number generator.
+func (c *Config) Stream(name string) *rand.ChaCha8 {
+	c.init()
+	s := c.streams[name]
+	if s == nil {
+		seed := makeSeed(c.seed)
+		s = rand.NewChaCha8(sha256.Sum256(append(seed[:], name...)))
+		if c.streams == nil {
+			c.streams = make(map[string]*rand.ChaCha8)
+		}
+		c.streams[name] = s
+	}
+	return s
+}
+
+// RandomState returns the state of the random number generator and
+// of the named streams, as text that SetRandomState accepts.
+func (c *Config) RandomState() string {
+	c.init()
+	c.LockRandom()
+	defer c.UnlockRandom()
+	state, _ := c.source.MarshalBinary()
+	text := fmt.Sprintf("%d %x", c.seed, state)
+	names := make([]string, 0, len(c.streams))
+	for name := range c.streams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		state, _ := c.streams[name].MarshalBinary()
+		text += fmt.Sprintf(" %s=%x", hex.EncodeToString([]byte(name)), state)
+	}
+	return text
+}
+
+// SetRandomState restores the state of the random number generator
+// and of the named streams to that reported by RandomState.
+func (c *Config) SetRandomState(text string) error {
+	c.init()
+	bad := fmt.Errorf("invalid random state")
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return bad
+	}
+	seed, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return bad
+	}
+	source := new(rand.ChaCha8)
+	if err := unmarshalHex(source, fields[1]); err != nil {
+		return bad
+	}
+	streams := make(map[string]*rand.ChaCha8)
+	for _, f := range fields[2:] {
+		name, state, ok := strings.Cut(f, "=")
+		n, err := hex.DecodeString(name)
+		if !ok || err != nil {
+			return bad
+		}
+		s := new(rand.ChaCha8)
+		if err := unmarshalHex(s, state); err != nil {
+			return bad
+		}
+		streams[string(n)] = s
+	}
+	c.LockRandom()
+	defer c.UnlockRandom()
+	c.seed = seed
+	*c.source = *source
+	c.streams = streams
+	return nil
+}
+
+// unmarshalHex sets the state of the generator from its hexadecimal encoding.
+func unmarshalHex(s *rand.ChaCha8, state string) error {
+	b, err := hex.DecodeString(state)
+	if err != nil {
+		return err
+	}
+	return s.UnmarshalBinary(b)
+}
+
 // LockRandom serializes access to the random number generator.
 // Needed because pfor can cause concurrent calls to the generator.
 func (c *Config) LockRandom() {
@@ -294,6 +432,18 @@ func (c *Config) SetMaxDigits(digits uint) {
 	c.maxDigits = digits
 }
 
+// MaxElems returns the maximum number of elements in a vector or matrix.
+func (c *Config) MaxElems() uint {
+	c.init()
+	return c.maxElems
+}
+
+// SetMaxElems sets the maximum number of elements in a vector or matrix.
+func (c *Config) SetMaxElems(elems uint) {
+	c.init()
+	c.maxElems = elems
+}
+
 // MaxStack returns the maximum call stack depth.
 func (c *Config) MaxStack() uint {
 	c.init()
@@ -336,19 +486,35 @@ func (c *Config) SetCPUTime(real, user, sys time.Duration) {
 	c.sysTime = sys
 }
 
+// CountTemp records that a pooled temporary was taken, and whether
+// it was reused rather than allocated. It is safe to call from forked
+// contexts evaluating in parallel.
+func (c *Config) CountTemp(reused bool) {
+	c.tempTaken.Add(1)
+	if reused {
+		c.tempReused.Add(1)
+	}
+}
+
+// TempStats returns the number of pooled temporaries taken, and how
+// many of them were reused rather than allocated.
+func (c *Config) TempStats() (taken, reused uint64) {
+	return c.tempTaken.Load(), c.tempReused.Load()
+}
+
 // PrintCPUTime returns a nicely formatted version of the CPU time.
 func (c *Config) PrintCPUTime() string {
 	if c.userTime == 0 && c.sysTime == 0 {
-		return printDuration(c.realTime)
+		return PrintDuration(c.realTime)
 	}
-	return fmt.Sprintf("%s (%s user, %s sys)", printDuration(c.realTime), printDuration(c.userTime), printDuration(c.sysTime))
+	return fmt.Sprintf("%s (%s user, %s sys)", PrintDuration(c.realTime), PrintDuration(c.userTime), PrintDuration(c.sysTime))
 }
 
-// printDuration returns a nice formatting of the duration d,
+// PrintDuration returns a nice formatting of the duration d,
 // with 3 decimal places in whatever unit best fits, but
 // if all the decimals are zero, drop them.
 // The Duration.String method never rounds and is too noisy.
-func printDuration(d time.Duration) string {
+func PrintDuration(d time.Duration) string {
 	switch {
 	case d > time.Minute:
 		m := int(d.Minutes())
@@ -407,6 +573,280 @@ func (c *Config) SetMobile(mobile bool) {
 	c.mobile = mobile
 }
 
+// Strict reports whether user-defined ops must declare, with a
+// :global directive, every global variable they read.
+func (c *Config) Strict() bool {
+	return c.strict
+}
+
+// SetStrict sets the Strict bit as specified.
+func (c *Config) SetStrict(strict bool) {
+	c.init()
+	c.strict = strict
+}
+
+// MaxRows returns the number of rows of a matrix to print before eliding
+// the middle ones. Zero means no limit.
+func (c *Config) MaxRows() int {
+	return c.maxRows
+}
+
+// SetMaxRows sets the number of rows of a matrix to print before eliding.
+func (c *Config) SetMaxRows(rows int) {
+	c.init()
+	c.maxRows = rows
+}
+
+// MaxCols returns the number of columns of a matrix, or elements of a
+// vector, to print before eliding the middle ones. Zero means no limit.
+func (c *Config) MaxCols() int {
+	return c.maxCols
+}
+
+// SetMaxCols sets the number of columns of a matrix to print before eliding.
+func (c *Config) SetMaxCols(cols int) {
+	c.init()
+	c.maxCols = cols
+}
+
+// Align returns the alignment of the columns of a printed matrix, each
+// "left", "right", or "center". The last entry applies to all remaining
+// columns; an empty slice means all are right-aligned.
+func (c *Config) Align() []string {
+	return c.align
+}
+
+// SetAlign sets the alignment of the columns of a printed matrix.
+func (c *Config) SetAlign(align []string) {
+	c.init()
+	c.align = align
+}
+
+// ColumnAlign returns the alignment of column i of a printed matrix.
+func (c *Config) ColumnAlign(i int) string {
+	switch {
+	case len(c.align) == 0:
+		return "right"
+	case i < len(c.align):
+		return c.align[i]
+	}
+	return c.align[len(c.align)-1]
+}
+
+// ColumnSep returns the separator between the columns of a printed matrix.
+func (c *Config) ColumnSep() string {
+	if c.columnSep == "" {
+		return " "
+	}
+	return c.columnSep
+}
+
+// SetColumnSep sets the separator between the columns of a printed matrix.
+func (c *Config) SetColumnSep(sep string) {
+	c.init()
+	c.columnSep = sep
+}
+
+// Tree reports whether nested values print as an indented tree.
+func (c *Config) Tree() bool {
+	return c.tree
+}
+
+// SetTree sets whether nested values print as an indented tree.
+func (c *Config) SetTree(tree bool) {
+	c.init()
+	c.tree = tree
+}
+
+// Raw reports whether vectors and matrices print as plain records.
+func (c *Config) Raw() bool {
+	return c.raw
+}
+
+// SetRaw sets whether vectors and matrices print as plain records.
+func (c *Config) SetRaw(raw bool) {
+	c.init()
+	c.raw = raw
+}
+
+// IEEE reports whether floating-point infinities and NaN propagate
+// through arithmetic, as in IEEE 754, rather than causing errors.
+func (c *Config) IEEE() bool {
+	return c.ieee
+}
+
+// SetIEEE sets whether floating-point infinities and NaN propagate.
+func (c *Config) SetIEEE(ieee bool) {
+	c.init()
+	c.ieee = ieee
+}
+
+// Polar reports whether complex numbers print in polar form, as
+// magnitude∠phase.
+func (c *Config) Polar() bool {
+	return c.polar
+}
+
+// SetPolar sets whether complex numbers print in polar form.
+func (c *Config) SetPolar(polar bool) {
+	c.init()
+	c.polar = polar
+}
+
+// A Plotter draws a plot for the plot operator. Each of ys is a series
+// of values to draw against xs, which has the same length. The Plotter
+// may choose the form of the output, for example SVG or a gnuplot
+// script, from the extension of the file name.
+type Plotter interface {
+	Plot(file string, xs []float64, ys [][]float64) error
+}
+
+// Plotter returns the Plotter used by the plot operator, or nil if
+// plotting is not available.
+func (c *Config) Plotter() Plotter {
+	return c.plotter
+}
+
+// SetPlotter sets the Plotter used by the plot operator.
+func (c *Config) SetPlotter(p Plotter) {
+	c.init()
+	c.plotter = p
+}
+
+// PlotFile returns the name of the file to which plots are written.
+func (c *Config) PlotFile() string {
+	if c.plotFile == "" {
+		return "ivy.svg"
+	}
+	return c.plotFile
+}
+
+// SetPlotFile sets the name of the file to which plots are written.
+func (c *Config) SetPlotFile(file string) {
+	c.init()
+	c.plotFile = file
+}
+
+// A Clipboard gives access to the system clipboard for )copy and sys "paste".
+type Clipboard interface {
+	Copy(text string) error
+	Paste() (string, error)
+}
+
+// Clipboard returns the system clipboard, or nil if there is none.
+func (c *Config) Clipboard() Clipboard {
+	return c.clipboard
+}
+
+// SetClipboard sets the system clipboard.
+func (c *Config) SetClipboard(clip Clipboard) {
+	c.init()
+	c.clipboard = clip
+}
+
+// Exec reports whether sys "exec" may run external commands.
+// It is off by default so programs that embed ivy do not
+// give their users a shell unless they choose to.
+func (c *Config) Exec() bool {
+	return c.exec
+}
+
+// SetExec sets whether sys "exec" may run external commands.
+func (c *Config) SetExec(exec bool) {
+	c.init()
+	c.exec = exec
+}
+
+// Network reports whether sys "get" may fetch data over the network.
+// Like Exec, it is off by default.
+func (c *Config) Network() bool {
+	return c.network
+}
+
+// SetNetwork sets whether sys "get" may fetch data over the network.
+func (c *Config) SetNetwork(network bool) {
+	c.init()
+	c.network = network
+}
+
+// Sandbox reports whether ivy is confined to computation, as for the
+// clients of a server: special commands and sys calls that reach files,
+// the environment, the clipboard, an editor, other programs, or the
+// network are refused, whatever the other settings.
+func (c *Config) Sandbox() bool {
+	return c.sandbox
+}
+
+// SetSandbox sets whether ivy is confined to computation.
+func (c *Config) SetSandbox(sandbox bool) {
+	c.init()
+	c.sandbox = sandbox
+}
+
+// Color reports whether interactive input and error messages
+// are highlighted using ANSI terminal colors.
+func (c *Config) Color() bool {
+	return c.color
+}
+
+// SetColor sets whether to highlight in color.
+func (c *Config) SetColor(color bool) {
+	c.init()
+	c.color = color
+}
+
+// Check reports whether expressions are checked for mismatched shapes
+// and oversized results before they are evaluated.
+func (c *Config) Check() bool {
+	return c.check
+}
+
+// SetCheck sets whether to check expressions before evaluating them.
+func (c *Config) SetCheck(check bool) {
+	c.init()
+	c.check = check
+}
+
+// Args returns the arguments given to the ivy program being run,
+// as reported by sys "args".
+func (c *Config) Args() []string {
+	return c.args
+}
+
+// SetArgs sets the arguments given to the ivy program being run.
+func (c *Config) SetArgs(args []string) {
+	c.init()
+	c.args = args
+}
+
+// Expectations returns the number of expect operations that have
+// passed and failed.
+func (c *Config) Expectations() (passed, failed int) {
+	return c.passed, c.failed
+}
+
+// RecordExpectation records the outcome of an expect operation.
+func (c *Config) RecordExpectation(ok bool) {
+	c.init()
+	if ok {
+		c.passed++
+	} else {
+		c.failed++
+	}
+}
+
+// History returns the recorded lines of interactive input, oldest first.
+// The caller must not modify the returned slice.
+func (c *Config) History() []string {
+	return c.history
+}
+
+// AddHistory appends a line to the recorded interactive input.
+func (c *Config) AddHistory(line string) {
+	c.init()
+	c.history = append(c.history, line)
+}
+
 // TimeZone returns the default time zone name.
 func (c *Config) TimeZone() string {
 	return c.timeZone
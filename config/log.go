@@ -0,0 +1,109 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// A transcript records input and output in a log file. Each line of
+// input is preceded by a comment holding the time it was read, and
+// output is indented by a tab, so a log reads much like the files
+// in ivy's testdata directory.
+type transcript struct {
+	mu        sync.Mutex // Output may come from ops evaluated in parallel.
+	name      string
+	w         io.WriteCloser
+	midOutput bool // Output has been written without a trailing newline.
+}
+
+// input records a line of input.
+func (t *transcript) input(line string) {
+	if t.midOutput {
+		io.WriteString(t.w, "\n")
+		t.midOutput = false
+	}
+	io.WriteString(t.w, "# "+time.Now().Format(time.DateTime)+"\n")
+	io.WriteString(t.w, line)
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		io.WriteString(t.w, "\n")
+	}
+}
+
+// output records output text, indenting each line.
+func (t *transcript) output(p []byte) {
+	for len(p) > 0 {
+		if !t.midOutput && p[0] != '\n' {
+			io.WriteString(t.w, "\t")
+			t.midOutput = true
+		}
+		i := 0
+		for i < len(p) && p[i] != '\n' {
+			i++
+		}
+		if i < len(p) {
+			i++ // Include the newline.
+			t.midOutput = false
+		}
+		t.w.Write(p[:i])
+		p = p[i:]
+	}
+}
+
+// logWriter is an io.Writer that copies what it writes to a transcript.
+type logWriter struct {
+	w   io.Writer
+	log *transcript
+}
+
+func (l logWriter) Write(p []byte) (int, error) {
+	l.log.mu.Lock()
+	defer l.log.mu.Unlock()
+	l.log.output(p)
+	return l.w.Write(p)
+}
+
+// Log returns the name of the log file, or the empty string if
+// logging is off.
+func (c *Config) Log() string {
+	if c.log == nil {
+		return ""
+	}
+	return c.log.name
+}
+
+// SetLog starts recording a transcript of input and output to w,
+// identified by name. If w is nil, logging stops. Any previous
+// log is closed.
+func (c *Config) SetLog(name string, w io.WriteCloser) {
+	c.init()
+	if c.log != nil {
+		c.log.w.Close()
+		c.log = nil
+	}
+	if w != nil {
+		c.log = &transcript{name: name, w: w}
+	}
+}
+
+// UnloggedOutput returns the writer for program output, such as the
+// prompt, that is meant for the terminal and not for the log.
+func (c *Config) UnloggedOutput() io.Writer {
+	c.init()
+	return c.output
+}
+
+// LogInput records a line of interactive input in the log, if logging
+// is on. Input read from files, as by )get, is not logged; its effects
+// appear in the output.
+func (c *Config) LogInput(line string) {
+	if c.log != nil {
+		c.log.mu.Lock()
+		defer c.log.mu.Unlock()
+		c.log.input(line)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package exec
+
+import (
+	"robpike.io/ivy/value"
+)
+
+// Op bodies are compiled into closures the first time they are run.
+// Compiling decides once what the tree walker decides on every
+// evaluation: whether a node is a constant, which local a variable
+// names, and which builtin implements an operator. Since a user may
+// define an op with the name of a builtin at any time, the builtin is
+// called directly only while no such op exists.
+//
+// Compiled code is used only when the body is not being traced,
+// profiled, or debugged; those use the tree walker, value.EvalFunctionBody.
+
+// code evaluates a compiled expression in the context.
+type code func(c *Context) value.Value
+
+// compiled reports whether fn's body may be run as compiled code.
+func (c *Context) compiled(fn *Function) bool {
+	return !c.prof.on && !c.config.Tracing(1) && !c.debugging(fn)
+}
+
+// code returns fn's compiled body, compiling it on first use.
+func (fn *Function) code() code {
+	fn.compileOnce.Do(func() {
+		fn.compiled = compileBody(fn.Name, fn.Body)
+	})
+	return fn.compiled
+}
+
+// compileBody compiles the statements of an op body, which may
+// include conditionals that return a value.
+func compileBody(name string, body []value.Expr) code {
+	type stmt struct {
+		cond, expr code
+	}
+	stmts := make([]stmt, len(body))
+	for i, e := range body {
+		if d, ok := e.(value.Decomposable); ok && d.Operator() == ":" {
+			left, right := d.Operands()
+			stmts[i] = stmt{compile(left), compile(right)}
+			continue
+		}
+		stmts[i] = stmt{nil, compile(e)}
+	}
+	return func(c *Context) value.Value {
+		var v value.Value
+		for _, s := range stmts {
+			if s.cond != nil {
+				if value.IsTrue(name, s.cond(c)) {
+					return s.expr(c)
+				}
+				continue
+			}
+			v = s.expr(c)
+		}
+		return v
+	}
+}
+
+// compile compiles the expression. Anything it does not handle
+// is left to the tree walker.
+func compile(expr value.Expr) code {
+	switch e := expr.(type) {
+	case value.Char, value.Int, value.BigInt, value.BigRat, value.BigFloat, value.Complex:
+		v := e.(value.Value)
+		return func(*Context) value.Value { return v }
+	case *value.ConstExpr:
+		v := e.Value
+		return func(*Context) value.Value { return v }
+	case *value.VarExpr:
+		if e.Local >= 1 {
+			i := e.Local
+			return func(c *Context) value.Value {
+				if v := c.Local(i).Value(); v != nil {
+					return v
+				}
+				return e.Eval(c) // Reports the error.
+			}
+		}
+	case *value.UnaryExpr:
+		if e.Axis != nil {
+			break
+		}
+		op, right := e.Op, compile(e.Right)
+		if builtin := value.UnaryOps[op]; builtin != nil {
+			return func(c *Context) value.Value {
+				v := right(c).Inner()
+				if c.UnaryFn[op] != nil {
+					return c.EvalUnary(op, v)
+				}
+				return builtin.EvalUnary(c, v)
+			}
+		}
+		return func(c *Context) value.Value {
+			return c.EvalUnary(op, right(c).Inner())
+		}
+	case *value.BinaryExpr:
+		if e.Axis != nil || e.Op == "=" || e.Op == "ifelse" || e.Op == "&&" || e.Op == "||" {
+			break // The tree walker handles assignment and lazy evaluation.
+		}
+		op, left, right := e.Op, compile(e.Left), compile(e.Right)
+		// The equality operators have special handling for Char.
+		if builtin := value.BinaryOps[op]; builtin != nil && op != "==" && op != "!=" {
+			return func(c *Context) value.Value {
+				rhs := right(c).Inner()
+				lhs := left(c)
+				if c.BinaryFn[op] != nil {
+					return c.EvalBinary(lhs, op, rhs)
+				}
+				return builtin.EvalBinary(c, lhs, rhs)
+			}
+		}
+		return func(c *Context) value.Value {
+			rhs := right(c).Inner()
+			return c.EvalBinary(left(c), op, rhs)
+		}
+	}
+	return func(c *Context) value.Value {
+		return expr.Eval(c)
+	}
+}
@@ -5,6 +5,8 @@
 package exec // import "robpike.io/ivy/exec"
 
 import (
+	"io"
+	"slices"
 	"strings"
 
 	"robpike.io/ivy/config"
@@ -22,30 +24,47 @@ type Context struct {
 	// Accessed through the value.Context Config method.
 	config *config.Config
 
-	frameSizes []int // size of each stack frame on the call stack
+	frameSizes []int       // size of each stack frame on the call stack
+	callers    []*Function // the op for each stack frame
 	stack      []*value.Var
 
+	// DebugInput is the source of commands when the debugger pauses.
+	// If it is nil, the debugger reports its state but does not pause.
+	DebugInput io.ByteReader
+	debug      debugger
+	prof       profiler
+
 	Globals Symtab
 
 	//  UnaryFn maps the names of unary functions (ops) to their implementations.
 	UnaryFn map[string]*Function
 	//  BinaryFn maps the names of binary functions (ops) to their implementations.
 	BinaryFn map[string]*Function
+	// UnaryInverse and BinaryInverse map the names of user-defined ops
+	// to the user-defined inverses of those ops, if any.
+	UnaryInverse  map[string]*Function
+	BinaryInverse map[string]*Function
 	// Defs is a list of defined ops, in time order.  It is used when saving the
 	// Context to a file.
 	Defs []OpDef
 	// Names of variables declared in the currently-being-parsed function.
 	variables []string
+	// undo holds the state before the last top-level statement.
+	undo *snapshot
+	// workspaces holds the states saved by )ws save.
+	workspaces map[string]*snapshot
 }
 
 // NewContext returns a new execution context: the stack and variables,
 // plus the execution configuration.
 func NewContext(conf *config.Config) value.Context {
 	c := &Context{
-		config:   conf,
-		Globals:  make(Symtab),
-		UnaryFn:  make(map[string]*Function),
-		BinaryFn: make(map[string]*Function),
+		config:        conf,
+		Globals:       make(Symtab),
+		UnaryFn:       make(map[string]*Function),
+		BinaryFn:      make(map[string]*Function),
+		UnaryInverse:  make(map[string]*Function),
+		BinaryInverse: make(map[string]*Function),
 	}
 	c.SetConstants()
 	return c
@@ -95,6 +114,7 @@ func (c *Context) push(fn *Function) {
 		c.stack = append(c.stack[:cap(c.stack)], nil)
 	}
 	c.frameSizes = append(c.frameSizes, len(fn.Locals))
+	c.callers = append(c.callers, fn)
 	c.stack = c.stack[:n+len(fn.Locals)]
 }
 
@@ -102,6 +122,7 @@ func (c *Context) push(fn *Function) {
 func (c *Context) pop() {
 	n := c.frameSizes[len(c.frameSizes)-1]
 	c.frameSizes = c.frameSizes[:len(c.frameSizes)-1]
+	c.callers = c.callers[:len(c.callers)-1]
 	c.stack = c.stack[:len(c.stack)-n]
 }
 
@@ -120,6 +141,15 @@ func (c *Context) TraceIndent() string {
 func (c *Context) Eval(exprs []value.Expr) []value.Value {
 	var values []value.Value
 	for _, expr := range exprs {
+		// As in an op, a true conditional yields its value and
+		// ends the line; a false one yields nothing.
+		if d, ok := expr.(value.Decomposable); ok && d.Operator() == ":" {
+			if v := value.EvalFunctionBody(c, "", []value.Expr{expr}); v != nil {
+				values = append(values, v)
+				break
+			}
+			continue
+		}
 		v := expr.Eval(c)
 		if v != nil {
 			values = append(values, v)
@@ -130,6 +160,17 @@ func (c *Context) Eval(exprs []value.Expr) []value.Value {
 
 // EvalUnary evaluates a unary operator, including reductions and scans.
 func (c *Context) EvalUnary(op string, right value.Value) value.Value {
+	if c.prof.on {
+		defer c.profileStart(op, false)()
+	}
+	if i := strings.LastIndex(op, " under "); i >= 0 {
+		// f under g: inverse g f g right.
+		f, g := op[:i], op[i+len(" under "):]
+		return c.EvalUnary("inverse "+g, c.EvalUnary(f, c.EvalUnary(g, right)))
+	}
+	if name, ok := strings.CutPrefix(op, "inverse "); ok {
+		return c.evalInverseUnary(name, right)
+	}
 	if len(op) > 1 {
 		switch op[len(op)-1] {
 		case '/':
@@ -151,6 +192,9 @@ func (c *Context) EvalUnary(op string, right value.Value) value.Value {
 			}
 		case '@':
 			value.TraceUnary(c, 2, op, right)
+			if strings.HasSuffix(op, "#@") {
+				return value.EachIndex(c, op[:len(op)-2], right)
+			}
 			return value.Each(c, op, right)
 		}
 	}
@@ -164,6 +208,21 @@ func (c *Context) EvalUnary(op string, right value.Value) value.Value {
 	return fn.EvalUnary(c, right)
 }
 
+// evalInverseUnary applies the inverse of the unary op to right.
+func (c *Context) evalInverseUnary(op string, right value.Value) value.Value {
+	if name, ok := strings.CutPrefix(op, "inverse "); ok {
+		return c.EvalUnary(name, right) // The inverse of an inverse.
+	}
+	if fn := c.UnaryInverse[op]; fn != nil {
+		value.TraceUnary(c, 1, "inverse "+op, right)
+		return fn.EvalUnary(c, right)
+	}
+	if c.UnaryFn[op] != nil {
+		value.Errorf("no inverse for unary %s", op)
+	}
+	return value.InverseUnary(c, op, right)
+}
+
 func (c *Context) unary(op string) (fn value.UnaryOp, userDefined bool) {
 	userFn := c.UnaryFn[op]
 	if userFn != nil {
@@ -183,8 +242,105 @@ func (c *Context) UserDefined(op string, isBinary bool) bool {
 	return c.UnaryFn[op] != nil
 }
 
+// Symbols returns the names of the global variables and user-defined
+// ops, sorted, each appearing once.
+func (c *Context) Symbols() []string {
+	var names []string
+	for name := range c.Globals {
+		names = append(names, name)
+	}
+	for _, def := range c.Defs {
+		names = append(names, def.Name)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// Fork returns a context that shares the configuration, variables,
+// and ops of c but has its own, empty, stack. It is used to evaluate
+// pure ops in parallel. The configuration was initialized by NewContext,
+// and pure ops do not change it, so sharing it is safe; so is writing
+// to its log, which is locked. Reading a global with pending indexed
+// assignments writes it, so Fork completes those first; it must be
+// called before the forked contexts run.
+func (c *Context) Fork() value.Context {
+	for _, v := range c.Globals {
+		v.Value()
+	}
+	return &Context{
+		config:        c.config,
+		Globals:       c.Globals,
+		UnaryFn:       c.UnaryFn,
+		BinaryFn:      c.BinaryFn,
+		UnaryInverse:  c.UnaryInverse,
+		BinaryInverse: c.BinaryInverse,
+		Defs:          c.Defs,
+	}
+}
+
+// Pure reports whether op has no side effects, so it may be evaluated
+// in parallel in forked contexts. A builtin is pure unless value.Impure
+// says otherwise. A user-defined op is pure if its body is, as
+// determined when it was defined, and so are the ops it refers to and
+// their inverses, since those may have been redefined since. Nothing is
+// pure while tracing, profiling, or debugging, which depend on the
+// order of evaluation.
+func (c *Context) Pure(op string, isBinary bool) bool {
+	if c.config.Tracing(1) || c.prof.on || c.debug.stepping || len(c.debug.breaks) > 0 {
+		return false
+	}
+	return c.pure(op, isBinary, make(map[OpDef]bool))
+}
+
+func (c *Context) pure(op string, isBinary bool, seen map[OpDef]bool) bool {
+	if i := strings.LastIndex(op, " under "); i >= 0 {
+		return c.pure(op[:i], isBinary, seen) && c.pure(op[i+len(" under "):], false, seen)
+	}
+	op = strings.TrimPrefix(op, "inverse ")
+	fn, inv := c.UnaryFn[op], c.UnaryInverse[op]
+	if isBinary {
+		fn, inv = c.BinaryFn[op], c.BinaryInverse[op]
+	}
+	if fn == nil {
+		if isBinary {
+			return value.BinaryOps[op] != nil && !value.Impure(op)
+		}
+		return value.UnaryOps[op] != nil && !value.Impure(op)
+	}
+	def := OpDef{Name: op, IsBinary: isBinary}
+	if seen[def] {
+		return true // Recursive; the rest of the body is being checked.
+	}
+	seen[def] = true
+	for _, f := range []*Function{fn, inv} {
+		if f == nil {
+			continue
+		}
+		if !f.Pure {
+			return false
+		}
+		for _, ref := range f.Refs {
+			if !c.pure(ref.Name, ref.IsBinary, seen) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // EvalBinary evaluates a binary operator, including products.
 func (c *Context) EvalBinary(left value.Value, op string, right value.Value) value.Value {
+	if c.prof.on {
+		defer c.profileStart(op, true)()
+	}
+	if i := strings.LastIndex(op, " under "); i >= 0 {
+		// A f under g B: inverse g (g A) f (g B).
+		f, g := op[:i], op[i+len(" under "):]
+		return c.EvalUnary("inverse "+g, c.EvalBinary(c.EvalUnary(g, left), f, c.EvalUnary(g, right)))
+	}
+	if name, ok := strings.CutPrefix(op, "inverse "); ok {
+		return c.evalInverseBinary(left, name, right)
+	}
 	// Special handling for the equal and non-equal operators, which must avoid
 	// type conversions involving Char.
 	if op == "==" || op == "!=" {
@@ -202,6 +358,17 @@ func (c *Context) EvalBinary(left value.Value, op string, right value.Value) val
 		value.TraceBinary(c, 2, left, op, right)
 		return value.Product(c, left, op, right)
 	}
+	if len(op) > 1 {
+		// N-wise reduction: 2 -/ v.
+		switch {
+		case strings.HasSuffix(op, "/"):
+			value.TraceBinary(c, 2, left, op, right)
+			return value.NwiseReduce(c, left, op[:len(op)-1], right)
+		case len(op) > 2 && strings.HasSuffix(op, "/%"):
+			value.TraceBinary(c, 2, left, op, right)
+			return value.NwiseReduceFirst(c, left, op[:len(op)-2], right)
+		}
+	}
 	fn, userDefined := c.binary(op)
 	if fn == nil {
 		value.Errorf("binary %q not implemented", op)
@@ -212,6 +379,21 @@ func (c *Context) EvalBinary(left value.Value, op string, right value.Value) val
 	return fn.EvalBinary(c, left, right)
 }
 
+// evalInverseBinary returns X such that left op X is right.
+func (c *Context) evalInverseBinary(left value.Value, op string, right value.Value) value.Value {
+	if name, ok := strings.CutPrefix(op, "inverse "); ok {
+		return c.EvalBinary(left, name, right) // The inverse of an inverse.
+	}
+	if fn := c.BinaryInverse[op]; fn != nil {
+		value.TraceBinary(c, 1, left, "inverse "+op, right)
+		return fn.EvalBinary(c, left, right)
+	}
+	if c.BinaryFn[op] != nil {
+		value.Errorf("no inverse for binary %s", op)
+	}
+	return value.InverseBinary(c, left, op, right)
+}
+
 func (c *Context) binary(op string) (fn value.BinaryOp, userDefined bool) {
 	user := c.BinaryFn[op]
 	if user != nil {
@@ -254,6 +436,20 @@ func (c *Context) Define(fn *Function) {
 	c.Defs = append(c.Defs, OpDef{fn.Name, fn.IsBinary})
 }
 
+// DefineInverse installs fn as the inverse of the user-defined op
+// of the same name.
+func (c *Context) DefineInverse(fn *Function) {
+	if !c.UserDefined(fn.Name, fn.IsBinary) {
+		value.Errorf("no definition for %s %s", arity(fn.IsBinary), fn.Name)
+	}
+	if fn.IsBinary {
+		c.BinaryInverse[fn.Name] = fn
+	} else {
+		c.UnaryInverse[fn.Name] = fn
+	}
+}
+
+// Undefine removes the definition of the op, and its inverse.
 func (c *Context) Undefine(name string, binary bool) {
 	// Is it already defined?
 	for i, def := range c.Defs {
@@ -262,17 +458,34 @@ func (c *Context) Undefine(name string, binary bool) {
 			c.Defs = append(c.Defs[:i], c.Defs[i+1:]...)
 			if binary {
 				delete(c.BinaryFn, name)
+				delete(c.BinaryInverse, name)
 			} else {
 				delete(c.UnaryFn, name)
+				delete(c.UnaryInverse, name)
 			}
 			return
 		}
 	}
-	ary := "unary"
+	value.Errorf("no definition for %s %s", arity(binary), name)
+}
+
+// UndefineInverse removes the inverse of the op.
+func (c *Context) UndefineInverse(name string, binary bool) {
+	inverses := c.UnaryInverse
+	if binary {
+		inverses = c.BinaryInverse
+	}
+	if inverses[name] == nil {
+		value.Errorf("no inverse for %s %s", arity(binary), name)
+	}
+	delete(inverses, name)
+}
+
+func arity(binary bool) string {
 	if binary {
-		ary = "binary"
+		return "binary"
 	}
-	value.Errorf("no definition for %s %s", ary, name)
+	return "unary"
 }
 
 // noVar guarantees that there is no global variable with that name,
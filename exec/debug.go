@@ -0,0 +1,156 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package exec
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"robpike.io/ivy/value"
+)
+
+// A debugger holds the state for pausing execution of user-defined ops.
+// When execution reaches the first statement of an op with a breakpoint,
+// or any statement while stepping, it pauses and reads commands from
+// DebugInput. If there is no input, it reports where it is and what the
+// locals are, then continues.
+type debugger struct {
+	breaks   map[string]bool // Names of ops with breakpoints.
+	stepping bool            // Pause before every statement.
+}
+
+const debugHelp = `debugger commands:
+	step (or s, or empty line): execute the next statement
+	cont (or c): continue to the next breakpoint
+	locals (or l): print the local variables
+	where (or w): print the stack of active ops
+	quit (or q): abandon the calculation`
+
+// SetBreak sets or clears the breakpoint on the named op.
+func (c *Context) SetBreak(name string, on bool) {
+	if !on {
+		delete(c.debug.breaks, name)
+		return
+	}
+	if c.debug.breaks == nil {
+		c.debug.breaks = make(map[string]bool)
+	}
+	c.debug.breaks[name] = true
+}
+
+// Breaks returns the sorted names of the ops with breakpoints.
+func (c *Context) Breaks() []string {
+	var names []string
+	for name := range c.debug.breaks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetStep sets whether to pause before every statement of every user-defined op.
+func (c *Context) SetStep(on bool) {
+	c.debug.stepping = on
+}
+
+// debugging reports whether the evaluation of fn may pause.
+func (c *Context) debugging(fn *Function) bool {
+	return c.debug.stepping || c.debug.breaks[fn.Name]
+}
+
+// evalBody evaluates the body of fn, whose frame has been pushed,
+// pausing as directed by the debugger.
+func (c *Context) evalBody(fn *Function) value.Value {
+	if c.compiled(fn) {
+		return fn.code()(c)
+	}
+	if !c.debugging(fn) {
+		return value.EvalFunctionBody(c, fn.Name, fn.Body)
+	}
+	var v value.Value
+	for i, stmt := range fn.Body {
+		if c.debug.stepping || (i == 0 && c.debug.breaks[fn.Name]) {
+			c.pause(fn, stmt)
+		}
+		// Evaluate one statement at a time. A conditional returns
+		// a value only if its condition is true.
+		if d, ok := stmt.(value.Decomposable); ok && d.Operator() == ":" {
+			if r := value.EvalFunctionBody(c, fn.Name, fn.Body[i:i+1]); r != nil {
+				return r
+			}
+			continue
+		}
+		v = stmt.Eval(c)
+	}
+	return v
+}
+
+// pause stops before executing stmt in fn and runs debugger commands.
+func (c *Context) pause(fn *Function, stmt value.Expr) {
+	out := c.config.Output()
+	// Indent to show the depth of nested calls; the top level is not indented.
+	fmt.Fprintf(out, "%s[%s] %s\n", c.TraceIndent()[2:], fn.Name, stmt.ProgString())
+	if c.DebugInput == nil {
+		c.printLocals(fn)
+		return
+	}
+	for {
+		fmt.Fprint(out, "debug> ")
+		cmd, err := readDebugLine(c.DebugInput)
+		if err != nil && cmd == "" {
+			value.Errorf("debugger: %v", err)
+		}
+		switch cmd {
+		case "", "s", "step":
+			c.debug.stepping = true
+			return
+		case "c", "cont":
+			c.debug.stepping = false
+			return
+		case "l", "locals":
+			c.printLocals(fn)
+		case "w", "where":
+			for i := len(c.callers) - 1; i >= 0; i-- {
+				fmt.Fprintf(out, "\t%s\n", c.callers[i].Name)
+			}
+		case "q", "quit":
+			c.debug.stepping = false
+			value.Errorf("debugger: calculation abandoned in %q", fn.Name)
+		default:
+			fmt.Fprintln(out, debugHelp)
+		}
+	}
+}
+
+// printLocals prints the values of the local variables in the top frame,
+// which belongs to fn.
+func (c *Context) printLocals(fn *Function) {
+	out := c.config.Output()
+	for i, name := range fn.Locals {
+		v := c.Local(i + 1).Value()
+		if v == nil {
+			fmt.Fprintf(out, "\t%s: <unset>\n", name)
+			continue
+		}
+		fmt.Fprintf(out, "\t%s: %s\n", name, v.Sprint(c.config))
+	}
+}
+
+// readDebugLine reads a line of input, returning it with surrounding spaces removed.
+func readDebugLine(r io.ByteReader) (string, error) {
+	var b strings.Builder
+	for {
+		ch, err := r.ReadByte()
+		if err != nil {
+			return strings.TrimSpace(b.String()), err
+		}
+		if ch == '\n' {
+			return strings.TrimSpace(b.String()), nil
+		}
+		b.WriteByte(ch)
+	}
+}
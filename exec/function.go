@@ -7,6 +7,7 @@ package exec
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"robpike.io/ivy/value"
 )
@@ -20,6 +21,28 @@ type Function struct {
 	Body     []value.Expr
 	Locals   []string
 	Globals  []string
+	// PinOrigin is set by an :origin directive at the start of the body.
+	// The op then runs with the given index origin regardless of the
+	// caller's setting.
+	PinOrigin bool
+	Origin    int
+	// LocalDecls and GlobalDecls are the variables named in :local
+	// and :global directives.
+	LocalDecls  []string
+	GlobalDecls []string
+	// Refs lists, in order of appearance, the user-defined ops
+	// the body referred to when it was defined.
+	Refs []OpDef
+	// Inverse is set if the function is the user-defined inverse
+	// of the op Name.
+	Inverse bool
+	// Pure is set if the body assigns no global variables and uses
+	// no builtin op with side effects. The user-defined ops it
+	// refers to are checked when it is run; see Context.Pure.
+	Pure bool
+
+	compileOnce sync.Once
+	compiled    code // The body, compiled; see compile.go.
 }
 
 // argProgString builds a string representation of arg, to be used in printing the
@@ -47,6 +70,9 @@ func argProgString(b *strings.Builder, arg value.Expr) {
 func (fn *Function) String() string {
 	var b strings.Builder
 	b.WriteString("op ")
+	if fn.Inverse {
+		b.WriteString("inverse ")
+	}
 	if fn.IsBinary {
 		argProgString(&b, fn.Left)
 		b.WriteRune(' ')
@@ -54,10 +80,22 @@ func (fn *Function) String() string {
 	b.WriteString(fn.Name)
 	b.WriteRune(' ')
 	argProgString(&b, fn.Right)
+	if fn.Body == nil {
+		// Declared but not defined.
+		return b.String()
+	}
 	b.WriteString(" = ")
 	if len(fn.Body) == 1 {
+		for _, d := range fn.directives() {
+			b.WriteString(d)
+			b.WriteString("; ")
+		}
 		b.WriteString(fn.Body[0].ProgString())
 	} else {
+		for _, d := range fn.directives() {
+			b.WriteString("\n\t")
+			b.WriteString(d)
+		}
 		for _, stmt := range fn.Body {
 			b.WriteString("\n\t")
 			b.WriteString(stmt.ProgString())
@@ -66,6 +104,46 @@ func (fn *Function) String() string {
 	return b.String()
 }
 
+// directives returns the source text of the directives at the start of fn's body.
+func (fn *Function) directives() []string {
+	var dirs []string
+	if fn.PinOrigin {
+		dirs = append(dirs, fmt.Sprintf(":origin %d", fn.Origin))
+	}
+	if len(fn.LocalDecls) > 0 {
+		dirs = append(dirs, ":local "+strings.Join(fn.LocalDecls, " "))
+	}
+	if len(fn.GlobalDecls) > 0 {
+		dirs = append(dirs, ":global "+strings.Join(fn.GlobalDecls, " "))
+	}
+	return dirs
+}
+
+// addCaller records fn in the call stack of an error passing through it.
+// It must be deferred, as it recovers and re-raises the panic.
+func (fn *Function) addCaller() {
+	err := recover()
+	if err == nil {
+		return
+	}
+	if e, ok := err.(value.Error); ok {
+		e.AddCaller(fn.Name)
+		err = e
+	}
+	panic(err)
+}
+
+// setOrigin sets the index origin pinned by fn, if any, and returns
+// the function that restores the caller's origin.
+func (fn *Function) setOrigin(c *Context) func() {
+	origin := c.config.Origin()
+	if !fn.PinOrigin || origin == fn.Origin {
+		return func() {}
+	}
+	c.config.SetOrigin(fn.Origin)
+	return func() { c.config.SetOrigin(origin) }
+}
+
 func (fn *Function) EvalUnary(context value.Context, right value.Value) value.Value {
 	if fn.Body == nil {
 		value.Errorf("unary %q undefined", fn.Name)
@@ -77,8 +155,10 @@ func (fn *Function) EvalUnary(context value.Context, right value.Value) value.Va
 	}
 	c.push(fn)
 	defer c.pop()
+	defer fn.addCaller()
+	defer fn.setOrigin(c)()
 	value.Assign(context, fn.Right, right, right)
-	v := value.EvalFunctionBody(c, fn.Name, fn.Body)
+	v := c.evalBody(fn)
 	if v == nil {
 		value.Errorf("no value returned by %q", fn.Name)
 	}
@@ -96,9 +176,11 @@ func (fn *Function) EvalBinary(context value.Context, left, right value.Value) v
 	}
 	c.push(fn)
 	defer c.pop()
+	defer fn.addCaller()
+	defer fn.setOrigin(c)()
 	value.Assign(context, fn.Left, left, left)
 	value.Assign(context, fn.Right, right, right)
-	v := value.EvalFunctionBody(c, fn.Name, fn.Body)
+	v := c.evalBody(fn)
 	if v == nil {
 		value.Errorf("no value returned by %q", fn.Name)
 	}
@@ -0,0 +1,112 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package exec
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A profiler records the calls to each operator while profiling is on.
+// Operators may be evaluated in parallel, so access is locked.
+type profiler struct {
+	mu      sync.Mutex
+	on      bool
+	entries map[profileKey]*profileEntry
+}
+
+// profileKey identifies an operator in the profile.
+type profileKey struct {
+	op       string
+	isBinary bool
+}
+
+// profileEntry records the cost of an operator.
+type profileEntry struct {
+	calls  int
+	time   time.Duration // Cumulative, including time in nested operators.
+	active int           // Depth of recursion; only the outermost call is timed.
+	start  time.Time
+}
+
+// SetProfile turns operator profiling on or off. Turning it on
+// discards any previous profile.
+func (c *Context) SetProfile(on bool) {
+	c.prof.mu.Lock()
+	defer c.prof.mu.Unlock()
+	c.prof.on = on
+	if on {
+		c.prof.entries = make(map[profileKey]*profileEntry)
+	}
+}
+
+// Profiling reports whether operator profiling is on.
+func (c *Context) Profiling() bool {
+	return c.prof.on
+}
+
+// profileStart records the start of a call to op and returns
+// the function to call when it is done. Nested calls to an op that is
+// already active are counted but not timed, so recursion does not
+// inflate the time.
+func (c *Context) profileStart(op string, isBinary bool) func() {
+	c.prof.mu.Lock()
+	defer c.prof.mu.Unlock()
+	key := profileKey{op, isBinary}
+	e := c.prof.entries[key]
+	if e == nil {
+		e = new(profileEntry)
+		c.prof.entries[key] = e
+	}
+	e.calls++
+	e.active++
+	if e.active == 1 {
+		e.start = time.Now()
+	}
+	return func() {
+		c.prof.mu.Lock()
+		defer c.prof.mu.Unlock()
+		e.active--
+		if e.active == 0 {
+			e.time += time.Since(e.start)
+		}
+	}
+}
+
+// ProfileReport writes to w the operators called while profiling,
+// sorted by decreasing cumulative time.
+func (c *Context) ProfileReport(w io.Writer) {
+	type row struct {
+		key profileKey
+		*profileEntry
+	}
+	c.prof.mu.Lock()
+	defer c.prof.mu.Unlock()
+	var rows []row
+	for k, e := range c.prof.entries {
+		rows = append(rows, row{k, e})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].time != rows[j].time {
+			return rows[i].time > rows[j].time
+		}
+		return rows[i].key.op < rows[j].key.op
+	})
+	fmt.Fprintf(w, "%-12s %-6s %10s %12s\n", "op", "kind", "calls", "time")
+	for _, r := range rows {
+		kind := "unary"
+		if r.key.isBinary {
+			kind = "binary"
+		}
+		user := ""
+		if c.UserDefined(r.key.op, r.key.isBinary) {
+			user = " (user)"
+		}
+		fmt.Fprintf(w, "%-12s %-6s %10d %12s%s\n", r.key.op, kind, r.calls, r.time.Round(time.Microsecond), user)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package exec
+
+import (
+	"maps"
+	"slices"
+
+	"robpike.io/ivy/value"
+)
+
+// A snapshot holds the variables and ops of a Context, so )undo can
+// restore them as they were before a top-level statement and )ws can
+// switch between workspaces. Values are immutable, so the snapshot
+// need only record which value each variable held.
+type snapshot struct {
+	globals       map[string]value.Value
+	unaryFn       map[string]*Function
+	binaryFn      map[string]*Function
+	unaryInverse  map[string]*Function
+	binaryInverse map[string]*Function
+	defs          []OpDef
+}
+
+// snapshot returns the current variables and ops.
+func (c *Context) snapshot() *snapshot {
+	s := &snapshot{
+		globals:       make(map[string]value.Value, len(c.Globals)),
+		unaryFn:       maps.Clone(c.UnaryFn),
+		binaryFn:      maps.Clone(c.BinaryFn),
+		unaryInverse:  maps.Clone(c.UnaryInverse),
+		binaryInverse: maps.Clone(c.BinaryInverse),
+		defs:          slices.Clone(c.Defs),
+	}
+	for name, v := range c.Globals {
+		s.globals[name] = v.Value()
+	}
+	return s
+}
+
+// restore replaces the variables and ops with those of s,
+// which is unchanged.
+func (c *Context) restore(s *snapshot) {
+	for name := range c.Globals {
+		if _, ok := s.globals[name]; !ok {
+			delete(c.Globals, name)
+		}
+	}
+	for name, val := range s.globals {
+		c.AssignGlobal(name, val)
+	}
+	// The maps may be shared with forked contexts, so update them in place.
+	set := func(dst, src map[string]*Function) {
+		clear(dst)
+		maps.Copy(dst, src)
+	}
+	set(c.UnaryFn, s.unaryFn)
+	set(c.BinaryFn, s.binaryFn)
+	set(c.UnaryInverse, s.unaryInverse)
+	set(c.BinaryInverse, s.binaryInverse)
+	c.Defs = slices.Clone(s.defs)
+}
+
+// Snapshot records the variables and ops, replacing the previous
+// snapshot, for a later Undo.
+func (c *Context) Snapshot() {
+	c.undo = c.snapshot()
+}
+
+// Undo restores the variables and ops recorded by the last Snapshot.
+// It reports whether there was a snapshot to restore; there is none
+// after an Undo until the next Snapshot.
+func (c *Context) Undo() bool {
+	if c.undo == nil {
+		return false
+	}
+	c.restore(c.undo)
+	c.undo = nil
+	return true
+}
+
+// Clear deletes all the variables, all the ops, or both. The constants
+// e and pi are restored by the next SetConstants.
+func (c *Context) Clear(vars, ops bool) {
+	if vars {
+		clear(c.Globals)
+	}
+	if ops {
+		clear(c.UnaryFn)
+		clear(c.BinaryFn)
+		clear(c.UnaryInverse)
+		clear(c.BinaryInverse)
+		c.Defs = nil
+	}
+}
+
+// SaveWorkspace saves the variables and ops in memory under the name.
+func (c *Context) SaveWorkspace(name string) {
+	if c.workspaces == nil {
+		c.workspaces = make(map[string]*snapshot)
+	}
+	c.workspaces[name] = c.snapshot()
+}
+
+// LoadWorkspace replaces the variables and ops with those of the
+// named workspace. It reports whether the workspace exists.
+func (c *Context) LoadWorkspace(name string) bool {
+	s := c.workspaces[name]
+	if s == nil {
+		return false
+	}
+	c.restore(s)
+	return true
+}
+
+// Workspaces returns the names of the saved workspaces, sorted.
+func (c *Context) Workspaces() []string {
+	return slices.Sorted(maps.Keys(c.workspaces))
+}
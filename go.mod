@@ -1,3 +1,5 @@
 module robpike.io/ivy
 
 go 1.23.0
+
+require golang.org/x/text v0.28.0
@@ -9,13 +9,20 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"net"
 	"os"
+	"path/filepath"
 	"runtime/pprof"
+	"slices"
 	"strings"
+	"time"
 
+	"robpike.io/ivy/clipboard"
 	"robpike.io/ivy/config"
 	"robpike.io/ivy/exec"
+	"robpike.io/ivy/jupyter"
 	"robpike.io/ivy/parse"
+	"robpike.io/ivy/plot"
 	"robpike.io/ivy/run"
 	"robpike.io/ivy/scan"
 	"robpike.io/ivy/value"
@@ -29,11 +36,16 @@ var (
 	gformat         = flag.Bool("g", false, `shorthand for -format="%.12g"`)
 	maxbits         = flag.Uint("maxbits", 1e9, "maximum size of an integer, in bits; 0 means no limit")
 	maxdigits       = flag.Uint("maxdigits", 1e4, "above this many `digits`, integers print as floating point; 0 disables")
+	maxelems        = flag.Uint("maxelems", 1e8, "maximum number of `elements` in a vector or matrix; 0 means no limit")
 	maxstack        = flag.Uint("stack", 100000, "maximum call stack `depth` allowed")
 	origin          = flag.Int("origin", 1, "set index origin to `n` (must be >=0)")
 	prompt          = flag.String("prompt", "", "command `prompt`")
+	raw             = flag.Bool("raw", false, "print vectors and matrices plainly, one element or row per line, for other programs")
 	profile         = flag.String("profile", "", "write profile to `file`")
 	debugFlag       = flag.String("debug", "", "comma-separated `names` of debug settings to enable")
+	history         = flag.String("history", defaultHistory(), "save interactive input history in `file`; empty disables")
+	serve           = flag.String("serve", "", "serve evaluation requests on TCP `address`, or - for standard input and output")
+	kernel          = flag.String("kernel", "", "run as a Jupyter kernel using the connection `file`")
 )
 
 var (
@@ -43,7 +55,13 @@ var (
 
 func main() {
 	flag.Usage = usage
-	flag.Parse()
+	// Arguments after -- are for the ivy program, through sys "args".
+	args := os.Args[1:]
+	if i := slices.Index(args, "--"); i >= 0 {
+		conf.SetArgs(args[i+1:])
+		args = args[:i]
+	}
+	flag.CommandLine.Parse(args)
 
 	if *profile != "" {
 		f, err := os.Create(*profile)
@@ -72,9 +90,15 @@ func main() {
 	conf.SetFormat(*format)
 	conf.SetMaxBits(*maxbits)
 	conf.SetMaxDigits(*maxdigits)
+	conf.SetMaxElems(*maxelems)
 	conf.SetMaxStack(*maxstack)
 	conf.SetOrigin(*origin)
 	conf.SetPrompt(*prompt)
+	conf.SetRaw(*raw)
+	conf.SetPlotter(plot.File{})
+	conf.SetClipboard(clipboard.System{})
+	conf.SetExec(true)
+	conf.SetNetwork(true)
 
 	if len(*debugFlag) > 0 {
 		for _, debug := range strings.Split(*debugFlag, ",") {
@@ -85,6 +109,19 @@ func main() {
 		}
 	}
 
+	if *serve != "" {
+		runServer(*serve)
+		return
+	}
+
+	if *kernel != "" {
+		if err := jupyter.Run(*kernel, &conf); err != nil {
+			fmt.Fprintf(os.Stderr, "ivy: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	context = exec.NewContext(&conf)
 
 	if *file != "" {
@@ -115,29 +152,78 @@ func main() {
 		return
 	}
 
-	scanner := scan.New(context, "<stdin>", bufio.NewReader(os.Stdin))
+	editor := run.NewEditor(&conf, os.Stdin, os.Stdout)
+	if editor != nil {
+		editor.Complete = func(line string, pos int) (int, []string) {
+			return run.Complete(context, line, pos)
+		}
+		editor.Highlight = func(line string) string {
+			return run.Highlight(context, line)
+		}
+	}
+	input := run.NewHistory(&conf, os.Stdin, editor, *history)
+	// The debugger reads its commands from the same input.
+	context.(*exec.Context).DebugInput = input
+	scanner := scan.New(context, "<stdin>", input)
 	parser := parse.NewParser("<stdin>", scanner, context)
+	input.SetOnLine(parser.ReloadWatched)
 	for !run.Run(parser, context, true) {
 	}
 }
 
+// runServer runs the evaluation server on the address, or on standard
+// input and output if the address is "-". Clients are sandboxed: they
+// cannot reach the server's files, environment, clipboard, or programs.
+// Network clients also get a configuration of their own.
+func runServer(addr string) {
+	limits := run.ServerLimits{
+		MaxConns:    64,
+		IdleTimeout: 30 * time.Minute,
+		MaxOutput:   1 << 20,
+	}
+	if addr == "-" {
+		run.ServeConn(struct {
+			io.Reader
+			io.Writer
+		}{os.Stdin, os.Stdout}, &conf, limits)
+		return
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ivy: %v\n", err)
+		os.Exit(1)
+	}
+	setup := func(c *config.Config) {
+		c.SetFormat(conf.Format())
+		c.SetMaxBits(conf.MaxBits())
+		c.SetMaxDigits(conf.MaxDigits())
+		c.SetMaxElems(conf.MaxElems())
+		c.SetMaxStack(conf.MaxStack())
+		c.SetOrigin(conf.Origin())
+		c.SetSandbox(true)
+	}
+	err = run.Serve(l, setup, limits)
+	fmt.Fprintf(os.Stderr, "ivy: %v\n", err)
+	os.Exit(1)
+}
+
 // runFile executes the contents of the file as an ivy program.
 func runFile(context value.Context, file string) bool {
-	var fd io.Reader
-	var err error
+	var r io.ByteReader
 	interactive := false
 	if file == "-" {
+		// Standard input is interactive, so it is logged.
 		interactive = true
-		fd = os.Stdin
+		r = run.NewHistory(context.Config(), os.Stdin, nil, "")
 	} else {
-		interactive = false
-		fd, err = os.Open(file)
-	}
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "ivy: %s\n", err)
-		os.Exit(1)
+		fd, err := os.Open(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ivy: %s\n", err)
+			os.Exit(1)
+		}
+		r = bufio.NewReader(fd)
 	}
-	scanner := scan.New(context, file, bufio.NewReader(fd))
+	scanner := scan.New(context, file, r)
 	parser := parse.NewParser(file, scanner, context)
 	return run.Run(parser, context, interactive)
 }
@@ -149,8 +235,18 @@ func runString(context value.Context, str string) bool {
 	return run.Run(parser, context, false)
 }
 
+// defaultHistory returns the default location of the history file,
+// $HOME/.ivy_history, or the empty string if there is no home directory.
+func defaultHistory() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ivy_history")
+}
+
 func usage() {
-	fmt.Fprintf(os.Stderr, "usage: ivy [options] [file ...]\n")
+	fmt.Fprintf(os.Stderr, "usage: ivy [options] [file ...] [-- arg ...]\n")
 	fmt.Fprintf(os.Stderr, "Flags:\n")
 	flag.PrintDefaults()
 	os.Exit(2)
@@ -8,14 +8,20 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"robpike.io/ivy/config"
 	"robpike.io/ivy/exec"
+	"robpike.io/ivy/parse"
 	"robpike.io/ivy/run"
+	"robpike.io/ivy/scan"
 	"robpike.io/ivy/value"
 )
 
@@ -178,12 +184,222 @@ func getText(t *testing.T, fileName string, lineNum int, shouldFail bool, lines
 
 func reset() {
 	testConf.SetFormat("")
+	testConf.SetFloatPrec(256)
 	testConf.SetMaxBits(1e9)
 	testConf.SetMaxDigits(1e4)
+	testConf.SetMaxElems(1e8)
 	testConf.SetMaxStack(100000)
 	testConf.SetOrigin(1)
 	testConf.SetPrompt("")
 	testConf.SetBase(0, 0)
 	testConf.SetRandomSeed(0)
 	testConf.SetLocation("UTC")
+	testConf.SetStrict(false)
+	testConf.SetMaxRows(0)
+	testConf.SetMaxCols(0)
+	testConf.SetAlign(nil)
+	testConf.SetColumnSep("")
+	testConf.SetTree(false)
+	testConf.SetRaw(false)
+	testConf.SetIEEE(false)
+	testConf.SetPolar(false)
+	testConf.SetPlotFile("")
+	testConf.SetClipboard(new(testClipboard))
+	testConf.SetExec(true)
+	testConf.SetNetwork(false)
+	testConf.SetColor(false)
+	testConf.SetCheck(false)
+}
+
+// testClipboard is a clipboard that holds its text in memory.
+type testClipboard struct {
+	text string
+}
+
+func (c *testClipboard) Copy(text string) error {
+	c.text = text
+	return nil
+}
+
+func (c *testClipboard) Paste() (string, error) {
+	return c.text, nil
+}
+
+func TestSysGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/data.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "1,2\n3,4\n")
+	}))
+	defer server.Close()
+	reset()
+	defer reset()
+	testConf.SetNetwork(true)
+	in := fmt.Sprintf("rho sys 'get' '%s/data.csv'\nsys 'get' '%s/missing'", server.URL, server.URL)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	run.Ivy(exec.NewContext(&testConf), in, stdout, stderr)
+	if got := stdout.String(); got != "8\n" {
+		t.Errorf("got %q; want %q", got, "8\n")
+	}
+	if got := stderr.String(); !strings.Contains(got, "404 Not Found") {
+		t.Errorf("got error %q; want 404 Not Found", got)
+	}
+	stdout.Reset()
+	stderr.Reset()
+	in = fmt.Sprintf(")maxelems 7\nsys 'get' '%s/data.csv'", server.URL)
+	run.Ivy(exec.NewContext(&testConf), in, stdout, stderr)
+	if got := stderr.String(); !strings.Contains(got, "response too large (more than 7 bytes)") {
+		t.Errorf("got error %q; want response too large", got)
+	}
+}
+
+// TestEdit runs )edit with the test binary itself as the editor;
+// see TestEditorHelper.
+func TestEdit(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", os.Args[0]+" -test.run=^TestEditorHelper$ --")
+	reset()
+	context := exec.NewContext(&testConf)
+	tests := []struct {
+		edit, in, out, err string
+	}{
+		{"x + x=>x + x + x", "op f x = x + x\nf 3\n)edit f\nf 3", "6\n9\n", ""},
+		// A broken edit is reported, and the next )edit resumes it.
+		{"x + x + x=>(x", ")edit f\nf 3", "edit of f failed; )edit f to resume\n9\n", "f.ivy:1:"},
+		{"(x=>10 * x", ")edit f\nf 3", "30\n", ""},
+	}
+	for _, test := range tests {
+		t.Setenv("IVY_EDITOR_HELPER", test.edit)
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+		run.Ivy(context, test.in, stdout, stderr)
+		if got := stdout.String(); got != test.out {
+			t.Errorf("%s: got %q; want %q", test.edit, got, test.out)
+		}
+		if got := stderr.String(); test.err == "" && got != "" || !strings.Contains(got, test.err) {
+			t.Errorf("%s: got error %q; want %q", test.edit, got, test.err)
+		}
+	}
+}
+
+// TestEditorHelper is the editor for TestEdit. It replaces text in the
+// file it is given as directed by $IVY_EDITOR_HELPER, old=>new.
+func TestEditorHelper(t *testing.T) {
+	edit := os.Getenv("IVY_EDITOR_HELPER")
+	if edit == "" {
+		return
+	}
+	file := os.Args[len(os.Args)-1]
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, new, _ := strings.Cut(edit, "=>")
+	os.WriteFile(file, []byte(strings.Replace(string(data), old, new, 1)), 0600)
+	os.Exit(0)
+}
+
+func TestWatch(t *testing.T) {
+	reset()
+	file := filepath.Join(t.TempDir(), "lib.ivy")
+	if err := os.WriteFile(file, []byte("op f x = x + 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	context := exec.NewContext(&testConf)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	run.Ivy(context, fmt.Sprintf(")watch %q\nf 1", file), stdout, stderr)
+	if got := stdout.String(); got != "2\n" || stderr.Len() > 0 {
+		t.Fatalf("got %q, error %q; want 2", got, stderr)
+	}
+	parser := parse.NewParser("<test>", scan.New(context, "<test>", strings.NewReader("")), context)
+	parser.ReloadWatched() // Unchanged, so nothing happens.
+	if err := os.WriteFile(file, []byte("op f x = x + 10\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Hour) // The file system may not see a change in modification time.
+	if err := os.Chtimes(file, future, future); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	testConf.SetOutput(stdout)
+	parser.ReloadWatched()
+	run.Ivy(context, "f 1\n)watch off", stdout, stderr)
+	if got, want := stdout.String(), "reloading "+file+"\n11\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestBench(t *testing.T) {
+	reset()
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	run.Ivy(exec.NewContext(&testConf), ")bench 'x = +/iota 100; x' 10\nx", stdout, stderr)
+	if stderr.Len() > 0 {
+		t.Fatal(stderr)
+	}
+	pattern := regexp.MustCompile(`^10 runs: min [0-9.]+(µs|ms|s), mean [0-9.]+(µs|ms|s), [0-9]+ allocs/run, [0-9]+ bytes/run\n5050\n$`)
+	if got := stdout.String(); !pattern.MatchString(got) {
+		t.Errorf("got %q; want match for %s", got, pattern)
+	}
+}
+
+// Pure ops run in parallel in forked contexts sharing one configuration,
+// which must be safe. Run with -race.
+func TestForkConfig(t *testing.T) {
+	var conf config.Config
+	context := exec.NewContext(&conf)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	run.Ivy(context, "op a f b = (a*b) + float 1/3\n+/ , (iota 20) o.f iota 20", stdout, stderr)
+	if stderr.Len() > 0 {
+		t.Fatal(stderr)
+	}
+	if got, want := stdout.String(), "44233.3333333\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+// A global with a pending indexed assignment is read by ops evaluated
+// in parallel. Run with -race.
+func TestForkGlobals(t *testing.T) {
+	var conf config.Config
+	context := exec.NewContext(&conf)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	run.Ivy(context, "x = iota 10\nop a f b = a + b + +/x\nx[1] = 7; +/ , (iota 200) o.f iota 200", stdout, stderr)
+	if stderr.Len() > 0 {
+		t.Fatal(stderr)
+	}
+	if got, want := stdout.String(), "10480000\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+// The branches of ifelse are part of the body whose purity decides
+// whether an op may be evaluated in parallel.
+func TestPureIfElse(t *testing.T) {
+	var conf config.Config
+	context := exec.NewContext(&conf)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	run.Ivy(context, "op g x = print x\nop f x = (x > 0) ifelse (g x) 0\nop h x = (x > 0) ifelse (print x) 0\nop k x = (x > 0) ifelse x 0", stdout, stderr)
+	if stderr.Len() > 0 {
+		t.Fatal(stderr)
+	}
+	for _, test := range []struct {
+		op   string
+		pure bool
+	}{
+		{"f", false},
+		{"h", false},
+		{"k", true},
+	} {
+		if got := context.Pure(test.op, false); got != test.pure {
+			t.Errorf("Pure(%q) = %t; want %t", test.op, got, test.pure)
+		}
+	}
 }
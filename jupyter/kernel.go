@@ -0,0 +1,467 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jupyter implements a Jupyter kernel for ivy, so ivy can run in
+// Jupyter notebooks and consoles. To install it, create a directory
+// named ivy in the Jupyter kernels directory (see jupyter kernelspec list)
+// holding a file kernel.json containing
+//
+//	{"argv": ["ivy", "-kernel", "{connection_file}"], "display_name": "Ivy", "language": "ivy"}
+//
+// Each cell is evaluated as ivy input in a single Context that lasts for
+// the life of the kernel. Matrices are also shown as HTML tables, and
+// the notebook's help for the word under the cursor is that of )help.
+package jupyter // import "robpike.io/ivy/jupyter"
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/exec"
+	"robpike.io/ivy/parse"
+	"robpike.io/ivy/run"
+	"robpike.io/ivy/scan"
+	"robpike.io/ivy/value"
+)
+
+// protocolVersion is the version of the Jupyter messaging protocol implemented.
+const protocolVersion = "5.3"
+
+// delimiter separates the routing identities of a message from its body.
+const delimiter = "<IDS|MSG>"
+
+// connectionInfo is the content of the connection file Jupyter gives the kernel.
+type connectionInfo struct {
+	Transport       string `json:"transport"`
+	IP              string `json:"ip"`
+	ShellPort       int    `json:"shell_port"`
+	IOPubPort       int    `json:"iopub_port"`
+	StdinPort       int    `json:"stdin_port"`
+	ControlPort     int    `json:"control_port"`
+	HBPort          int    `json:"hb_port"`
+	Key             string `json:"key"`
+	SignatureScheme string `json:"signature_scheme"`
+}
+
+// header is the header of a message.
+type header struct {
+	MsgID    string `json:"msg_id"`
+	Session  string `json:"session"`
+	Username string `json:"username"`
+	Date     string `json:"date"`
+	MsgType  string `json:"msg_type"`
+	Version  string `json:"version"`
+}
+
+// message is a message received from the client.
+type message struct {
+	ids     [][]byte
+	header  header
+	content json.RawMessage
+}
+
+// A Kernel evaluates the requests of a Jupyter client.
+type Kernel struct {
+	conf     *config.Config
+	context  value.Context
+	key      []byte
+	session  string
+	count    int // Execution count.
+	out      bytes.Buffer
+	errs     bytes.Buffer
+	mu       sync.Mutex // Protects subs.
+	subs     []*zconn   // Peers of the IOPub socket.
+	requests chan request
+}
+
+// request is a message received on the shell or control socket,
+// with the connection to reply on.
+type request struct {
+	z   *zconn
+	msg [][]byte
+}
+
+// New returns a Kernel that evaluates in a new Context using conf and
+// signs its messages with key.
+func New(conf *config.Config, key string) *Kernel {
+	k := &Kernel{
+		conf:     conf,
+		key:      []byte(key),
+		session:  newID(),
+		requests: make(chan request),
+	}
+	conf.SetOutput(&k.out)
+	conf.SetErrOutput(&k.errs)
+	k.context = exec.NewContext(conf)
+	return k
+}
+
+// Run runs a kernel using conf for the Jupyter client described by the
+// connection file. It returns when the client asks the kernel to shut down.
+func Run(connectionFile string, conf *config.Config) error {
+	data, err := os.ReadFile(connectionFile)
+	if err != nil {
+		return err
+	}
+	var info connectionInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return fmt.Errorf("%s: %v", connectionFile, err)
+	}
+	if info.Transport != "tcp" {
+		return fmt.Errorf("unsupported transport %q", info.Transport)
+	}
+	if info.Key != "" && info.SignatureScheme != "hmac-sha256" {
+		return fmt.Errorf("unsupported signature scheme %q", info.SignatureScheme)
+	}
+	k := New(conf, info.Key)
+	listen := func(port int) (net.Listener, error) {
+		return net.Listen("tcp", net.JoinHostPort(info.IP, fmt.Sprint(port)))
+	}
+	sockets := []struct {
+		port       int
+		socketType string
+		serve      func(*zconn)
+	}{
+		{info.ShellPort, "ROUTER", k.serveRequests},
+		{info.ControlPort, "ROUTER", k.serveRequests},
+		{info.StdinPort, "ROUTER", drain},
+		{info.HBPort, "REP", echo},
+		{info.IOPubPort, "PUB", k.serveSubscriber},
+	}
+	for _, s := range sockets {
+		l, err := listen(s.port)
+		if err != nil {
+			return err
+		}
+		defer l.Close()
+		go accept(l, s.socketType, s.serve)
+	}
+	for req := range k.requests {
+		if !k.handle(req) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// accept accepts connections on l and runs serve on each after the handshake.
+func accept(l net.Listener, socketType string, serve func(*zconn)) {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			z, err := handshake(c, socketType)
+			if err != nil {
+				c.Close()
+				return
+			}
+			serve(z)
+			c.Close()
+		}()
+	}
+}
+
+// serveRequests passes the messages from a shell or control peer to the kernel.
+func (k *Kernel) serveRequests(z *zconn) {
+	for {
+		msg, err := z.readMessage()
+		if err != nil {
+			return
+		}
+		k.requests <- request{z, msg}
+	}
+}
+
+// serveSubscriber adds an IOPub peer to those that receive published
+// messages, until it goes away.
+func (k *Kernel) serveSubscriber(z *zconn) {
+	k.mu.Lock()
+	k.subs = append(k.subs, z)
+	k.mu.Unlock()
+	drain(z)
+	k.mu.Lock()
+	k.subs = slices.DeleteFunc(k.subs, func(s *zconn) bool { return s == z })
+	k.mu.Unlock()
+}
+
+// drain discards the messages from a peer, such as subscriptions.
+func drain(z *zconn) {
+	for {
+		if _, err := z.readMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// echo returns heartbeat messages to the peer.
+func echo(z *zconn) {
+	for {
+		msg, err := z.readMessage()
+		if err != nil || z.writeMessage(msg) != nil {
+			return
+		}
+	}
+}
+
+// handle responds to a request. It returns false if the kernel should shut down.
+func (k *Kernel) handle(req request) bool {
+	msg, err := k.parse(req.msg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ivy kernel: %v\n", err)
+		return true
+	}
+	k.publish(msg, "status", map[string]any{"execution_state": "busy"})
+	defer k.publish(msg, "status", map[string]any{"execution_state": "idle"})
+	reply := func(content any) {
+		k.send(req.z, msg.ids, msg, strings.TrimSuffix(msg.header.MsgType, "_request")+"_reply", content)
+	}
+	switch msg.header.MsgType {
+	case "kernel_info_request":
+		reply(map[string]any{
+			"status":                 "ok",
+			"protocol_version":       protocolVersion,
+			"implementation":         "ivy",
+			"implementation_version": "1",
+			"language_info": map[string]any{
+				"name":           "ivy",
+				"version":        "1",
+				"mimetype":       "text/x-ivy",
+				"file_extension": ".ivy",
+			},
+			"banner":     "ivy: an APL-like calculator; )help for help",
+			"help_links": []any{},
+		})
+	case "execute_request":
+		var content struct {
+			Code   string `json:"code"`
+			Silent bool   `json:"silent"`
+		}
+		json.Unmarshal(msg.content, &content)
+		if !content.Silent {
+			k.count++
+			k.publish(msg, "execute_input", map[string]any{"code": content.Code, "execution_count": k.count})
+		}
+		out, errs, html := k.execute(content.Code)
+		if out != "" && !content.Silent {
+			data := map[string]any{"text/plain": out}
+			if html != "" {
+				data["text/html"] = html
+			}
+			k.publish(msg, "execute_result", map[string]any{
+				"execution_count": k.count,
+				"data":            data,
+				"metadata":        map[string]any{},
+			})
+		}
+		if errs != "" {
+			failure := map[string]any{
+				"ename":     "error",
+				"evalue":    errs,
+				"traceback": strings.Split(errs, "\n"),
+			}
+			k.publish(msg, "error", failure)
+			failure["status"] = "error"
+			failure["execution_count"] = k.count
+			reply(failure)
+			break
+		}
+		reply(map[string]any{"status": "ok", "execution_count": k.count, "user_expressions": map[string]any{}})
+	case "is_complete_request":
+		reply(map[string]any{"status": "unknown"})
+	case "complete_request":
+		var content struct {
+			Code      string `json:"code"`
+			CursorPos int    `json:"cursor_pos"`
+		}
+		json.Unmarshal(msg.content, &content)
+		start, matches := run.Complete(k.context, content.Code, content.CursorPos)
+		reply(map[string]any{
+			"status":       "ok",
+			"matches":      matches,
+			"cursor_start": start,
+			"cursor_end":   content.CursorPos,
+			"metadata":     map[string]any{},
+		})
+	case "inspect_request":
+		var content struct {
+			Code      string `json:"code"`
+			CursorPos int    `json:"cursor_pos"`
+		}
+		json.Unmarshal(msg.content, &content)
+		help := k.help(content.Code, content.CursorPos)
+		reply(map[string]any{
+			"status":   "ok",
+			"found":    help != "",
+			"data":     map[string]any{"text/plain": help},
+			"metadata": map[string]any{},
+		})
+	case "history_request":
+		reply(map[string]any{"status": "ok", "history": []any{}})
+	case "comm_info_request":
+		reply(map[string]any{"status": "ok", "comms": map[string]any{}})
+	case "interrupt_request":
+		// Evaluation cannot be interrupted, but the request must be answered.
+		reply(map[string]any{"status": "ok"})
+	case "shutdown_request":
+		var content struct {
+			Restart bool `json:"restart"`
+		}
+		json.Unmarshal(msg.content, &content)
+		reply(map[string]any{"status": "ok", "restart": content.Restart})
+		return false
+	}
+	return true
+}
+
+// execute evaluates the code and returns its output and error text and,
+// if the last value printed is a matrix, its rendering as an HTML table.
+func (k *Kernel) execute(code string) (out, errs, html string) {
+	k.out.Reset()
+	k.errs.Reset()
+	var before value.Value
+	if v := k.context.Global("_"); v != nil {
+		before = v.Value()
+	}
+	if !strings.HasSuffix(code, "\n") {
+		code += "\n"
+	}
+	k.conf.LogInput(code)
+	scanner := scan.New(k.context, "<cell>", strings.NewReader(code))
+	parser := parse.NewParser("<cell>", scanner, k.context)
+	for !run.Run(parser, k.context, false) {
+	}
+	out = strings.TrimSuffix(k.out.String(), "\n")
+	errs = strings.TrimSuffix(k.errs.String(), "\n")
+	if v := k.context.Global("_"); v != nil && v.Value() != before {
+		if m, ok := v.Value().(*value.Matrix); ok && m.Rank() == 2 {
+			html = run.IvyEval(k.context, "'html' export _").Sprint(k.conf)
+		}
+	}
+	return out, errs, html
+}
+
+// word returns the start and end, in runes, of the word of code around
+// the cursor. A word is a run of letters, digits and underscores or, if
+// there is none, a run of other non-space characters, such as an operator.
+func word(code []rune, cursor int, inWord func(rune) bool) (start, end int) {
+	cursor = min(max(cursor, 0), len(code))
+	start, end = cursor, cursor
+	for start > 0 && inWord(code[start-1]) {
+		start--
+	}
+	for end < len(code) && inWord(code[end]) {
+		end++
+	}
+	return start, end
+}
+
+func isIdent(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isSymbol(r rune) bool {
+	return !isIdent(r) && !unicode.IsSpace(r) && r != '(' && r != ')'
+}
+
+// help returns the )help text for the word at the cursor, or "" if there is none.
+func (k *Kernel) help(code string, cursor int) string {
+	text := []rune(code)
+	start, end := word(text, cursor, isIdent)
+	if start == end {
+		start, end = word(text, cursor, isSymbol)
+	}
+	if start == end {
+		return ""
+	}
+	out, errs, _ := k.execute(")help " + string(text[start:end]))
+	out = strings.TrimSpace(out)
+	if errs != "" || strings.HasPrefix(out, "no docs") {
+		return ""
+	}
+	return out
+}
+
+// parse decodes and verifies a message from the client.
+func (k *Kernel) parse(frames [][]byte) (*message, error) {
+	i := slices.IndexFunc(frames, func(f []byte) bool { return string(f) == delimiter })
+	if i < 0 || len(frames) < i+6 {
+		return nil, fmt.Errorf("malformed message")
+	}
+	sig, parts := frames[i+1], frames[i+2:i+6]
+	if len(k.key) > 0 && !hmac.Equal(sig, []byte(k.sign(parts))) {
+		return nil, fmt.Errorf("bad message signature")
+	}
+	msg := &message{ids: frames[:i], content: parts[3]}
+	if err := json.Unmarshal(parts[0], &msg.header); err != nil {
+		return nil, fmt.Errorf("bad message header: %v", err)
+	}
+	return msg, nil
+}
+
+// sign returns the signature of the parts of a message.
+func (k *Kernel) sign(parts [][]byte) string {
+	if len(k.key) == 0 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, k.key)
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// encode returns the frames of a message of the given type in reply to parent.
+func (k *Kernel) encode(ids [][]byte, parent *message, msgType string, content any) [][]byte {
+	hdr, _ := json.Marshal(header{
+		MsgID:    newID(),
+		Session:  k.session,
+		Username: "ivy",
+		Date:     time.Now().UTC().Format(time.RFC3339Nano),
+		MsgType:  msgType,
+		Version:  protocolVersion,
+	})
+	parentHdr, _ := json.Marshal(parent.header)
+	body, _ := json.Marshal(content)
+	parts := [][]byte{hdr, parentHdr, []byte("{}"), body}
+	frames := slices.Clone(ids)
+	frames = append(frames, []byte(delimiter), []byte(k.sign(parts)))
+	return append(frames, parts...)
+}
+
+// send sends a message on the connection.
+func (k *Kernel) send(z *zconn, ids [][]byte, parent *message, msgType string, content any) {
+	z.writeMessage(k.encode(ids, parent, msgType, content))
+}
+
+// publish sends a message to all IOPub peers.
+func (k *Kernel) publish(parent *message, msgType string, content any) {
+	frames := k.encode([][]byte{[]byte(msgType)}, parent, msgType, content)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, z := range k.subs {
+		z.writeMessage(frames)
+	}
+}
+
+// newID returns a random identifier for a message or session.
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
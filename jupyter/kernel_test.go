@@ -0,0 +1,89 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jupyter
+
+import (
+	"net"
+	"slices"
+	"strings"
+	"testing"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/run"
+)
+
+func TestZMTP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go accept(l, "REP", echo)
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	z, err := handshake(c, "REQ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := [][]byte{[]byte("short"), []byte(strings.Repeat("long", 100)), nil}
+	if err := z.writeMessage(msg); err != nil {
+		t.Fatal(err)
+	}
+	got, err := z.readMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.EqualFunc(got, msg, func(a, b []byte) bool { return string(a) == string(b) }) {
+		t.Errorf("echo: got %q; want %q", got, msg)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	k := New(new(config.Config), "secret")
+	parent := &message{header: header{MsgID: "1", MsgType: "execute_request"}}
+	frames := k.encode([][]byte{[]byte("id")}, parent, "execute_reply", map[string]any{"status": "ok"})
+	msg, err := k.parse(frames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(msg.ids[0]) != "id" || msg.header.MsgType != "execute_reply" {
+		t.Errorf("parse: got ids %q, type %q", msg.ids, msg.header.MsgType)
+	}
+	frames[len(frames)-1] = []byte(`{"status":"error"}`)
+	if _, err := k.parse(frames); err == nil {
+		t.Error("parse accepted a message with a bad signature")
+	}
+}
+
+func TestExecute(t *testing.T) {
+	k := New(new(config.Config), "")
+	out, errs, html := k.execute("x = 2 3 rho iota 6\nx")
+	if out != "1 2 3\n4 5 6" || errs != "" {
+		t.Errorf("execute: got %q, %q", out, errs)
+	}
+	if !strings.Contains(html, "<table>") {
+		t.Errorf("execute: got html %q; want a table", html)
+	}
+	out, errs, html = k.execute("+/x")
+	if out != "6 15" || html != "" {
+		t.Errorf("execute: got %q, html %q", out, html)
+	}
+	_, errs, _ = k.execute("1/0")
+	if !strings.Contains(errs, "zero denominator") {
+		t.Errorf("execute: got error %q", errs)
+	}
+	if _, matches := run.Complete(k.context, "1 + xy x", 8); !slices.Equal(matches, []string{"x", "xor"}) {
+		t.Errorf("complete: got %q", matches)
+	}
+	if help := k.help("rho x", 1); !strings.Contains(help, "shape") {
+		t.Errorf("help: got %q", help)
+	}
+	if help := k.help("nothing", 1); help != "" {
+		t.Errorf("help: got %q for an unknown word", help)
+	}
+}
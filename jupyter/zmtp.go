@@ -0,0 +1,185 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jupyter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// This file implements just enough of ZMTP 3.0, the ZeroMQ wire protocol,
+// with the NULL security mechanism, for a kernel to talk to Jupyter.
+// Each socket is a TCP listener; each accepted connection is a peer.
+// The kernel replies to a request on the connection it came from,
+// which is all the ROUTER and REP socket types need here, and sends
+// every message on a PUB socket to every peer, ignoring subscriptions.
+
+// Frame flags.
+const (
+	flagMore    = 1 << 0
+	flagLong    = 1 << 1
+	flagCommand = 1 << 2
+)
+
+// maxFrame bounds the size of a frame we are willing to read.
+const maxFrame = 1 << 28
+
+// A zconn is a ZMTP connection to a peer.
+type zconn struct {
+	net.Conn
+	r  *bufio.Reader
+	w  *bufio.Writer
+	mu sync.Mutex // Serializes writes.
+}
+
+// greeting returns the ZMTP 3.0 greeting for the NULL mechanism.
+func greeting() []byte {
+	g := make([]byte, 64)
+	g[0] = 0xFF
+	g[9] = 0x7F
+	g[10] = 3 // Major version.
+	g[11] = 0 // Minor version.
+	copy(g[12:32], "NULL")
+	return g
+}
+
+// handshake exchanges greetings and READY commands with the peer,
+// announcing the socket type of this end.
+func handshake(c net.Conn, socketType string) (*zconn, error) {
+	z := &zconn{Conn: c, r: bufio.NewReader(c), w: bufio.NewWriter(c)}
+	if _, err := c.Write(greeting()); err != nil {
+		return nil, err
+	}
+	g := make([]byte, 64)
+	if _, err := io.ReadFull(z.r, g); err != nil {
+		return nil, err
+	}
+	if g[0] != 0xFF || g[9] != 0x7F || g[10] < 3 {
+		return nil, fmt.Errorf("zmtp: bad greeting from %s", c.RemoteAddr())
+	}
+	if mech := string(bytes.TrimRight(g[12:32], "\x00")); mech != "NULL" {
+		return nil, fmt.Errorf("zmtp: unsupported mechanism %q", mech)
+	}
+	if err := z.command(readyCommand(socketType)); err != nil {
+		return nil, err
+	}
+	flags, body, err := z.readFrame()
+	if err != nil {
+		return nil, err
+	}
+	if flags&flagCommand == 0 || !bytes.HasPrefix(body, []byte("\x05READY")) {
+		return nil, fmt.Errorf("zmtp: expected READY from %s", c.RemoteAddr())
+	}
+	return z, nil
+}
+
+// readyCommand returns the body of a READY command.
+func readyCommand(socketType string) []byte {
+	var b bytes.Buffer
+	b.WriteString("\x05READY")
+	b.WriteByte(byte(len("Socket-Type")))
+	b.WriteString("Socket-Type")
+	binary.Write(&b, binary.BigEndian, uint32(len(socketType)))
+	b.WriteString(socketType)
+	return b.Bytes()
+}
+
+// readFrame reads a single frame.
+func (z *zconn) readFrame() (flags byte, body []byte, err error) {
+	flags, err = z.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var size uint64
+	if flags&flagLong != 0 {
+		var b [8]byte
+		if _, err := io.ReadFull(z.r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(b[:])
+	} else {
+		b, err := z.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size = uint64(b)
+	}
+	if size > maxFrame {
+		return 0, nil, fmt.Errorf("zmtp: frame too large (%d bytes)", size)
+	}
+	body = make([]byte, size)
+	if _, err := io.ReadFull(z.r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
+
+// readMessage reads a multipart message. It answers PING commands
+// and skips any other commands.
+func (z *zconn) readMessage() ([][]byte, error) {
+	var msg [][]byte
+	for {
+		flags, body, err := z.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		if flags&flagCommand != 0 {
+			if bytes.HasPrefix(body, []byte("\x04PING")) && len(body) >= 7 {
+				// The PONG carries the context that follows the TTL.
+				z.command(append([]byte("\x04PONG"), body[7:]...))
+			}
+			continue
+		}
+		msg = append(msg, body)
+		if flags&flagMore == 0 {
+			return msg, nil
+		}
+	}
+}
+
+// writeFrame buffers a single frame with the given flags, apart from
+// the size flag, which it sets itself. The caller must hold z.mu.
+func (z *zconn) writeFrame(flags byte, body []byte) error {
+	var hdr [9]byte
+	n := 2
+	if len(body) > 255 {
+		hdr[0] = flags | flagLong
+		binary.BigEndian.PutUint64(hdr[1:], uint64(len(body)))
+		n = 9
+	} else {
+		hdr[0] = flags
+		hdr[1] = byte(len(body))
+	}
+	z.w.Write(hdr[:n])
+	_, err := z.w.Write(body)
+	return err
+}
+
+// command sends a command frame.
+func (z *zconn) command(body []byte) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.writeFrame(flagCommand, body)
+	return z.w.Flush()
+}
+
+// writeMessage writes a multipart message.
+func (z *zconn) writeMessage(msg [][]byte) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	for i, part := range msg {
+		var flags byte
+		if i < len(msg)-1 {
+			flags = flagMore
+		}
+		z.writeFrame(flags, part)
+	}
+	return z.w.Flush()
+}
@@ -51,21 +51,55 @@ spaces, is a single rational number, not the expression 1 divided
 by 3. This can affect precedence: 3/6*4 is 2 while 3 / 6*4 is 1/8
 since the spacing turns the / into a division operator. Use parentheses
 or spaces to disambiguate: 3/(6*4) or 3 /6*4.
+<p>Similarly, a minus sign is part of a number if it is preceded by a
+space and followed immediately by a digit, so 3 -2 is the vector 3 -2
+but 3-2 and 3 - 2 are 1. APL&apos;s high minus, as in ¯2, is always the sign
+of a number, never an operator, so 1 ¯2 3 and x ¯2 are vectors wherever
+they appear. It may also introduce a negative exponent, as in 1e¯5.
 <p>Ivy has complex numbers, which are constructed using the unary or
 binary j operator. As with rationals, the token 1j2 (the representation
 of 1+2i) is a single token. The individual parts can be rational,
 so 1/2j-3/2 is the complex number 0.5-1.5i and scans as a single
-value.
+value. The binary polar operator builds a complex number from its
+magnitude and phase: 2 polar pi/2 is (near enough) 0j2.
+<p>A matrix may be written directly, row by row, in brackets with the rows
+separated by semicolons: [1 2 3; 4 5 6] is the 2x3 matrix with those
+rows. The rows may be any expressions but must all have the same shape;
+rows that are matrices stack to make a matrix of higher rank. After an
+operator, a bracketed expression is taken as an axis, as in +/[1] x,
+unless it holds a semicolon or ends the expression; otherwise use
+parentheses: -([1 2 3]) * 2.
 <p>Indexing uses [] notation: x[1], x[1; 2], and so on. Indexing by a
 vector selects multiple elements: x[1 2] creates a new item from
 x[1] and x[2]. An empty index slot is a shorthand for all the
 elements along that dimension, so x[] is equivalent to x, and x[;3]
 gives the third column of two-dimensional array x.
+<p>Where take, fill, or reshape of an empty vector must pad an array, they use
+its fill element, the prototype of its first element: a blank for a char, zero
+for any other scalar, and for a nested item, an item of the same shape holding
+the prototypes of its elements. Thus 3 take (1 &apos;ab&apos;) 2 is (1 &apos;ab&apos;) 2 (0 &apos;  &apos;).
+An empty vector has fill element zero, which is also its first.
+<p>Elementwise binary operators such as + extend a scalar to the shape of the
+other operand, and a vector along the last axis of a matrix. More generally,
+with the shapes aligned at their last axes, an axis of length 1, or a missing
+leading axis, stretches to match the other operand, so
+(3 1 rho iota 3) + 1 2 3 4 is a 3 by 4 matrix. Any other difference in shape
+is an error.
 <p>Only a subset of APL&apos;s functionality is implemented, but all numerical
 operations are supported.
+<p>A backslash preceded by a space and ending a line, apart from any comment,
+continues the line: the next line is read as part of the same one. This works
+both inside and outside operator definitions:
+<pre>x = 1 + 2 + \   # partial sums
+    3 + 4
+</pre>
 <p>Semicolons separate multiple statements on a line. Variables are
 alphanumeric and are assigned with the = operator. Assignment is
 an expression.
+<p>Selected elements of a variable may be assigned through a boolean
+mask and sel: ((x&lt;0) sel x) = 0 sets the negative elements of x to
+zero, the same as x[where x&lt;0] = 0. The right-hand side may be a
+scalar or a vector holding a value for each selected element.
 <p>After each successful expression evaluation, except for assignments
 and calls to the print operator, the result is stored in the variable
 called _ (underscore) so it can be used in the next expression
@@ -84,16 +118,24 @@ Floor             ⌊B    floor   Greatest integer less than or equal to B
 Shape             ⍴B    rho     Vector of number of components in each dimension of B
 Count             ≢B    count   Scalar number of elements at top level of B
 Flatten           ∊B    flatten Vector of all the scalar elements within B
+Raze              ⊃,/B  raze    Items of B joined into one vector, undoing part,
+                                one level only, unlike flatten; matrix items are
+                                catenated along the first axis; for a matrix B,
+                                the items in each row are joined
 Not               ∼B    not     Logical: not 1 is 0, not 0 is 1
 Absolute value    ∣B    abs     Magnitude of B
 Index generator   ⍳B    iota    Vector of the first B integers
                                 If B is a vector, matrix of coordinates
 Where             ⍸B    where   Vector of indexes where B is non-zero
+                                Positive integers in B repeat the index;
+                                if B is a matrix, vector of coordinates
+Where             ⍸B    sel     Same as where
 Unique            ∪B    unique  Remove all duplicate elements from B
 Enclose           ⊂B    box     Wrap B in one level of nesting
 Disclose          ⊃B    first   First element of B in ravel order
 Split             ↓B    split   Create vector of nested elements from matrix B; inverse of mix
 Mix               ↑B    mix     Create matrix from elements of vector B; inverse of split
+Depth             ≡B    depth   Levels of nesting in B: 0 for a scalar, 1 for a simple vector
 Exponential       ⋆B    **      e to the B power
 Negation          −B    -       Change sign of B
 Identity          +B    +       No change to B
@@ -107,12 +149,49 @@ Reversal          ⌽B    rot     Reverse elements of B along last axis
 Reversal          ⊖B    flip    Reverse elements of B along first axis
 Grade up          ⍋B    up      Indices of B which will arrange B in ascending order
 Grade down        ⍒B    down    Indices of B which will arrange B in descending order
+Sort                    sort    B arranged in ascending order; rows of a matrix
+                                are sorted as units
+Reverse sort            rsort   B arranged in descending order
+Group                   group   Vector of vectors of the indexes of each unique
+                                element of B, in order of first appearance
+Running maximum         cummax  Maximum of each prefix of B, as for max\B, along last axis
+Running minimum         cummin  Minimum of each prefix of B, as for min\B, along last axis
+Differences             diff    Each element of B minus the one before, along last axis
+Histogram               hist    Counts of the real numbers B in 1+ceil(log2 rho B) bins
+                                of equal width spanning B, as for binary hist
+Cartesian product       cross   Cartesian product of the elements of B, as for
+                                B[1] cross B[2] cross ...
+Weekday                 weekday Day of the week of time B: 0 for Sunday to 6 for Saturday
+Upper case              upper   Text B in upper case; the length may change, as for ß
+Lower case              lower   Text B in lower case
+Compose                 nfc     Text B in Unicode normalization form C (composed)
+Decompose               nfd     Text B in Unicode normalization form D (decomposed)
+Hex encode              hex     Text of hexadecimal digits for bytes B (0 to 255);
+                                text B stands for its UTF-8 bytes
+Hex decode              unhex   Byte values encoded in the hexadecimal text B
+Base64 encode           base64  Text of base64 encoding of bytes B, as for hex
+Base64 decode           unbase64 Byte values encoded in the base64 text B
 Execute           ⍎B    ivy     Execute an APL (ivy) expression
 Monadic format    ⍕B    text    A character representation of B
+Type                    type    The name of the type of B as text, such as int or matrix
+Arity                   arity   1 if the op named by text B is unary, 2 if binary, or 1 2
+Plot                    plot    Draw vector B, or each row of matrix B, against its
+                                indices in the file set by )plot; yields the file name
+Exact decimal           decimal Text of rational B as an exact decimal, with any
+                                repeating digits in parentheses: 1/6 is 0.1(6)
 Monadic transpose ⍉B    transp  Reverse the axes of B
-Factorial         !B    !       Product of integers 1 to B
+Factorial         !B    !       Product of integers 1 to B; for non-integer
+                                or complex B, gamma(B+1)
+Infinity test           isinf   1 if B is +Inf or -Inf, or complex with such a part; 0 if not
+NaN test                isnan   1 if B is NaN, or complex with a NaN part; 0 if not
 Bitwise not             ^       Bitwise complement of B (integer only)
+Population count        popcount Number of 1 bits in non-negative integer B
+Bit length              bitlen  Number of bits needed to hold abs B (integer only)
+Balanced ternary        baltern Balanced ternary digits (-1 0 1) of integer B;
+                                3 decode baltern B is B
 Square root       B⋆.5  sqrt    Square root of B.
+Integer sqrt            isqrt   Square root of integer B, rounded down; exact however large
+Power test              ispower 1 if integer B is m**k for integers m and k&gt;1; 0 if not
 Sine                    sin     sin(A); APL uses binary ○ (see below)
 Cosine                  cos     cos(A); ditto
 Tangent                 tan     tan(A); ditto
@@ -148,45 +227,122 @@ Circle                A○B             Trigonometric functions of B selected by
                             cos       cos(B); ivy uses traditional name.
                             tan       tan(B); ivy uses traditional name.
 Deal                  A?B   ?         A distinct integers selected randomly from the first B integers
+Roll from stream            roll      As for ?B, but drawn from the random stream named by the
+                                      text A, independent of ? and of other streams
 Membership            A∈B   in        1 for elements of A present in B; 0 where not.
+Count of                    countin   Number of times each element of A appears in B
 Intersection          A∩B   intersect A with all elements not in B removed
 Union                 A∪B   union     A followed by all members of B not already in A
+Without               A~B   without   A with all elements in B removed
+Unique within               unique    B without elements within A of an earlier element kept;
+                                      B must be real numbers
+Histogram                   hist      Counts of the real numbers B in each bin: A is the
+                                      increasing edges of the bins, or the number of bins
+                                      of equal width from min B to max B; each bin holds
+                                      its lower edge, the last also its upper edge
+Interpolation               interp    Linear interpolation at B in the table xs ys held by A,
+                                      where xs increases; outside the table, A may add
+                                      &apos;clamp&apos; (default), &apos;extend&apos;, or &apos;error&apos;
+Find root                   findroot  A zero of the unary op named by text A between the
+                                      bounds B, where the op changes sign, by bisection
+Minimize                    minimize  Where the unary op named by text A is least between
+                                      the bounds B, for an op with one minimum there
+Integral                    integrate The integral of the unary op named by text A between
+                                      the bounds B, for an op smooth between them
+Derivative                  deriv     The derivative of the unary op named by text A at B
+Recurrence                  recurrence Term B, from the index origin, of the sequence
+                                      whose initial values are the second of A and whose
+                                      later terms are the sum of the coefficients, the
+                                      first of A, times the terms before, latest first
+Matrix power                mpow      The square matrix A to the power B, by +.*
+Cartesian product           cross     Matrix whose rows are each tuple of A followed by
+                                      each tuple of B, the last changing fastest; each row
+                                      of a matrix is a tuple, as is each element of a
+                                      vector and a scalar
+Find                  A⍷B   find      1 where pattern A begins in B; 0 elsewhere.
+                                      A vector A is sought in each row of a matrix B
 Maximum               A⌈B   max       The greater value of A or B
 Minimum               A⌊B   min       The smaller value of A or B
 Reshape               A⍴B   rho       Array of shape A with data B
-Take                  A↑B   take      Select the first (or last) A elements of B according to sgn A
+Disclose                    first     Apply first A times, descending A levels of nesting
+Split                       split     Array of the cells formed by the last A axes of B
+Take                  A↑B   take      Select the first (or last) A elements of B according to sgn A;
+                                      overtaking pads B with fill elements (see below)
 Drop                  A↓B   drop      Remove the first (or last) A elements of B according to sgn A
 Decode                A⊥B   decode    Value of a polynomial whose coefficients are B at A
                                       &apos;T&apos; decode B creates a seconds value from the time vector B
-Encode                A⊤B   encode    Base-A representation of the value of B
+Encode                A⊤B   encode    Base-A representation of the value of B, one digit
+                                      per radix of A; as in APL, a zero radix takes all
+                                      that remains and each digit has the sign of its radix
                                       &apos;T&apos; encode B creates a time vector from the seconds value B
+Radix                       radix     Digits of integer B in base A, which may be negative,
+                                      as many as needed, most significant first;
+                                      A decode A radix B is B
 Residue               A∣B              B modulo A
                             mod       A modulo B (Euclidean)
                             imod      A modulo B (Go)
+                            divmod    The pair (A div B) (A mod B) from one division, so
+                                      (q r) = A divmod B sets both; works for non-integers
 Catenation            A,B   ,         Elements of B appended to the elements of A along last axis
 Catenation            A,B   ,%        Elements of B appended to the elements of A along first axis
-Expansion             A\B   fill      Insert zeros (or blanks) in B corresponding to zeros in A
-                                      In ivy: abs(A) gives count, A &lt;= 0 inserts zero (or blank)
+Laminate                    lam       A and B, of the same shape, joined along a new first axis;
+                                      a scalar is extended (APL A,[0.5]B)
+Expansion             A\B   fill      Insert fill elements in B corresponding to zeros in A
+                                      In ivy: abs(A) gives count, A &lt;= 0 inserts fill elements
 Compression           A/B   sel       Select elements in B corresponding to ones in A
-                                      In ivy: abs(A) gives count, A &lt;= 0 inserts zero
+                                      In ivy: abs(A) gives count, A &lt;= 0 inserts zero;
+                                      if A has the shape of B, each element of A is the
+                                      count for the corresponding element of B
+Compression           A⌿B   sel[1]    Select rows of B corresponding to ones in A
+Expansion             A⍀B   fill[1]   Insert rows of fill elements in B
+Where                       where     Elements of A where B is non-zero, B times each, as
+                                      for unary where; if A and B are matrices, each row
+                                      holds the coordinates of an element followed by it
 Partition             A⊆B   part      Vector of subvectors of B grouped by elements of A:
                                       If 0, ignore; otherwise start new group at boundaries
-                                      where elements of A increase
+                                      where elements of A increase. A is non-negative integers,
+                                      one for each element of B, or a single value for all;
+                                      for a matrix B, each row is partitioned by A, and
+                                      part[1] partitions down the columns.
+                                      raze (A part B) is B if A has no zeros
 Index of              A⍳B   iota      The location (index) of B in A; 1+⌈/⍳⍴A if not found
                                       In ivy: origin-1 if not found (that is, 0 if one-indexed)
+Progressive index of        pio       As for iota, but each occurrence of a value in vector B
+                                      finds the next unused occurrence in vector A
+Sort                        sort      B arranged by ascending order of the key A,
+                                      which has one element (or row) per element of B
+                            rsort     B arranged by descending order of the key A
+Group                       group     Vector of vectors of the elements of B, one for each
+                                      unique key in A, in order of first appearance;
+                                      unique A gives the keys
+Top k                       topk      The A largest elements of vector B, largest first;
+                                      if A &lt; 0, the -A smallest, smallest first
+Interval index        A⍸B   interval  For each element of B, the index i of the interval
+                                      A[i] &lt;= B &lt; A[i+1] of sorted A; origin-1 if below A[1]
 Matrix divide         A⌹B   mdiv      Solution to system of linear equations Bx = A
                                       For real vectors, the magnitude of A projected on B
 Rotation              A⌽B   rot       The elements of B are rotated A positions left
 Rotation              A⊖B   flip      The elements of B are rotated A positions along the first axis
 Logarithm             A⍟B   log       Logarithm of B to base A
+Root                        root      The Ath root of B, for positive integer A; exact if
+                                      B is a rational with an exact root
+Split on delimiter          fields    Vector of the texts in text B separated by delimiter A,
+                                      or by white space if A is empty; splits each row of a matrix
 Dyadic format         A⍕B   text      Format B into a character matrix according to A
                                       A is the textual format (see format special command);
                                       otherwise result depends on length of A:
                                       1 gives decimal count, 2 gives width and decimal count,
                                       3 gives width, decimal count, and style (&apos;d&apos;, &apos;e&apos;, &apos;f&apos;, etc.).
                                       &apos;T&apos; text B formats seconds value B as a Unix date
+If-else                     ifelse    First element of two-element vector B if scalar A is
+                                      true, else the second; if B is written as two
+                                      expressions, only the chosen one is evaluated
+Plot                        plot      As for unary plot, but draw B against the values of vector A
+Export                      export    Text of scalar, vector or matrix B as a table to paste
+                                      into a document; A is &apos;latex&apos;, &apos;markdown&apos;, or &apos;html&apos;
 General transpose     A⍉B   transp    The axes of B are ordered by A
-Combinations          A!B   !         Number of combinations of B taken A at a time
+Combinations          A!B   !         Number of combinations of B taken A at a time;
+                                      for non-integers, (!B)/(!A)*!B-A via gamma
 Less than             A&lt;B   &lt;         Comparison (elementwise): 1 if true, 0 if false
 Less than or equal    A≤B   &lt;=        Comparison (elementwise): 1 if true, 0 if false
 Equal                 A=B   ==        Comparison (elementwise): 1 if true, 0 if false
@@ -195,22 +351,51 @@ Greater than          A&gt;B   &gt;         Comparison (elementwise): 1 if true,
 Not equal             A≠B   !=        Comparison (elementwise): 1 if true, 0 if false
 Match                 A≡B   ===       Comparison (overall): 1 if true, 0 if false
 Not match             A≠B   !==       Comparison (overall): 1 if true, 0 if false
+Compare                     cmp       Comparison (elementwise): -1, 0, or 1 as A is below,
+                                      equal to, or above B in the total order used by sort,
+                                      which places chars below numbers
+Expect                      expect    For testing: B, unprinted, if it matches A in shape and
+                                      elements, as for ===; otherwise an error
 Or                    A∨B   or        Logic: 0 if A and B are 0; 1 otherwise
 And                   A∧B   and       Logic: 1 if A and B are 1; 0 otherwise
 Nor                   A⍱B   nor       Logic: 1 if both A and B are 0; otherwise 0
 Nand                  A⍲B   nand      Logic: 0 if both A and B are 1; otherwise 1
 Xor                         xor       Logic: 1 if A != B; otherwise 0
+Conditional and             &amp;&amp;        Logic: 1 if scalars A and B are true; B is not
+                                      evaluated if A is false
+Conditional or              ||        Logic: 1 if scalar A or B is true; B is not
+                                      evaluated if A is true
 Bitwise and                 &amp;         Bitwise A and B (integer only)
 Bitwise or                  |         Bitwise A or B (integer only)
 Bitwise xor                 ^         Bitwise A exclusive or B (integer only)
-Left shift                  &lt;&lt;        A shifted left B bits (integer only)
-Right Shift                 &gt;&gt;        A shifted right B bits (integer only)
+Left shift                  &lt;&lt;        A shifted left B bits; for rationals and floats,
+                                      A times 2**B, exactly
+Right Shift                 &gt;&gt;        A shifted right B bits; for rationals and floats,
+                                      A divided by 2**B, exactly
+Get bit                     getbit    Bit number A of B, counting from 0 (integer only)
+Set bit                     setbit    B with bit number A set to 1 (integer only)
+Rotate bits                 rotbits   With A the pair N W, the low W bits of B rotated
+                                      left N bits (right if N&lt;0); B must fit in W bits
+Modular inverse             invmod    The integer X in 0 to B-1 such that (A*X) mod B is 1
+Modular power               powmod    With B the pair E M, (A**E) mod M without computing
+                                      A**E; a negative E uses the modular inverse of A
 Complex construction        j         The complex number A+Bi
+Polar construction          polar     The complex number with magnitude A and phase B
+Add months                  addmonths Time A moved B calendar months, keeping the time of day;
+                                      a day past the end of the month becomes its last day
+Add years                   addyears  Time A moved B calendar years, as for addmonths
+Date range                  todates   Times one day apart from time A through time B
+Business days               busdays   Number of days Monday through Friday from the date
+                                      of time A up to but not including that of time B
 </pre>
 <p>Operators and axis indicator
 <pre>Name                APL  Ivy  APL Example  Ivy Example  Meaning (of example)
 Reduce (last axis)  /    /    +/B          +/B          Sum across B
 Reduce (first axis) ⌿    /%   +⌿B                       Sum down B
+N-wise reduce       /    /    2-/B         2 -/B        Differences of pairs in B
+                                                        (negative N runs each
+                                                        window backwards; /%
+                                                        for first axis)
 Scan (last axis)    \    \    +\B          +\B          Running sum across B
 Scan (first axis)   ⍀    \%   +⍀B                       Running sum down B
 Inner product       .    .    A+.×B        A +.* B      Matrix product of A and B
@@ -221,6 +406,24 @@ Each left                @f                A @f B       (A[1] f B), (A[2] f B),
                                                         as vector or matrix
 Each right          f¨   f@   A f¨ B       A f@ B       (A f B[1]), (A f B[2]), ...
                                                         as vector or matrix
+Each with index          f#@               f#@ B        (1 f B[1]), (2 f B[2]), ...
+                                                        as vector or matrix; for a
+                                                        matrix, the coordinates of
+                                                        each element are the index
+Inverse             ⍣¯1  inverse           inverse f B  X such that f X is B;
+                                                        A inverse f B is X such
+                                                        that A f X is B
+Under               ⍢    under f⍢g B       f under g B  inverse g f g B;
+                                                        A f under g B is
+                                                        inverse g (g A) f (g B)
+Axis                [K]  [K]  +/[1]B       +/[1]B       Sum along axis 1 of B;
+                                                        axis (origin-based) also
+                                                        for scan, rot, flip, sel,
+                                                        fill, part, raze, cummax,
+                                                        cummin, diff and
+                                                        catenation, as in
+                                                        A ,[1] B; with an axis,
+                                                        rot and flip are the same
 </pre>
 <p>Type-converting operations
 <pre>Name              APL   Ivy     Meaning
@@ -229,6 +432,7 @@ Char                    char B  The character with integer Unicode value B
 Float                   float B The floating-point representation of B;
                                 for complex numbers, the result is
                                 (float A)j(float B)
+Time                    time B  The time B seconds after 1970.01.01T00:00:00Z
 </pre>
 <h3 id="hdr-Pre_defined_constants">Pre-defined constants</h3>
 <p>The constants e (base of natural logarithms) and pi (π) are pre-defined to high
@@ -251,7 +455,33 @@ spaces between them.
 <p>Chars have restricted operations. Printing, comparison, indexing and so on are
 legal but arithmetic is not, and chars cannot be converted automatically into other
 singleton values (ints, floats, and so on). The unary operators char and code
-enable transcoding between integer and char values.
+enable transcoding between integer and char values. The unary operators upper
+and lower convert the case of text, and nfc and nfd normalize it; they treat the
+text as a whole, so the result may have a different length.
+<h3 id="hdr-Time">Time</h3>
+<p>A time literal is a date written with periods, optionally followed by T and a
+time of day: 2024.01.15, 2024.01.15T10:30, 2024.01.15T10:30:00.5. It denotes
+that moment in the time zone set by )timezone, or in UTC if it ends in Z.
+Times print in the configured time zone, without the time of day if it is
+midnight.
+<p>Times may be compared, and the difference of two times is the number of seconds
+between them. Adding seconds to, or subtracting them from, a time yields a time:
+<pre>2024.01.15T10:30 - 2024.01.15
+result: 37800
+2024.01.15 + 86400
+result: 2024.01.16
+</pre>
+<p>The time operator converts a seconds value to a time, and &apos;T&apos; encode and
+&apos;T&apos; text accept times as well as seconds. Thus time &apos;T&apos; decode V is the
+time described by the time vector V, and T - time 0 is the seconds value
+of the time T.
+<p>Calendar operators such as weekday, addmonths and todates, listed above, work
+on dates in the configured time zone.
+<pre>weekday 2024.01.15
+result: 1
+2024.01.31 addmonths 1
+result: 2024.02.29
+</pre>
 <h3 id="hdr-User_defined_operators">User-defined operators</h3>
 <p>Users can define unary and binary operators, which then behave just like
 built-in operators. Both a unary and a binary operator may be defined for the
@@ -269,14 +499,27 @@ creating new local variables.
 <p>The body may be a single line (possibly containing semicolons) on the same line
 as the &apos;op&apos;, or it can be multiple lines. For a multiline entry, there is a
 newline after the &apos;=&apos; and the definition ends at the first blank line (ignoring
-spaces).
+spaces). A line holding only a comment is not blank, so comments may separate
+the parts of a long body.
 <p>Conditional execution is done with the &quot;:&quot; binary conditional return
-operator, which is valid only within the code for a user-defined
 operator. The left operand must be a scalar or one-element vector
 or matrix. If it is non-zero, the right operand is returned as the
 value of the function. Otherwise, execution continues normally. The
 &quot;:&quot; operator has a lower precedence than any other operator; in
-effect it breaks the line into two separate expressions.
+effect it breaks the line into two separate expressions. Outside an
+operator, a conditional works the same way on a line: if the condition
+is true, the right operand is printed and the rest of the line is
+skipped, so x&lt;0: &apos;negative&apos;; &apos;non-negative&apos; prints one or the other.
+<p>Within an expression, the binary ifelse operator chooses between two
+values: c ifelse A B is A if the scalar c is true and B otherwise. When
+the right operand is written as two expressions, such as (x[n]) 0, only
+the chosen one is evaluated, so (n &lt;= rho x) ifelse (x[n]) 0 is safe
+when n is out of range, and a recursive op may use ifelse to stop. If
+the right operand is a computed value, such as a variable, both elements
+have already been evaluated.
+<p>Similarly, the binary operators &amp;&amp; and || evaluate their left operand
+first and their right operand only if it decides the result, so
+(n &gt; 0) &amp;&amp; x[n] == 1 does not index x when n is 0.
 <p>Example: average of a vector (unary):
 <pre>op avg x = (+/x)/rho x
 avg iota 11
@@ -315,6 +558,12 @@ result: 22
 	e % 2: (b * modexp b (e-1) m) mod m
 	modexp ((b**2) mod m) (e&gt;&gt;1) m
 </pre>
+<p>The built-in powmod does the same job far faster: b powmod e m.
+<p>Ivy has no loop statements. To apply an operator to each element of a vector
+in turn, with no index to maintain, use the each operator @:
+<pre>12 gcd@ 18 20 27
+result: 6 4 3
+</pre>
 <p>On mobile platforms only, due to I/O restrictions, user-defined operators
 must be presented on a single line. Use semicolons to separate expressions:
 <pre>op a gcd b = a == b: a; a &gt; b: b gcd a-b; a gcd b-a
@@ -342,10 +591,60 @@ save 9; save 3
 total last
 result: 12 3
 </pre>
+<p>The body may instead begin with directives that declare variables explicitly.
+The directive &quot;:local&quot; names variables that are local even if read before being
+written, and &quot;:global&quot; names variables that are global even if written before
+being read. Directives end with a semicolon or newline.
+<pre>op save x = :global total last; total = total + x; last = x
+</pre>
+<p>After the special command &quot;)strict 1&quot;, an operator that reads a global it has
+not declared with &quot;:global&quot; is rejected when it is defined. This protects a
+library of operators from misreading a local as a global, or vice versa.
+<p>An operator body may begin with the directive &quot;:origin N&quot;, which runs the
+operator with index origin N whatever the origin of its caller, and restores
+the caller&apos;s origin on return. This lets a library of operators work under
+any setting of )origin.
+<pre>op first3 x = :origin 1; x[1 2 3]
+)origin 0
+first3 10 20 30 40
+result: 10 20 30
+</pre>
+<p>An operator is pure if it assigns no global variables, has no :origin
+directive, uses no operator with side effects such as ?, print, or sys, and
+calls only pure operators. Like most builtins, a pure operator applied by an
+outer or inner product, a reduction, a scan, or elementwise to the items of a
+large array may be evaluated in parallel.
+<p>The inverse operator undoes an operator: inverse - and inverse / negate and
+take the reciprocal, inverse ** is log, inverse +\ takes differences, and
+A inverse rot B and A inverse encode B rotate and decode. The inverse of a
+binary operator is with respect to its right argument, so 2 inverse + 5 is 3.
+The word inverse must be followed by an operator; otherwise it is an ordinary
+name. A user-defined operator may be given an inverse by a definition that
+starts &apos;op inverse&apos;:
+<pre>op double x = 2*x
+op inverse double x = x/2
+inverse double 6
+result: 3
+
+op a plus b = a+b
+op inverse a plus b = b-a
+</pre>
+<p>With inverses, the under operator applies an operator in a transformed domain:
+f under g B transforms B by g, applies f, and transforms the result back by the
+inverse of g. Binary, A f under g B transforms both arguments. Thus 2 + under
+log 3 is 6, and to round to two decimal places,
+<pre>op cents x = 100*x
+op inverse cents x = x/100
+floor under cents 3.14159
+result: 157/50
+</pre>
 <p>To remove the definition of a unary or binary user-defined operator,
 <pre>opdelete foo x
 opdelete a gcd b
 </pre>
+<p>which also removes any inverse. To remove only the inverse, say
+<pre>opdelete inverse foo x
+</pre>
 <h3 id="hdr-Special_commands">Special commands</h3>
 <p>Ivy accepts a number of special commands, introduced by a right paren
 at the beginning of the line. Most report the current value if a new value
@@ -357,18 +656,93 @@ base 10 and must be non-negative on input.
 ) base 0
 	Set the number base for input and output. The commands ibase and
 	obase control setting of the base for input and output alone,
-	respectively.  Base 0 allows C-style input: decimal, with 037 being
-	octal and 0x10 being hexadecimal. Bases above 16 are disallowed.
-	To output large integers and rationals, base must be one of
-	0 2 8 10 16. Floats are always printed base 10.
+	respectively.  Base 0 allows C-style input: decimal, with 037 and
+	0o37 being octal, 0x10 hexadecimal and 0b101 binary; hexadecimal
+	floats such as 0x1.8p3 take a power-of-two exponent. Bases above
+	10 use the letters a-z (or A-Z on input) as digits; bases above 36
+	are disallowed. In other bases, the digits after a point are in
+	that base too, so in base 16 1.8 is 3/2. An exponent is always
+	decimal: after p it scales by a power of two, and after e, which
+	is only possible in bases up to 14, by a power of the base. In
+	large bases p and j are digits, so p exponents and complex numbers
+	cannot be typed. Floats are always printed base 10.
+) bench &apos;expr&apos; 1000
+	Evaluate the expression, which is parsed only once, the given
+	number of times, or if no number is given, repeatedly for a
+	second. The results are not printed. Report the fastest and
+	mean times of an evaluation and the memory it allocates.
+) break name 0|1
+	Set or clear a breakpoint on the user-defined operator name.
+	When execution reaches the operator, it pauses and accepts
+	debugger commands: step (s), cont (c), locals (l), where (w),
+	and quit (q). An empty line steps. With no argument, lists
+	the breakpoints.
+) check on|off
+	Before evaluating each line, check the shapes of its results
+	where they follow from the text and the current values of
+	variables. A length mismatch between vectors in an elementwise
+	operation, or a result of rho, iota, outer product or catenation
+	bigger than maxelems, is reported without evaluating anything.
+	With no argument, report the setting.
+) clear vars|ops|all
+	Delete all variables, all user-defined operators, or both,
+	leaving the settings unchanged. The constants e and pi remain.
+) color on|off
+	Highlight the input line as it is typed, coloring numbers,
+	strings, operators and comments and flagging unbalanced
+	parentheses and brackets, and print error messages in red.
+	Requires a terminal that understands ANSI colors. With no
+	argument, report the setting.
+) copy
+	Put the text of the last result on the system clipboard.
+	Sys &quot;paste&quot; returns the text on the clipboard. The clipboard
+	is not available on mobile platforms.
 ) cpu
 	Print the duration of the last interactive calculation.
 ) debug name 0|1
 	Toggle or set the named debugging flag. With no argument, lists
-	the settings.
+	the settings. If the traceback flag is set, errors report the
+	user-defined operators that were active, innermost first. If the
+	fold flag is set, defining an operator shows the constant
+	subexpressions of its body that are evaluated once, in advance.
+	If the mem flag is set, each interactive calculation reports its
+	allocations and how many big-number temporaries it reused.
+) display
+	Show the settings for displaying large or tabular values.
+) display rows 0
+) display cols 0
+	To avoid flooding the terminal, print at most this many rows or
+	columns of each 2-d plane of a matrix, and this many elements of a
+	vector, keeping the first and last ones and printing ... in place
+	of the rest. If 0, there is no limit. The text operator always
+	produces the full text.
+) display align right
+	Set the alignment of the columns of a matrix: left, right, or
+	center. Several may be given, one per column; the last applies to
+	any remaining columns.
+) display sep &quot; &quot;
+	Set the separator printed between the columns of a matrix.
+) display tree off
+	If on, print values with nested elements as a tree: each vector
+	or matrix is introduced by a line giving its shape, followed by
+	its elements indented beneath it, those of a matrix labeled with
+	their indexes.
+) display raw off
+	If on, print vectors and matrices plainly for other programs to
+	read: a vector one element per line and a matrix one row per
+	line, its elements separated by tabs, without padding. Text
+	prints as is, and nested elements print on one line within
+	parentheses. The -raw flag sets raw on at startup.
 ) demo
 	Run a line-by-line interactive demo. On mobile platforms,
 	use the Demo menu option instead.
+) edit name
+	Open the definition of the user-defined operator name in the
+	editor named by $VISUAL or $EDITOR (default vi). When the editor
+	exits, the text is evaluated, redefining the operator. If that
+	fails, the errors are reported with their line numbers, and the
+	next )edit of the operator resumes with the edited text.
+	(Unimplemented on mobile.)
 ) format &quot;&quot;
 	Set the format for printing values. If empty, the output is printed
 	using the output base. If non-empty, the format determines the
@@ -378,6 +752,44 @@ base 10 and must be non-negative on input.
 	Read input from the named file; return to interactive execution
 	afterwards. If no file is specified, read from &quot;save.ivy&quot;.
 	(Unimplemented on mobile.)
+) history 10
+	List the most recent lines of interactive input, numbered; with
+	no argument, list them all. Typing !! on a line by itself
+	re-executes the previous line, and !n re-executes line n.
+	A reference to a line that does not exist is left alone.
+	(Write ! n, with a space, for the factorial of a literal.)
+	History is kept only for input typed at a terminal, and is
+	saved across sessions in the file named by the -history flag,
+	by default $HOME/.ivy_history, which keeps at least the last 1000 lines.
+	When ivy runs on a terminal, the input line may be edited with
+	the usual readline keys: arrows or ^B ^F ^A ^E to move, ^K ^U ^W
+	to delete and ^Y to restore the deletion, up and down arrows or
+	^P ^N to step through the history, and ^R to search it. Tab
+	completes the names of operators and variables, and of special
+	commands and help topics after ) and )help.
+) ieee off
+	If off, a floating-point result too large to represent is an
+	error, as are operations, such as 0 times infinity, that have no
+	meaningful result. If on, as in IEEE 754 arithmetic, the result
+	is instead +Inf or -Inf, or NaN, which propagate through later
+	calculations. NaN compares unequal to every value, including
+	itself; floor and ceil leave infinities unchanged. Isinf and
+	isnan report which values are infinite or NaN.
+	With no argument, )ieee reports the setting.
+) load &quot;save.ivyw&quot;
+	Restore the workspace saved in the named file by )save -b,
+	including its configuration settings. If no file is specified,
+	read from &quot;save.ivyw&quot;.
+	(Unimplemented on mobile.)
+) log &quot;ivy.log&quot;
+	Append a transcript of all subsequent interactive input and
+	output to the named file. Each line of input is preceded by a
+	comment giving the time it was read, and output is indented by
+	a tab. Lines read from files, as by )get, are not recorded, nor
+	are prompts. The
+	command )log off stops logging; with no argument, )log reports
+	the current log file.
+	(Unimplemented on mobile.)
 ) maxbits 1e6
 	To avoid consuming too much memory, if an integer result would
 	require more than this many bits to store, abort the calculation.
@@ -386,35 +798,94 @@ base 10 and must be non-negative on input.
 	To avoid overwhelming amounts of output, if an integer has more
 	than this many digits, print it using the defined floating-point
 	format. If maxdigits is 0, integers are always printed as integers.
+) maxelems 1e8
+	To avoid consuming too much memory, if a vector or matrix would
+	hold more than this many elements, abort the calculation.
+	If maxelems is 0, there is no limit; the default is 1e8.
 ) maxstack 1e5
 	To avoid using too much stack, the number of nested active calls to
 	user-defined operators is limited to maxstack.
 ) op X
 	If X is absent, list all user-defined operators. Otherwise,
-	show the definition of the user-defined operator X. Inside the
-	definition, numbers are always shown base 10, ignoring the ibase
-	and obase.
+	show the definition of the user-defined operator X, preceded by
+	declarations of any operators it uses that were defined after it.
+	Inside the definition, numbers are always shown base 10, ignoring
+	the ibase and obase.
 ) origin 1
 	Set the origin for indexing a vector or matrix. Must be non-negative.
+) plot &quot;ivy.svg&quot;
+	Set the file to which the plot operator writes. A name ending
+	.svg gets an SVG image; .gp, .gnuplot or .plt gets a gnuplot
+	script. Plotting is not available on mobile platforms.
+) polar off
+	If on, print complex numbers in polar form, as magnitude∠phase,
+	with the phase in radians, at the current format and precision.
+	The text operator follows the setting too.
+	With no argument, )polar reports the setting.
 ) prec 256
 	Set the precision (mantissa length) for floating-point values.
 	The value is in bits. The exponent always has 32 bits.
+) profile on|off|report
+	Record the number of calls to, and cumulative time spent in, each
+	built-in and user-defined operator. The on argument discards any
+	previous profile and starts recording, off stops it, and report
+	prints the operators sorted by time. With no argument, reports
+	whether profiling is on.
 ) prompt &quot;&quot;
 	Set the interactive prompt.
 ) save &quot;save.ivy&quot;
 	Write definitions of user-defined operators and variables to the
-	named file, as ivy textual source. If no file is specified, save to
-	&quot;save.ivy&quot;.
+	named file, as ivy textual source. Operators are declared before
+	use as needed, so mutually recursive definitions read back
+	correctly. If no file is specified, save to &quot;save.ivy&quot;.
+) save -b &quot;save.ivyw&quot;
+	Write the workspace to the named file in a structured (JSON)
+	format that records every value exactly, including the precision
+	of floating-point values. Restore it with )load. If no file is
+	specified, save to &quot;save.ivyw&quot;.
 	(Unimplemented on mobile.)
 ) seed 0
-	Set the seed for the ? operator.
+	Set the seed for the ? operator. The named streams of the roll
+	operator are derived from it and start again. To save and
+	restore the state of all the generators, use sys &quot;randstate&quot;.
+) step 0|1
+	Pause before every statement of every user-defined operator,
+	as if each had a breakpoint. The cont debugger command turns
+	stepping off.
+) strict 0|1
+	If 1, user-defined operators must declare each global variable
+	they read, using the :global directive. With no argument, report
+	the setting.
+) test &quot;lib_test.ivy&quot;
+	Run the named file of tests, typically using the expect
+	operator (expected expect actual), in the current context.
+	Each failed expect, and any other error, is reported with its
+	position in the file and evaluation continues. A summary of
+	the expects that passed and failed follows; if anything
+	failed, it is an error.
+	(Unimplemented on mobile.)
 ) timezone &quot;Local&quot;
 	Set the time zone to be used for display. If the argument is
 	missing, print the name and zone offset in seconds east.
+) undo
+	Restore the variables and operators to their state before the
+	previous line of input, undoing its assignments and definitions.
+	Only one line can be undone.
 ) var X
 	If X is absent, list all defined variables. Otherwise, show the
 	definition of the variable X in a form that can be evaluated
 	to recreate the value.
+) watch &quot;lib.ivy&quot;
+	Read input from the named file, like )get, and read it again
+	whenever it changes, just before the next line of interactive
+	input is evaluated. Several files may be watched. With no
+	argument, list the watched files; )watch off stops watching.
+	(Unimplemented on mobile.)
+) ws save name
+) ws load name
+	Save the variables and operators as a named workspace, kept in
+	memory, or replace them with those of a saved workspace. The
+	settings are unchanged. With no argument, list the workspaces.
 </pre>
 </body></html>
 `
@@ -88,6 +88,7 @@ func Reset() {
 	conf.SetFormat("")
 	conf.SetMaxBits(1e9)
 	conf.SetMaxDigits(1e4)
+	conf.SetMaxElems(1e8)
 	conf.SetOrigin(1)
 	conf.SetPrompt("")
 	conf.SetBase(0, 0)
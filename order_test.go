@@ -57,9 +57,17 @@ var (
 	vector012  = value.NewIntVector(0, 1, 2)
 	vector022  = value.NewIntVector(0, 2, 2)
 
-	matrix000_000 = value.NewMatrix([]int{2, 3}, newMatrixData(0, 0, 0, 0, 0, 0))
-	matrix12_34   = value.NewMatrix([]int{2, 2}, newMatrixData(1, 2, 3, 4))
-	matrix12_44   = value.NewMatrix([]int{2, 2}, newMatrixData(1, 2, 4, 4))
+	matrix000_000  = value.NewMatrix([]int{2, 3}, newMatrixData(0, 0, 0, 0, 0, 0))
+	matrix12_34    = value.NewMatrix([]int{2, 2}, newMatrixData(1, 2, 3, 4))
+	matrix12_44    = value.NewMatrix([]int{2, 2}, newMatrixData(1, 2, 4, 4))
+	matrix00_00_00 = value.NewMatrix([]int{3, 2}, newMatrixData(0, 0, 0, 0, 0, 0))
+	matrix1234     = value.NewMatrix([]int{1, 4}, newMatrixData(1, 2, 3, 4))
+	matrix1_2_3_4  = value.NewMatrix([]int{1, 2, 2}, newMatrixData(1, 2, 3, 4))
+
+	nested012_022 = value.NewVector(vector012, vector022)
+	nested022_012 = value.NewVector(vector022, vector012)
+	nestedMatrix  = value.NewVector(matrix12_34, matrix000_000)
+	nestedMatrixT = value.NewVector(matrix12_34, matrix00_00_00)
 )
 
 func newMatrixData(data ...int) *value.Vector {
@@ -271,6 +279,16 @@ func TestOrderedCompare(t *testing.T) {
 		{matrix12_34, matrix12_44, -1},
 		{matrix12_34, matrix12_34, 0},
 		{matrix12_44, matrix12_34, 1},
+		{matrix000_000, matrix00_00_00, -1}, // Then shape.
+		{matrix00_00_00, matrix000_000, 1},
+		{matrix1234, matrix12_34, -1},
+		{matrix12_34, matrix1_2_3_4, -1}, // Rank before shape.
+
+		// Nested values.
+		{nested012_022, nested022_012, -1},
+		{nested012_022, nested012_022, 0},
+		{nestedMatrix, nestedMatrixT, -1},
+		{nestedMatrixT, nestedMatrixT, 0},
 	}
 	var testConf config.Config
 	c := exec.NewContext(&testConf)
@@ -281,3 +299,37 @@ func TestOrderedCompare(t *testing.T) {
 		}
 	}
 }
+
+// TestHash verifies that equal values that have hashes have the same hash.
+func TestHash(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	values := []value.Value{
+		int0, int1, int2, int3,
+		char1, char2, char3,
+		bigInt0, bigInt1, bigInt2, bigInt3,
+		value.BigInt{Int: huge}, value.BigInt{Int: new(big.Int).Set(huge)},
+		bigRat1o1, bigRat1o7, bigFloat1p0, bigFloat1p5, complex1j0, complex1j1,
+		vector012, matrix12_34,
+	}
+	var testConf config.Config
+	c := exec.NewContext(&testConf)
+	for _, u := range values {
+		hu, uok := u.Hash()
+		switch u.(type) {
+		case value.Int, value.Char, value.BigInt:
+			if !uok {
+				t.Errorf("%T(%v) has no hash", u, u)
+			}
+		default:
+			if uok {
+				t.Errorf("%T(%v) has a hash", u, u)
+			}
+		}
+		for _, v := range values {
+			hv, vok := v.Hash()
+			if uok && vok && value.OrderedCompare(c, u, v) == 0 && hu != hv {
+				t.Errorf("%T(%v) and %T(%v) are equal but hash to %#x and %#x", u, u, v, v, hu, hv)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import (
+	"runtime"
+	"strings"
+	"time"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/scan"
+	"robpike.io/ivy/value"
+)
+
+// benchTime is how long )bench runs when not told how many times.
+const benchTime = time.Second
+
+// bench implements )bench: it parses the text once, then evaluates it
+// n times without printing the results, or if n is zero, as many times
+// as fit in benchTime. It reports the fastest and mean times per run and
+// the memory allocated per run.
+func (p *Parser) bench(text string, n int) {
+	scanner := scan.New(p.context, "<bench>", strings.NewReader(text+"\n"))
+	parser := NewParser("<bench>", scanner, p.context)
+	var exprs []value.Expr
+	for {
+		line, ok := parser.Line()
+		exprs = append(exprs, line...)
+		if !ok {
+			break
+		}
+	}
+	if len(exprs) == 0 {
+		p.errorf("bench: nothing to evaluate")
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	var total, fastest time.Duration
+	runs := 0
+	for n == 0 && total < benchTime || runs < n {
+		start := time.Now()
+		p.context.Eval(exprs)
+		d := time.Since(start)
+		if runs == 0 || d < fastest {
+			fastest = d
+		}
+		total += d
+		runs++
+	}
+	runtime.ReadMemStats(&after)
+	allocs := (after.Mallocs - before.Mallocs) / uint64(runs)
+	bytes := (after.TotalAlloc - before.TotalAlloc) / uint64(runs)
+	p.Printf("%d runs: min %s, mean %s, %d allocs/run, %d bytes/run\n",
+		runs, config.PrintDuration(fastest), config.PrintDuration(total/time.Duration(runs)), allocs, bytes)
+}
@@ -0,0 +1,186 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import (
+	"strings"
+
+	"robpike.io/ivy/value"
+)
+
+// Checking of statements before they are evaluated, enabled by )check on.
+// The checker computes the shapes of results where they follow from the
+// text and the current values of global variables, and rejects a statement
+// whose elementwise operands cannot conform or that would build a result
+// bigger than )maxelems. Whatever it cannot determine it leaves to
+// evaluation.
+
+// A shape is the shape of a value as far as it is known before evaluation.
+// A nil shape is unknown; a scalar has an empty shape.
+type shape []int
+
+var scalar = shape{}
+
+// check checks the statement exprs.
+func (p *Parser) check(exprs []value.Expr) {
+	// A variable assigned anywhere in the statement may change
+	// shape before it is read, so its current value is no guide.
+	assigned := make(map[string]bool)
+	for _, expr := range exprs {
+		walk(expr, false, func(expr value.Expr, assign bool) {
+			if v, ok := expr.(*value.VarExpr); ok && assign {
+				assigned[v.Name] = true
+			}
+		})
+	}
+	for _, expr := range exprs {
+		p.shapeOf(expr, assigned)
+	}
+}
+
+// shapeOf checks expr and returns the shape of its value, if known.
+func (p *Parser) shapeOf(expr value.Expr, assigned map[string]bool) shape {
+	switch e := expr.(type) {
+	case value.Char, value.Int, value.BigInt, value.BigRat, value.BigFloat, value.Complex, value.Time:
+		return scalar
+	case *value.Vector:
+		return shape{e.Len()}
+	case *value.Matrix:
+		return shape(e.Shape())
+	case value.VectorExpr:
+		for _, x := range e {
+			p.shapeOf(x, assigned)
+		}
+		return shape{len(e)}
+	case value.MatrixExpr:
+		for _, x := range e {
+			p.shapeOf(x, assigned)
+		}
+	case *value.IndexExpr:
+		p.shapeOf(e.Left, assigned)
+		for _, x := range e.Right {
+			if x != nil { // Not a placeholder index.
+				p.shapeOf(x, assigned)
+			}
+		}
+	case *value.VarExpr:
+		if g := p.context.Global(e.Name); g != nil && !assigned[e.Name] && g.Value() != nil {
+			return p.shapeOf(g.Value(), assigned)
+		}
+	case *value.UnaryExpr:
+		right := p.shapeOf(e.Right, assigned)
+		if e.Axis != nil || p.context.UserDefined(e.Op, false) {
+			p.shapeOf(e.Axis, assigned)
+			return nil
+		}
+		switch {
+		case e.Op == "iota":
+			if dims, ok := intConsts(e.Right); ok && len(dims) == 1 && dims[0] >= 0 {
+				return p.checkSize(e, dims)
+			}
+		case e.Op == "rho":
+			if right != nil {
+				return shape{len(right)}
+			}
+		case value.Elementwise(e.Op, false):
+			return right
+		}
+	case *value.BinaryExpr:
+		right := p.shapeOf(e.Right, assigned)
+		if e.Op == "=" {
+			return right
+		}
+		left := p.shapeOf(e.Left, assigned)
+		if e.Axis != nil || p.context.UserDefined(e.Op, true) {
+			p.shapeOf(e.Axis, assigned)
+			return nil
+		}
+		switch {
+		case e.Op == "rho":
+			if dims, ok := intConsts(e.Left); ok {
+				return p.checkSize(e, dims)
+			}
+		case strings.HasPrefix(e.Op, "o.") && left != nil && right != nil:
+			return p.checkSize(e, append(append(shape{}, left...), right...))
+		case e.Op == "," && len(left) <= 1 && len(right) <= 1 && left != nil && right != nil:
+			return p.checkSize(e, shape{count(left) + count(right)})
+		case value.Elementwise(e.Op, true):
+			return p.conform(e, left, right)
+		}
+	}
+	return nil
+}
+
+// conform returns the shape of the result of the elementwise binary
+// expression e with operands of the given shapes. Only vectors whose
+// lengths differ are rejected; other mismatches are left to evaluation.
+func (p *Parser) conform(e *value.BinaryExpr, left, right shape) shape {
+	switch {
+	case left == nil || right == nil:
+		return nil
+	case count(left) == 1 && len(left) <= 1:
+		return right
+	case count(right) == 1 && len(right) <= 1:
+		return left
+	case len(left) == 1 && len(right) == 1 && left[0] != right[0]:
+		p.errorf("check: length mismatch: %d %d in %s", left[0], right[0], e.ProgString())
+	case len(left) == len(right):
+		for i := range left {
+			if left[i] != right[i] {
+				return nil
+			}
+		}
+		return left
+	}
+	return nil
+}
+
+// checkSize returns s, the shape of the result of e, after verifying
+// it is within the )maxelems limit.
+func (p *Parser) checkSize(e value.Expr, s shape) shape {
+	limit := int64(p.context.Config().MaxElems())
+	if limit == 0 {
+		return s
+	}
+	n := int64(1)
+	for _, dim := range s {
+		if dim < 0 {
+			return nil
+		}
+		if n *= int64(dim); n > limit {
+			p.errorf("check: %s would have more than %d elements", e.ProgString(), limit)
+		}
+	}
+	return s
+}
+
+// count returns the number of elements of a value of the known shape s.
+func count(s shape) int {
+	n := 1
+	for _, dim := range s {
+		n *= dim
+	}
+	return n
+}
+
+// intConsts returns the values of expr if it is an integer constant or a
+// vector of them, such as the left operand of rho.
+func intConsts(expr value.Expr) ([]int, bool) {
+	switch e := expr.(type) {
+	case value.Int:
+		return []int{int(e)}, true
+	case value.VectorExpr:
+		dims := make([]int, len(e))
+		for i, x := range e {
+			n, ok := x.(value.Int)
+			if !ok {
+				return nil, false
+			}
+			dims[i] = int(n)
+		}
+		return dims, true
+	}
+	return nil, false
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	ivyexec "robpike.io/ivy/exec"
+	"robpike.io/ivy/scan"
+)
+
+// editKey identifies an op being edited in a context.
+type editKey struct {
+	context *ivyexec.Context
+	name    string
+}
+
+// failedEdits holds the text of edits that did not parse, so the
+// next )edit of the op can start from it rather than from the old
+// definition.
+var failedEdits = make(map[editKey]string)
+
+// editor returns the command line of the user's editor.
+func editor() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if cmd := strings.Fields(os.Getenv(env)); len(cmd) > 0 {
+			return cmd
+		}
+	}
+	return []string{"vi"}
+}
+
+// edit implements )edit: it opens the source of the named op in the
+// user's editor and, when the editor exits, evaluates the text to
+// redefine the op. Errors are reported with line numbers in the text.
+func (p *Parser) edit(name string) {
+	conf := p.context.Config()
+	if conf.Mobile() || !conf.Exec() {
+		p.errorf(")edit not enabled")
+	}
+	key := editKey{p.context, name}
+	text, ok := failedEdits[key]
+	if !ok {
+		var defs []string
+		if fn := p.context.UnaryFn[name]; fn != nil {
+			defs = append(defs, fn.String())
+		}
+		if fn := p.context.BinaryFn[name]; fn != nil {
+			defs = append(defs, fn.String())
+		}
+		if defs == nil {
+			p.errorf("%q not defined", name)
+		}
+		text = strings.Join(defs, "\n\n") + "\n"
+	}
+	dir, err := os.MkdirTemp("", "ivyedit")
+	if err != nil {
+		p.errorf("edit: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, name+".ivy")
+	if err := os.WriteFile(file, []byte(text), 0600); err != nil {
+		p.errorf("edit: %v", err)
+	}
+	args := append(editor(), file)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		p.errorf("edit: %s: %v", args[0], err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		p.errorf("edit: %v", err)
+	}
+	edited := string(data)
+	if edited == text && !ok {
+		return // Nothing changed.
+	}
+	scanner := scan.New(p.context, name+".ivy", strings.NewReader(edited))
+	parser := NewParser(name+".ivy", scanner, p.context)
+	if parser.runUntilError(name+".ivy") != io.EOF {
+		// The error has been reported. Keep the text for another try.
+		failedEdits[key] = edited
+		p.Printf("edit of %s failed; )edit %s to resume\n", name, name)
+		return
+	}
+	delete(failedEdits, key)
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import (
+	"robpike.io/ivy/value"
+)
+
+// Constant folding for op bodies, which are evaluated on every call.
+// Only exact arithmetic and comparison on integer and rational scalars
+// is folded, as its result does not depend on the configuration, and
+// only the symbolic operators, which cannot be redefined.
+
+// foldOps lists the binary operators that may be folded.
+var foldOps = map[string]bool{
+	"+":  true,
+	"-":  true,
+	"*":  true,
+	"/":  true,
+	"==": true,
+	"!=": true,
+	"<":  true,
+	"<=": true,
+	">":  true,
+	">=": true,
+}
+
+// fold folds the constant subexpressions of expr, returning the
+// rewritten expression. Expressions are rewritten in place.
+func (p *Parser) fold(expr value.Expr) value.Expr {
+	switch e := expr.(type) {
+	case *value.UnaryExpr:
+		e.Right = p.fold(e.Right)
+		if e.Axis == nil && e.Op == "-" && isFoldable(e.Right) {
+			return p.foldConst(e)
+		}
+	case *value.BinaryExpr:
+		e.Right = p.fold(e.Right)
+		if e.Op == "=" {
+			break // The left side is the target of the assignment.
+		}
+		e.Left = p.fold(e.Left)
+		if e.Axis == nil && foldOps[e.Op] && isFoldable(e.Left) && isFoldable(e.Right) {
+			return p.foldConst(e)
+		}
+	case *value.CondExpr:
+		e.Cond.Left = p.fold(e.Cond.Left)
+		e.Cond.Right = p.fold(e.Cond.Right)
+	case *value.IndexExpr:
+		e.Left = p.fold(e.Left)
+		for i, x := range e.Right {
+			if x != nil { // Not a placeholder index.
+				e.Right[i] = p.fold(x)
+			}
+		}
+	case value.VectorExpr:
+		for i, x := range e {
+			e[i] = p.fold(x)
+		}
+	case value.MatrixExpr:
+		for i, x := range e {
+			e[i] = p.fold(x)
+		}
+	}
+	return expr
+}
+
+// isFoldable reports whether expr is an integer or rational constant.
+func isFoldable(expr value.Expr) bool {
+	switch e := expr.(type) {
+	case value.Int, value.BigInt, value.BigRat:
+		return true
+	case *value.ConstExpr:
+		return isFoldable(e.Value)
+	}
+	return false
+}
+
+// foldConst evaluates the constant expression expr. If evaluation
+// fails, expr is left to fail when the op is run.
+func (p *Parser) foldConst(expr value.Expr) (result value.Expr) {
+	defer func() {
+		if err := recover(); err != nil {
+			if _, ok := err.(value.Error); !ok {
+				panic(err)
+			}
+			result = expr
+		}
+	}()
+	v := expr.Eval(p.context)
+	if p.context.Config().Debug("fold") > 0 {
+		p.Printf("fold %s => %s\n", expr.ProgString(), v.ProgString())
+	}
+	return &value.ConstExpr{Expr: expr, Value: v}
+}
@@ -6,6 +6,8 @@ package parse
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"robpike.io/ivy/exec"
 	"robpike.io/ivy/scan"
@@ -17,8 +19,10 @@ import (
 //	"op" name arg <eol>
 //	"op" name arg '=' statements <eol>
 //	"op" arg name arg '=' statements <eol>
-//	"opdelete" name arg <eol>
-//	"opdelete" arg name arg <eol>
+//	"op" "inverse" name arg '=' statements <eol>
+//	"op" "inverse" arg name arg '=' statements <eol>
+//	"opdelete" ["inverse"] name arg <eol>
+//	"opdelete" ["inverse"] arg name arg <eol>
 //
 // statements:
 //
@@ -34,6 +38,10 @@ func (p *Parser) functionDefn() {
 		p.errorf("unexpected %s", tok) // Cannot happen but be safe.
 	}
 	fn := new(exec.Function)
+	if p.atInverseDefn() {
+		p.next()
+		fn.Inverse = true
+	}
 	// Two identifiers means: op arg.
 	// Three identifiers means: arg op arg.
 	// arg can be name or parenthesized list of args.
@@ -54,7 +62,11 @@ func (p *Parser) functionDefn() {
 	// Undefine if so requested.
 	if undefine {
 		p.need(scan.EOF)
-		p.context.Undefine(fn.Name, len(args) == 3)
+		if fn.Inverse {
+			p.context.UndefineInverse(fn.Name, len(args) == 3)
+		} else {
+			p.context.Undefine(fn.Name, len(args) == 3)
+		}
 		return
 	}
 
@@ -83,15 +95,25 @@ func (p *Parser) functionDefn() {
 		walkVars(fn.Left, declare)
 		walkVars(fn.Right, declare)
 		installMap = p.context.BinaryFn
+		if fn.Inverse {
+			installMap = p.context.BinaryInverse
+		}
 	} else {
 		fn.Right = args[1]
 		walkVars(fn.Right, declare)
 		installMap = p.context.UnaryFn
+		if fn.Inverse {
+			installMap = p.context.UnaryInverse
+		}
+	}
+	define := p.context.Define
+	if fn.Inverse {
+		define = p.context.DefineInverse
 	}
 
 	// Define it, but prepare to undefine if there's trouble.
 	prevDefn := installMap[fn.Name]
-	p.context.Define(fn)
+	define(fn)
 	defer p.context.ForgetAll()
 	succeeded := false
 	defer func() {
@@ -120,6 +142,7 @@ func (p *Parser) functionDefn() {
 			if !p.readTokensToNewline(true) {
 				p.errorf("invalid function definition")
 			}
+			p.opDirectives(fn, true)
 			for p.peek().Type != scan.EOF {
 				x, ok := p.expressionList()
 				if !ok {
@@ -134,6 +157,7 @@ func (p *Parser) functionDefn() {
 		} else {
 			// Single line.
 			var ok bool
+			p.opDirectives(fn, false)
 			fn.Body, ok = p.expressionList()
 			if !ok {
 				p.errorf("invalid function definition")
@@ -146,14 +170,129 @@ func (p *Parser) functionDefn() {
 	default:
 		p.errorf("expected newline after function declaration, found %s", tok)
 	}
-	p.context.Define(fn)
-	funcVars(fn)
+	fn.Refs = references(p.context, fn.Body)
+	define(fn)
+	undeclared := funcVars(fn)
+	fn.Pure = pure(fn)
+	for i, expr := range fn.Body {
+		fn.Body[i] = p.fold(expr)
+	}
+	if len(undeclared) > 0 && p.context.Config().Strict() {
+		p.errorf("strict: undeclared global %q in %s", undeclared[0], fn.Name)
+	}
 	succeeded = true
 	if p.context.Config().Debug("parse") > 0 {
 		p.Printf("op %s %s %s = %s\n", fn.Left.ProgString(), fn.Name, fn.Right.ProgString(), tree(fn.Body))
 	}
 }
 
+// atInverseDefn reports whether the definition that begins the remaining
+// input is of an inverse: "inverse" followed by the two or three
+// arguments and name of an op. Otherwise "inverse" is the name of the
+// op being defined, or its left argument.
+func (p *Parser) atInverseDefn() bool {
+	if tok := p.peek(); tok.Type != scan.Identifier || tok.Text != "inverse" {
+		return false
+	}
+	n, depth := 0, 0
+	for _, tok := range p.tokens[1:] {
+		switch tok.Type {
+		case scan.Identifier:
+			if depth == 0 {
+				n++
+			}
+		case scan.LeftParen:
+			if depth == 0 {
+				n++
+			}
+			depth++
+		case scan.RightParen:
+			depth--
+		default:
+			return n == 2 || n == 3
+		}
+	}
+	return n == 2 || n == 3
+}
+
+// opDirectives parses the directives at the start of a function body.
+// Each ends with a semicolon or, in a multiline definition, a newline.
+//
+//	(':' directive (';' | '\n'))*
+//
+// directive:
+//
+//	"origin" number
+//	"local" name+
+//	"global" name+
+func (p *Parser) opDirectives(fn *exec.Function, multiline bool) {
+	for p.peek().Type == scan.Colon {
+		p.opDirective(fn)
+		switch tok := p.peek(); tok.Type {
+		case scan.Semicolon:
+			p.next()
+		case scan.EOF:
+			if !multiline {
+				return
+			}
+			if !p.readTokensToNewline(true) {
+				p.errorf("invalid function definition")
+			}
+		default:
+			p.errorf("unexpected %s after op directive", tok)
+		}
+	}
+}
+
+// opDirective parses a single directive and records it in fn.
+func (p *Parser) opDirective(fn *exec.Function) {
+	p.next() // Colon.
+	tok := p.next()
+	if tok.Type != scan.Identifier {
+		p.errorf("unknown op directive :%s", tok.Text)
+	}
+	switch tok.Text {
+	case "origin":
+		origin := p.nextDecimalNumber()
+		if origin < 0 {
+			p.errorf("illegal origin %d", origin)
+		}
+		fn.PinOrigin = true
+		fn.Origin = origin
+	case "local", "global":
+		if p.peek().Type != scan.Identifier {
+			p.errorf(":%s needs variable names", tok.Text)
+		}
+		for p.peek().Type == scan.Identifier {
+			name := p.next().Text
+			if isArg(fn.Left, name) || isArg(fn.Right, name) {
+				p.errorf("argument %q cannot be declared :%s", name, tok.Text)
+			}
+			if slices.Contains(fn.LocalDecls, name) || slices.Contains(fn.GlobalDecls, name) {
+				p.errorf("%q declared twice", name)
+			}
+			if tok.Text == "local" {
+				fn.LocalDecls = append(fn.LocalDecls, name)
+			} else {
+				fn.GlobalDecls = append(fn.GlobalDecls, name)
+			}
+		}
+	default:
+		p.errorf("unknown op directive :%s", tok.Text)
+	}
+}
+
+// isArg reports whether name is one of the variables in the argument arg.
+func isArg(arg value.Expr, name string) bool {
+	found := false
+	if arg != nil {
+		walkVars(arg, func(x *value.VarExpr) {
+			found = found || x.Name == name
+		})
+	}
+	return found
+}
+
 // function argument
 //	name | '(' args ')'
 func (p *Parser) funcArg() value.Expr {
@@ -173,20 +312,23 @@ func (p *Parser) funcArg() value.Expr {
 }
 
 // references returns a list, in appearance order, of the user-defined ops
-// referenced by this function body. Only the first appearance creates an
-// entry in the list.
+// referenced by this function body, including those used as the operand
+// of a reduction, scan, each, or product. Only the first appearance creates
+// an entry in the list.
 func references(c *exec.Context, body []value.Expr) []exec.OpDef {
 	var refs []exec.OpDef
 	for _, expr := range body {
 		walk(expr, false, func(expr value.Expr, _ bool) {
+			var ops []exec.OpDef
 			switch e := expr.(type) {
 			case *value.UnaryExpr:
-				if c.UnaryFn[e.Op] != nil {
-					addReference(&refs, e.Op, false)
-				}
+				ops = operands(e.Op, false)
 			case *value.BinaryExpr:
-				if c.BinaryFn[e.Op] != nil {
-					addReference(&refs, e.Op, true)
+				ops = operands(e.Op, true)
+			}
+			for _, op := range ops {
+				if c.UserDefined(op.Name, op.IsBinary) {
+					addReference(&refs, op.Name, op.IsBinary)
 				}
 			}
 		})
@@ -194,6 +336,49 @@ func references(c *exec.Context, body []value.Expr) []exec.OpDef {
 	return refs
 }
 
+// operands returns the ops invoked by the operator op, which may be
+// a compound such as +/ or +.* as well as a simple op.
+func operands(op string, isBinary bool) []exec.OpDef {
+	ops := func(isBinary bool, names ...string) []exec.OpDef {
+		defs := make([]exec.OpDef, len(names))
+		for i, name := range names {
+			defs[i] = exec.OpDef{Name: name, IsBinary: isBinary}
+		}
+		return defs
+	}
+	if i := strings.LastIndex(op, " under "); i >= 0 {
+		return append(operands(op[:i], isBinary), operands(op[i+len(" under "):], false)...)
+	}
+	if name, ok := strings.CutPrefix(op, "inverse "); ok {
+		return operands(name, isBinary)
+	}
+	if !isBinary {
+		switch {
+		case strings.HasSuffix(op, "#@"): // Each with index.
+			return ops(true, op[:len(op)-2])
+		case len(op) > 1 && strings.HasSuffix(op, "@"): // Each.
+			return ops(false, strings.TrimRight(op, "@"))
+		case len(op) > 2 && (strings.HasSuffix(op, "/%") || strings.HasSuffix(op, `\%`)):
+			return ops(true, op[:len(op)-2])
+		case len(op) > 1 && (strings.HasSuffix(op, "/") || strings.HasSuffix(op, `\`)):
+			return ops(true, op[:len(op)-1])
+		}
+		return ops(false, op)
+	}
+	switch {
+	case len(op) > 1 && strings.Trim(op, "@") != op: // Each.
+		return ops(true, strings.Trim(op, "@"))
+	case len(op) > 1 && strings.Contains(op, "."): // Inner or outer product.
+		left, right, _ := strings.Cut(op, ".")
+		return ops(true, left, right)
+	case len(op) > 2 && strings.HasSuffix(op, "/%"): // N-wise reduction.
+		return ops(true, op[:len(op)-2])
+	case len(op) > 1 && strings.HasSuffix(op, "/"):
+		return ops(true, op[:len(op)-1])
+	}
+	return ops(true, op)
+}
+
 func addReference(refs *[]exec.OpDef, name string, isBinary bool) {
 	// If it's already there, ignore. This is n^2 but n is tiny.
 	for _, ref := range *refs {
@@ -210,15 +395,19 @@ func addReference(refs *[]exec.OpDef, name string, isBinary bool) {
 
 // funcVars sets fn.Locals and fn.Globals
 // to the lists of variables that are local versus global.
-// A variable assigned to before any read is a local.
-// A variable read before any assignment to is a global.
+// A variable declared by a :local or :global directive is
+// as declared. Otherwise, a variable assigned to before any
+// read is a local and a variable read before any assignment
+// to is a global.
 //
 // A function that wants to assign blindly to a global
-// can first do a throwaway read, as in
+// can declare it with :global or first do a throwaway read, as in
 //
 //	_ = x # global x
 //	x = 1
-func funcVars(fn *exec.Function) {
+//
+// funcVars returns the globals that were not declared.
+func funcVars(fn *exec.Function) (undeclared []string) {
 	known := make(map[string]int)
 	addLocal := func(e *value.VarExpr) {
 		fn.Locals = append(fn.Locals, e.Name)
@@ -233,6 +422,7 @@ func funcVars(fn *exec.Function) {
 					addLocal(e)
 				} else {
 					known[e.Name] = 0
+					undeclared = append(undeclared, e.Name)
 				}
 				x = known[e.Name]
 			}
@@ -245,10 +435,55 @@ func funcVars(fn *exec.Function) {
 	if fn.Right != nil {
 		walk(fn.Right, true, f)
 	}
+	for _, name := range fn.LocalDecls {
+		addLocal(value.NewVarExpr(name))
+	}
+	for _, name := range fn.GlobalDecls {
+		known[name] = 0
+	}
 	for _, e := range fn.Body {
 		walk(e, false, f)
 	}
-	return
+	return undeclared
+}
+
+// pure reports whether the body of fn, whose variables have been resolved
+// by funcVars, is free of side effects: it assigns no global variables,
+// pins no index origin, and uses no builtin op with side effects. The
+// user-defined ops it refers to are checked when it runs.
+func pure(fn *exec.Function) bool {
+	if fn.PinOrigin {
+		return false // Setting the origin changes the shared configuration.
+	}
+	ok := true
+	global := func(expr value.Expr, _ bool) {
+		if e, isVar := expr.(*value.VarExpr); isVar && e.Local == 0 {
+			ok = false
+		}
+	}
+	// The walk covers both branches of a lazy ifelse, which are
+	// elements of its right operand.
+	for _, expr := range fn.Body {
+		walk(expr, false, func(expr value.Expr, _ bool) {
+			var ops []exec.OpDef
+			switch e := expr.(type) {
+			case *value.UnaryExpr:
+				ops = operands(e.Op, false)
+			case *value.BinaryExpr:
+				if e.Op == "=" {
+					// Also catches indexed assignment to a global.
+					walk(e.Left, true, global)
+				}
+				ops = operands(e.Op, true)
+			}
+			for _, op := range ops {
+				if value.Impure(op.Name) {
+					ok = false
+				}
+			}
+		})
+	}
+	return ok
 }
 
 // walk traverses expr in right-to-left order,
@@ -259,10 +494,16 @@ func walk(expr value.Expr, assign bool, f func(value.Expr, bool)) {
 	switch e := expr.(type) {
 	case *value.UnaryExpr:
 		walk(e.Right, false, f)
+		if e.Axis != nil {
+			walk(e.Axis, false, f)
+		}
 	case *value.CondExpr:
 		walk(e.Cond, false, f)
 	case *value.BinaryExpr:
 		walk(e.Right, false, f)
+		if e.Axis != nil {
+			walk(e.Axis, false, f)
+		}
 		walk(e.Left, e.Op == "=", f)
 	case *value.IndexExpr:
 		for i := len(e.Right) - 1; i >= 0; i-- {
@@ -273,16 +514,22 @@ func walk(expr value.Expr, assign bool, f func(value.Expr, bool)) {
 		}
 		walk(e.Left, false, f)
 	case *value.VarExpr:
+	case *value.ConstExpr:
 	case value.VectorExpr:
 		for i := len(e) - 1; i >= 0; i-- {
 			walk(e[i], assign, f)
 		}
+	case value.MatrixExpr:
+		for i := len(e) - 1; i >= 0; i-- {
+			walk(e[i], false, f)
+		}
 	case value.Char:
 	case value.Int:
 	case value.BigInt:
 	case value.BigRat:
 	case value.BigFloat:
 	case value.Complex:
+	case value.Time:
 	case *value.Vector:
 	case *value.Matrix:
 	default:
@@ -33,11 +33,26 @@ var helpLines = []string{
 	"since the spacing turns the / into a division operator. Use parentheses",
 	"or spaces to disambiguate: 3/(6*4) or 3 /6*4.",
 	"",
+	"Similarly, a minus sign is part of a number if it is preceded by a",
+	"space and followed immediately by a digit, so 3 -2 is the vector 3 -2",
+	"but 3-2 and 3 - 2 are 1. APL's high minus, as in ¯2, is always the sign",
+	"of a number, never an operator, so 1 ¯2 3 and x ¯2 are vectors wherever",
+	"they appear. It may also introduce a negative exponent, as in 1e¯5.",
+	"",
 	"Ivy has complex numbers, which are constructed using the unary or",
 	"binary j operator. As with rationals, the token 1j2 (the representation",
 	"of 1+2i) is a single token. The individual parts can be rational,",
 	"so 1/2j-3/2 is the complex number 0.5-1.5i and scans as a single",
-	"value.",
+	"value. The binary polar operator builds a complex number from its",
+	"magnitude and phase: 2 polar pi/2 is (near enough) 0j2.",
+	"",
+	"A matrix may be written directly, row by row, in brackets with the rows",
+	"separated by semicolons: [1 2 3; 4 5 6] is the 2x3 matrix with those",
+	"rows. The rows may be any expressions but must all have the same shape;",
+	"rows that are matrices stack to make a matrix of higher rank. After an",
+	"operator, a bracketed expression is taken as an axis, as in +/[1] x,",
+	"unless it holds a semicolon or ends the expression; otherwise use",
+	"parentheses: -([1 2 3]) * 2.",
 	"",
 	"Indexing uses [] notation: x[1], x[1; 2], and so on. Indexing by a",
 	"vector selects multiple elements: x[1 2] creates a new item from",
@@ -45,13 +60,38 @@ var helpLines = []string{
 	"elements along that dimension, so x[] is equivalent to x, and x[;3]",
 	"gives the third column of two-dimensional array x.",
 	"",
+	"Where take, fill, or reshape of an empty vector must pad an array, they use",
+	"its fill element, the prototype of its first element: a blank for a char, zero",
+	"for any other scalar, and for a nested item, an item of the same shape holding",
+	"the prototypes of its elements. Thus 3 take (1 'ab') 2 is (1 'ab') 2 (0 '  ').",
+	"An empty vector has fill element zero, which is also its first.",
+	"",
+	"Elementwise binary operators such as + extend a scalar to the shape of the",
+	"other operand, and a vector along the last axis of a matrix. More generally,",
+	"with the shapes aligned at their last axes, an axis of length 1, or a missing",
+	"leading axis, stretches to match the other operand, so",
+	"(3 1 rho iota 3) + 1 2 3 4 is a 3 by 4 matrix. Any other difference in shape",
+	"is an error.",
+	"",
 	"Only a subset of APL's functionality is implemented, but all numerical",
 	"operations are supported.",
 	"",
+	"A backslash preceded by a space and ending a line, apart from any comment,",
+	"continues the line: the next line is read as part of the same one. This works",
+	"both inside and outside operator definitions:",
+	"",
+	"\tx = 1 + 2 + \\   # partial sums",
+	"\t    3 + 4",
+	"",
 	"Semicolons separate multiple statements on a line. Variables are",
 	"alphanumeric and are assigned with the = operator. Assignment is",
 	"an expression.",
 	"",
+	"Selected elements of a variable may be assigned through a boolean",
+	"mask and sel: ((x<0) sel x) = 0 sets the negative elements of x to",
+	"zero, the same as x[where x<0] = 0. The right-hand side may be a",
+	"scalar or a vector holding a value for each selected element.",
+	"",
 	"After each successful expression evaluation, except for assignments",
 	"and calls to the print operator, the result is stored in the variable",
 	"called _ (underscore) so it can be used in the next expression",
@@ -73,16 +113,24 @@ var helpLines = []string{
 	"\tShape             ⍴B    rho     Vector of number of components in each dimension of B",
 	"\tCount             ≢B    count   Scalar number of elements at top level of B",
 	"\tFlatten           ∊B    flatten Vector of all the scalar elements within B",
+	"\tRaze              ⊃,/B  raze    Items of B joined into one vector, undoing part,",
+	"\t                                one level only, unlike flatten; matrix items are",
+	"\t                                catenated along the first axis; for a matrix B,",
+	"\t                                the items in each row are joined",
 	"\tNot               ∼B    not     Logical: not 1 is 0, not 0 is 1",
 	"\tAbsolute value    ∣B    abs     Magnitude of B",
 	"\tIndex generator   ⍳B    iota    Vector of the first B integers",
 	"\t                                If B is a vector, matrix of coordinates",
 	"\tWhere             ⍸B    where   Vector of indexes where B is non-zero",
+	"\t                                Positive integers in B repeat the index;",
+	"\t                                if B is a matrix, vector of coordinates",
+	"\tWhere             ⍸B    sel     Same as where",
 	"\tUnique            ∪B    unique  Remove all duplicate elements from B",
 	"\tEnclose           ⊂B    box     Wrap B in one level of nesting",
 	"\tDisclose          ⊃B    first   First element of B in ravel order",
 	"\tSplit             ↓B    split   Create vector of nested elements from matrix B; inverse of mix",
 	"\tMix               ↑B    mix     Create matrix from elements of vector B; inverse of split",
+	"\tDepth             ≡B    depth   Levels of nesting in B: 0 for a scalar, 1 for a simple vector",
 	"\tExponential       ⋆B    **      e to the B power",
 	"\tNegation          −B    -       Change sign of B",
 	"\tIdentity          +B    +       No change to B",
@@ -96,12 +144,49 @@ var helpLines = []string{
 	"\tReversal          ⊖B    flip    Reverse elements of B along first axis",
 	"\tGrade up          ⍋B    up      Indices of B which will arrange B in ascending order",
 	"\tGrade down        ⍒B    down    Indices of B which will arrange B in descending order",
+	"\tSort                    sort    B arranged in ascending order; rows of a matrix",
+	"\t                                are sorted as units",
+	"\tReverse sort            rsort   B arranged in descending order",
+	"\tGroup                   group   Vector of vectors of the indexes of each unique",
+	"\t                                element of B, in order of first appearance",
+	"\tRunning maximum         cummax  Maximum of each prefix of B, as for max\\B, along last axis",
+	"\tRunning minimum         cummin  Minimum of each prefix of B, as for min\\B, along last axis",
+	"\tDifferences             diff    Each element of B minus the one before, along last axis",
+	"\tHistogram               hist    Counts of the real numbers B in 1+ceil(log2 rho B) bins",
+	"\t                                of equal width spanning B, as for binary hist",
+	"\tCartesian product       cross   Cartesian product of the elements of B, as for",
+	"\t                                B[1] cross B[2] cross ...",
+	"\tWeekday                 weekday Day of the week of time B: 0 for Sunday to 6 for Saturday",
+	"\tUpper case              upper   Text B in upper case; the length may change, as for ß",
+	"\tLower case              lower   Text B in lower case",
+	"\tCompose                 nfc     Text B in Unicode normalization form C (composed)",
+	"\tDecompose               nfd     Text B in Unicode normalization form D (decomposed)",
+	"\tHex encode              hex     Text of hexadecimal digits for bytes B (0 to 255);",
+	"\t                                text B stands for its UTF-8 bytes",
+	"\tHex decode              unhex   Byte values encoded in the hexadecimal text B",
+	"\tBase64 encode           base64  Text of base64 encoding of bytes B, as for hex",
+	"\tBase64 decode           unbase64 Byte values encoded in the base64 text B",
 	"\tExecute           ⍎B    ivy     Execute an APL (ivy) expression",
 	"\tMonadic format    ⍕B    text    A character representation of B",
+	"\tType                    type    The name of the type of B as text, such as int or matrix",
+	"\tArity                   arity   1 if the op named by text B is unary, 2 if binary, or 1 2",
+	"\tPlot                    plot    Draw vector B, or each row of matrix B, against its",
+	"\t                                indices in the file set by )plot; yields the file name",
+	"\tExact decimal           decimal Text of rational B as an exact decimal, with any",
+	"\t                                repeating digits in parentheses: 1/6 is 0.1(6)",
 	"\tMonadic transpose ⍉B    transp  Reverse the axes of B",
-	"\tFactorial         !B    !       Product of integers 1 to B",
+	"\tFactorial         !B    !       Product of integers 1 to B; for non-integer",
+	"\t                                or complex B, gamma(B+1)",
+	"\tInfinity test           isinf   1 if B is +Inf or -Inf, or complex with such a part; 0 if not",
+	"\tNaN test                isnan   1 if B is NaN, or complex with a NaN part; 0 if not",
 	"\tBitwise not             ^       Bitwise complement of B (integer only)",
+	"\tPopulation count        popcount Number of 1 bits in non-negative integer B",
+	"\tBit length              bitlen  Number of bits needed to hold abs B (integer only)",
+	"\tBalanced ternary        baltern Balanced ternary digits (-1 0 1) of integer B;",
+	"\t                                3 decode baltern B is B",
 	"\tSquare root       B⋆.5  sqrt    Square root of B.",
+	"\tInteger sqrt            isqrt   Square root of integer B, rounded down; exact however large",
+	"\tPower test              ispower 1 if integer B is m**k for integers m and k>1; 0 if not",
 	"\tSine                    sin     sin(A); APL uses binary ○ (see below)",
 	"\tCosine                  cos     cos(A); ditto",
 	"\tTangent                 tan     tan(A); ditto",
@@ -138,45 +223,122 @@ var helpLines = []string{
 	"\t                            cos       cos(B); ivy uses traditional name.",
 	"\t                            tan       tan(B); ivy uses traditional name.",
 	"\tDeal                  A?B   ?         A distinct integers selected randomly from the first B integers",
+	"\tRoll from stream            roll      As for ?B, but drawn from the random stream named by the",
+	"\t                                      text A, independent of ? and of other streams",
 	"\tMembership            A∈B   in        1 for elements of A present in B; 0 where not.",
+	"\tCount of                    countin   Number of times each element of A appears in B",
 	"\tIntersection          A∩B   intersect A with all elements not in B removed",
 	"\tUnion                 A∪B   union     A followed by all members of B not already in A",
+	"\tWithout               A~B   without   A with all elements in B removed",
+	"\tUnique within               unique    B without elements within A of an earlier element kept;",
+	"\t                                      B must be real numbers",
+	"\tHistogram                   hist      Counts of the real numbers B in each bin: A is the",
+	"\t                                      increasing edges of the bins, or the number of bins",
+	"\t                                      of equal width from min B to max B; each bin holds",
+	"\t                                      its lower edge, the last also its upper edge",
+	"\tInterpolation               interp    Linear interpolation at B in the table xs ys held by A,",
+	"\t                                      where xs increases; outside the table, A may add",
+	"\t                                      'clamp' (default), 'extend', or 'error'",
+	"\tFind root                   findroot  A zero of the unary op named by text A between the",
+	"\t                                      bounds B, where the op changes sign, by bisection",
+	"\tMinimize                    minimize  Where the unary op named by text A is least between",
+	"\t                                      the bounds B, for an op with one minimum there",
+	"\tIntegral                    integrate The integral of the unary op named by text A between",
+	"\t                                      the bounds B, for an op smooth between them",
+	"\tDerivative                  deriv     The derivative of the unary op named by text A at B",
+	"\tRecurrence                  recurrence Term B, from the index origin, of the sequence",
+	"\t                                      whose initial values are the second of A and whose",
+	"\t                                      later terms are the sum of the coefficients, the",
+	"\t                                      first of A, times the terms before, latest first",
+	"\tMatrix power                mpow      The square matrix A to the power B, by +.*",
+	"\tCartesian product           cross     Matrix whose rows are each tuple of A followed by",
+	"\t                                      each tuple of B, the last changing fastest; each row",
+	"\t                                      of a matrix is a tuple, as is each element of a",
+	"\t                                      vector and a scalar",
+	"\tFind                  A⍷B   find      1 where pattern A begins in B; 0 elsewhere.",
+	"\t                                      A vector A is sought in each row of a matrix B",
 	"\tMaximum               A⌈B   max       The greater value of A or B",
 	"\tMinimum               A⌊B   min       The smaller value of A or B",
 	"\tReshape               A⍴B   rho       Array of shape A with data B",
-	"\tTake                  A↑B   take      Select the first (or last) A elements of B according to sgn A",
+	"\tDisclose                    first     Apply first A times, descending A levels of nesting",
+	"\tSplit                       split     Array of the cells formed by the last A axes of B",
+	"\tTake                  A↑B   take      Select the first (or last) A elements of B according to sgn A;",
+	"\t                                      overtaking pads B with fill elements (see below)",
 	"\tDrop                  A↓B   drop      Remove the first (or last) A elements of B according to sgn A",
 	"\tDecode                A⊥B   decode    Value of a polynomial whose coefficients are B at A",
 	"\t                                      'T' decode B creates a seconds value from the time vector B",
-	"\tEncode                A⊤B   encode    Base-A representation of the value of B",
+	"\tEncode                A⊤B   encode    Base-A representation of the value of B, one digit",
+	"\t                                      per radix of A; as in APL, a zero radix takes all",
+	"\t                                      that remains and each digit has the sign of its radix",
 	"\t                                      'T' encode B creates a time vector from the seconds value B",
+	"\tRadix                       radix     Digits of integer B in base A, which may be negative,",
+	"\t                                      as many as needed, most significant first;",
+	"\t                                      A decode A radix B is B",
 	"\tResidue               A∣B              B modulo A",
 	"\t                            mod       A modulo B (Euclidean)",
 	"\t                            imod      A modulo B (Go)",
+	"\t                            divmod    The pair (A div B) (A mod B) from one division, so",
+	"\t                                      (q r) = A divmod B sets both; works for non-integers",
 	"\tCatenation            A,B   ,         Elements of B appended to the elements of A along last axis",
 	"\tCatenation            A,B   ,%        Elements of B appended to the elements of A along first axis",
-	"\tExpansion             A\\B   fill      Insert zeros (or blanks) in B corresponding to zeros in A",
-	"\t                                      In ivy: abs(A) gives count, A <= 0 inserts zero (or blank)",
+	"\tLaminate                    lam       A and B, of the same shape, joined along a new first axis;",
+	"\t                                      a scalar is extended (APL A,[0.5]B)",
+	"\tExpansion             A\\B   fill      Insert fill elements in B corresponding to zeros in A",
+	"\t                                      In ivy: abs(A) gives count, A <= 0 inserts fill elements",
 	"\tCompression           A/B   sel       Select elements in B corresponding to ones in A",
-	"\t                                      In ivy: abs(A) gives count, A <= 0 inserts zero",
+	"\t                                      In ivy: abs(A) gives count, A <= 0 inserts zero;",
+	"\t                                      if A has the shape of B, each element of A is the",
+	"\t                                      count for the corresponding element of B",
+	"\tCompression           A⌿B   sel[1]    Select rows of B corresponding to ones in A",
+	"\tExpansion             A⍀B   fill[1]   Insert rows of fill elements in B",
+	"\tWhere                       where     Elements of A where B is non-zero, B times each, as",
+	"\t                                      for unary where; if A and B are matrices, each row",
+	"\t                                      holds the coordinates of an element followed by it",
 	"\tPartition             A⊆B   part      Vector of subvectors of B grouped by elements of A:",
 	"\t                                      If 0, ignore; otherwise start new group at boundaries",
-	"\t                                      where elements of A increase",
+	"\t                                      where elements of A increase. A is non-negative integers,",
+	"\t                                      one for each element of B, or a single value for all;",
+	"\t                                      for a matrix B, each row is partitioned by A, and",
+	"\t                                      part[1] partitions down the columns.",
+	"\t                                      raze (A part B) is B if A has no zeros",
 	"\tIndex of              A⍳B   iota      The location (index) of B in A; 1+⌈/⍳⍴A if not found",
 	"\t                                      In ivy: origin-1 if not found (that is, 0 if one-indexed)",
+	"\tProgressive index of        pio       As for iota, but each occurrence of a value in vector B",
+	"\t                                      finds the next unused occurrence in vector A",
+	"\tSort                        sort      B arranged by ascending order of the key A,",
+	"\t                                      which has one element (or row) per element of B",
+	"\t                            rsort     B arranged by descending order of the key A",
+	"\tGroup                       group     Vector of vectors of the elements of B, one for each",
+	"\t                                      unique key in A, in order of first appearance;",
+	"\t                                      unique A gives the keys",
+	"\tTop k                       topk      The A largest elements of vector B, largest first;",
+	"\t                                      if A < 0, the -A smallest, smallest first",
+	"\tInterval index        A⍸B   interval  For each element of B, the index i of the interval",
+	"\t                                      A[i] <= B < A[i+1] of sorted A; origin-1 if below A[1]",
 	"\tMatrix divide         A⌹B   mdiv      Solution to system of linear equations Bx = A",
 	"\t                                      For real vectors, the magnitude of A projected on B",
 	"\tRotation              A⌽B   rot       The elements of B are rotated A positions left",
 	"\tRotation              A⊖B   flip      The elements of B are rotated A positions along the first axis",
 	"\tLogarithm             A⍟B   log       Logarithm of B to base A",
+	"\tRoot                        root      The Ath root of B, for positive integer A; exact if",
+	"\t                                      B is a rational with an exact root",
+	"\tSplit on delimiter          fields    Vector of the texts in text B separated by delimiter A,",
+	"\t                                      or by white space if A is empty; splits each row of a matrix",
 	"\tDyadic format         A⍕B   text      Format B into a character matrix according to A",
 	"\t                                      A is the textual format (see format special command);",
 	"\t                                      otherwise result depends on length of A:",
 	"\t                                      1 gives decimal count, 2 gives width and decimal count,",
 	"\t                                      3 gives width, decimal count, and style ('d', 'e', 'f', etc.).",
 	"\t                                      'T' text B formats seconds value B as a Unix date",
+	"\tIf-else                     ifelse    First element of two-element vector B if scalar A is",
+	"\t                                      true, else the second; if B is written as two",
+	"\t                                      expressions, only the chosen one is evaluated",
+	"\tPlot                        plot      As for unary plot, but draw B against the values of vector A",
+	"\tExport                      export    Text of scalar, vector or matrix B as a table to paste",
+	"\t                                      into a document; A is 'latex', 'markdown', or 'html'",
 	"\tGeneral transpose     A⍉B   transp    The axes of B are ordered by A",
-	"\tCombinations          A!B   !         Number of combinations of B taken A at a time",
+	"\tCombinations          A!B   !         Number of combinations of B taken A at a time;",
+	"\t                                      for non-integers, (!B)/(!A)*!B-A via gamma",
 	"\tLess than             A<B   <         Comparison (elementwise): 1 if true, 0 if false",
 	"\tLess than or equal    A≤B   <=        Comparison (elementwise): 1 if true, 0 if false",
 	"\tEqual                 A=B   ==        Comparison (elementwise): 1 if true, 0 if false",
@@ -185,23 +347,52 @@ var helpLines = []string{
 	"\tNot equal             A≠B   !=        Comparison (elementwise): 1 if true, 0 if false",
 	"\tMatch                 A≡B   ===       Comparison (overall): 1 if true, 0 if false",
 	"\tNot match             A≠B   !==       Comparison (overall): 1 if true, 0 if false",
+	"\tCompare                     cmp       Comparison (elementwise): -1, 0, or 1 as A is below,",
+	"\t                                      equal to, or above B in the total order used by sort,",
+	"\t                                      which places chars below numbers",
+	"\tExpect                      expect    For testing: B, unprinted, if it matches A in shape and",
+	"\t                                      elements, as for ===; otherwise an error",
 	"\tOr                    A∨B   or        Logic: 0 if A and B are 0; 1 otherwise",
 	"\tAnd                   A∧B   and       Logic: 1 if A and B are 1; 0 otherwise",
 	"\tNor                   A⍱B   nor       Logic: 1 if both A and B are 0; otherwise 0",
 	"\tNand                  A⍲B   nand      Logic: 0 if both A and B are 1; otherwise 1",
 	"\tXor                         xor       Logic: 1 if A != B; otherwise 0",
+	"\tConditional and             &&        Logic: 1 if scalars A and B are true; B is not",
+	"\t                                      evaluated if A is false",
+	"\tConditional or              ||        Logic: 1 if scalar A or B is true; B is not",
+	"\t                                      evaluated if A is true",
 	"\tBitwise and                 &         Bitwise A and B (integer only)",
 	"\tBitwise or                  |         Bitwise A or B (integer only)",
 	"\tBitwise xor                 ^         Bitwise A exclusive or B (integer only)",
-	"\tLeft shift                  <<        A shifted left B bits (integer only)",
-	"\tRight Shift                 >>        A shifted right B bits (integer only)",
+	"\tLeft shift                  <<        A shifted left B bits; for rationals and floats,",
+	"\t                                      A times 2**B, exactly",
+	"\tRight Shift                 >>        A shifted right B bits; for rationals and floats,",
+	"\t                                      A divided by 2**B, exactly",
+	"\tGet bit                     getbit    Bit number A of B, counting from 0 (integer only)",
+	"\tSet bit                     setbit    B with bit number A set to 1 (integer only)",
+	"\tRotate bits                 rotbits   With A the pair N W, the low W bits of B rotated",
+	"\t                                      left N bits (right if N<0); B must fit in W bits",
+	"\tModular inverse             invmod    The integer X in 0 to B-1 such that (A*X) mod B is 1",
+	"\tModular power               powmod    With B the pair E M, (A**E) mod M without computing",
+	"\t                                      A**E; a negative E uses the modular inverse of A",
 	"\tComplex construction        j         The complex number A+Bi",
+	"\tPolar construction          polar     The complex number with magnitude A and phase B",
+	"\tAdd months                  addmonths Time A moved B calendar months, keeping the time of day;",
+	"\t                                      a day past the end of the month becomes its last day",
+	"\tAdd years                   addyears  Time A moved B calendar years, as for addmonths",
+	"\tDate range                  todates   Times one day apart from time A through time B",
+	"\tBusiness days               busdays   Number of days Monday through Friday from the date",
+	"\t                                      of time A up to but not including that of time B",
 	"",
 	"Operators and axis indicator",
 	"",
 	"\tName                APL  Ivy  APL Example  Ivy Example  Meaning (of example)",
 	"\tReduce (last axis)  /    /    +/B          +/B          Sum across B",
 	"\tReduce (first axis) ⌿    /%   +⌿B                       Sum down B",
+	"\tN-wise reduce       /    /    2-/B         2 -/B        Differences of pairs in B",
+	"\t                                                        (negative N runs each",
+	"\t                                                        window backwards; /%",
+	"\t                                                        for first axis)",
 	"\tScan (last axis)    \\    \\    +\\B          +\\B          Running sum across B",
 	"\tScan (first axis)   ⍀    \\%   +⍀B                       Running sum down B",
 	"\tInner product       .    .    A+.×B        A +.* B      Matrix product of A and B",
@@ -212,6 +403,24 @@ var helpLines = []string{
 	"\t                                                        as vector or matrix",
 	"\tEach right          f¨   f@   A f¨ B       A f@ B       (A f B[1]), (A f B[2]), ...",
 	"\t                                                        as vector or matrix",
+	"\tEach with index          f#@               f#@ B        (1 f B[1]), (2 f B[2]), ...",
+	"\t                                                        as vector or matrix; for a",
+	"\t                                                        matrix, the coordinates of",
+	"\t                                                        each element are the index",
+	"\tInverse             ⍣¯1  inverse           inverse f B  X such that f X is B;",
+	"\t                                                        A inverse f B is X such",
+	"\t                                                        that A f X is B",
+	"\tUnder               ⍢    under f⍢g B       f under g B  inverse g f g B;",
+	"\t                                                        A f under g B is",
+	"\t                                                        inverse g (g A) f (g B)",
+	"\tAxis                [K]  [K]  +/[1]B       +/[1]B       Sum along axis 1 of B;",
+	"\t                                                        axis (origin-based) also",
+	"\t                                                        for scan, rot, flip, sel,",
+	"\t                                                        fill, part, raze, cummax,",
+	"\t                                                        cummin, diff and",
+	"\t                                                        catenation, as in",
+	"\t                                                        A ,[1] B; with an axis,",
+	"\t                                                        rot and flip are the same",
 	"",
 	"Type-converting operations",
 	"",
@@ -221,6 +430,7 @@ var helpLines = []string{
 	"\tFloat                   float B The floating-point representation of B;",
 	"\t                                for complex numbers, the result is",
 	"\t                                (float A)j(float B)",
+	"\tTime                    time B  The time B seconds after 1970.01.01T00:00:00Z",
 	"",
 	"# Pre-defined constants",
 	"",
@@ -249,7 +459,38 @@ var helpLines = []string{
 	"Chars have restricted operations. Printing, comparison, indexing and so on are",
 	"legal but arithmetic is not, and chars cannot be converted automatically into other",
 	"singleton values (ints, floats, and so on). The unary operators char and code",
-	"enable transcoding between integer and char values.",
+	"enable transcoding between integer and char values. The unary operators upper",
+	"and lower convert the case of text, and nfc and nfd normalize it; they treat the",
+	"text as a whole, so the result may have a different length.",
+	"",
+	"# Time",
+	"",
+	"A time literal is a date written with periods, optionally followed by T and a",
+	"time of day: 2024.01.15, 2024.01.15T10:30, 2024.01.15T10:30:00.5. It denotes",
+	"that moment in the time zone set by )timezone, or in UTC if it ends in Z.",
+	"Times print in the configured time zone, without the time of day if it is",
+	"midnight.",
+	"",
+	"Times may be compared, and the difference of two times is the number of seconds",
+	"between them. Adding seconds to, or subtracting them from, a time yields a time:",
+	"",
+	"\t2024.01.15T10:30 - 2024.01.15",
+	"\tresult: 37800",
+	"\t2024.01.15 + 86400",
+	"\tresult: 2024.01.16",
+	"",
+	"The time operator converts a seconds value to a time, and 'T' encode and",
+	"'T' text accept times as well as seconds. Thus time 'T' decode V is the",
+	"time described by the time vector V, and T - time 0 is the seconds value",
+	"of the time T.",
+	"",
+	"Calendar operators such as weekday, addmonths and todates, listed above, work",
+	"on dates in the configured time zone.",
+	"",
+	"\tweekday 2024.01.15",
+	"\tresult: 1",
+	"\t2024.01.31 addmonths 1",
+	"\tresult: 2024.02.29",
 	"",
 	"# User-defined operators",
 	"",
@@ -272,15 +513,30 @@ var helpLines = []string{
 	"The body may be a single line (possibly containing semicolons) on the same line",
 	"as the 'op', or it can be multiple lines. For a multiline entry, there is a",
 	"newline after the '=' and the definition ends at the first blank line (ignoring",
-	"spaces).",
+	"spaces). A line holding only a comment is not blank, so comments may separate",
+	"the parts of a long body.",
 	"",
 	"Conditional execution is done with the \":\" binary conditional return",
-	"operator, which is valid only within the code for a user-defined",
 	"operator. The left operand must be a scalar or one-element vector",
 	"or matrix. If it is non-zero, the right operand is returned as the",
 	"value of the function. Otherwise, execution continues normally. The",
 	"\":\" operator has a lower precedence than any other operator; in",
-	"effect it breaks the line into two separate expressions.",
+	"effect it breaks the line into two separate expressions. Outside an",
+	"operator, a conditional works the same way on a line: if the condition",
+	"is true, the right operand is printed and the rest of the line is",
+	"skipped, so x<0: 'negative'; 'non-negative' prints one or the other.",
+	"",
+	"Within an expression, the binary ifelse operator chooses between two",
+	"values: c ifelse A B is A if the scalar c is true and B otherwise. When",
+	"the right operand is written as two expressions, such as (x[n]) 0, only",
+	"the chosen one is evaluated, so (n <= rho x) ifelse (x[n]) 0 is safe",
+	"when n is out of range, and a recursive op may use ifelse to stop. If",
+	"the right operand is a computed value, such as a variable, both elements",
+	"have already been evaluated.",
+	"",
+	"Similarly, the binary operators && and || evaluate their left operand",
+	"first and their right operand only if it decides the result, so",
+	"(n > 0) && x[n] == 1 does not index x when n is 0.",
 	"",
 	"Example: average of a vector (unary):",
 	"",
@@ -326,6 +582,14 @@ var helpLines = []string{
 	"\t\te % 2: (b * modexp b (e-1) m) mod m",
 	"\t\tmodexp ((b**2) mod m) (e>>1) m",
 	"",
+	"The built-in powmod does the same job far faster: b powmod e m.",
+	"",
+	"Ivy has no loop statements. To apply an operator to each element of a vector",
+	"in turn, with no index to maintain, use the each operator @:",
+	"",
+	"\t12 gcd@ 18 20 27",
+	"\tresult: 6 4 3",
+	"",
 	"On mobile platforms only, due to I/O restrictions, user-defined operators",
 	"must be presented on a single line. Use semicolons to separate expressions:",
 	"",
@@ -356,11 +620,68 @@ var helpLines = []string{
 	"\ttotal last",
 	"\tresult: 12 3",
 	"",
+	"The body may instead begin with directives that declare variables explicitly.",
+	"The directive \":local\" names variables that are local even if read before being",
+	"written, and \":global\" names variables that are global even if written before",
+	"being read. Directives end with a semicolon or newline.",
+	"",
+	"\top save x = :global total last; total = total + x; last = x",
+	"",
+	"After the special command \")strict 1\", an operator that reads a global it has",
+	"not declared with \":global\" is rejected when it is defined. This protects a",
+	"library of operators from misreading a local as a global, or vice versa.",
+	"",
+	"An operator body may begin with the directive \":origin N\", which runs the",
+	"operator with index origin N whatever the origin of its caller, and restores",
+	"the caller's origin on return. This lets a library of operators work under",
+	"any setting of )origin.",
+	"",
+	"\top first3 x = :origin 1; x[1 2 3]",
+	"\t)origin 0",
+	"\tfirst3 10 20 30 40",
+	"\tresult: 10 20 30",
+	"",
+	"An operator is pure if it assigns no global variables, has no :origin",
+	"directive, uses no operator with side effects such as ?, print, or sys, and",
+	"calls only pure operators. Like most builtins, a pure operator applied by an",
+	"outer or inner product, a reduction, a scan, or elementwise to the items of a",
+	"large array may be evaluated in parallel.",
+	"",
+	"The inverse operator undoes an operator: inverse - and inverse / negate and",
+	"take the reciprocal, inverse ** is log, inverse +\\ takes differences, and",
+	"A inverse rot B and A inverse encode B rotate and decode. The inverse of a",
+	"binary operator is with respect to its right argument, so 2 inverse + 5 is 3.",
+	"The word inverse must be followed by an operator; otherwise it is an ordinary",
+	"name. A user-defined operator may be given an inverse by a definition that",
+	"starts 'op inverse':",
+	"",
+	"\top double x = 2*x",
+	"\top inverse double x = x/2",
+	"\tinverse double 6",
+	"\tresult: 3",
+	"",
+	"\top a plus b = a+b",
+	"\top inverse a plus b = b-a",
+	"",
+	"With inverses, the under operator applies an operator in a transformed domain:",
+	"f under g B transforms B by g, applies f, and transforms the result back by the",
+	"inverse of g. Binary, A f under g B transforms both arguments. Thus 2 + under",
+	"log 3 is 6, and to round to two decimal places,",
+	"",
+	"\top cents x = 100*x",
+	"\top inverse cents x = x/100",
+	"\tfloor under cents 3.14159",
+	"\tresult: 157/50",
+	"",
 	"To remove the definition of a unary or binary user-defined operator,",
 	"",
 	"\topdelete foo x",
 	"\topdelete a gcd b",
 	"",
+	"which also removes any inverse. To remove only the inverse, say",
+	"",
+	"\topdelete inverse foo x",
+	"",
 	"# Special commands",
 	"",
 	"Ivy accepts a number of special commands, introduced by a right paren",
@@ -374,18 +695,93 @@ var helpLines = []string{
 	"\t) base 0",
 	"\t\tSet the number base for input and output. The commands ibase and",
 	"\t\tobase control setting of the base for input and output alone,",
-	"\t\trespectively.  Base 0 allows C-style input: decimal, with 037 being",
-	"\t\toctal and 0x10 being hexadecimal. Bases above 16 are disallowed.",
-	"\t\tTo output large integers and rationals, base must be one of",
-	"\t\t0 2 8 10 16. Floats are always printed base 10.",
+	"\t\trespectively.  Base 0 allows C-style input: decimal, with 037 and",
+	"\t\t0o37 being octal, 0x10 hexadecimal and 0b101 binary; hexadecimal",
+	"\t\tfloats such as 0x1.8p3 take a power-of-two exponent. Bases above",
+	"\t\t10 use the letters a-z (or A-Z on input) as digits; bases above 36",
+	"\t\tare disallowed. In other bases, the digits after a point are in",
+	"\t\tthat base too, so in base 16 1.8 is 3/2. An exponent is always",
+	"\t\tdecimal: after p it scales by a power of two, and after e, which",
+	"\t\tis only possible in bases up to 14, by a power of the base. In",
+	"\t\tlarge bases p and j are digits, so p exponents and complex numbers",
+	"\t\tcannot be typed. Floats are always printed base 10.",
+	"\t) bench 'expr' 1000",
+	"\t\tEvaluate the expression, which is parsed only once, the given",
+	"\t\tnumber of times, or if no number is given, repeatedly for a",
+	"\t\tsecond. The results are not printed. Report the fastest and",
+	"\t\tmean times of an evaluation and the memory it allocates.",
+	"\t) break name 0|1",
+	"\t\tSet or clear a breakpoint on the user-defined operator name.",
+	"\t\tWhen execution reaches the operator, it pauses and accepts",
+	"\t\tdebugger commands: step (s), cont (c), locals (l), where (w),",
+	"\t\tand quit (q). An empty line steps. With no argument, lists",
+	"\t\tthe breakpoints.",
+	"\t) check on|off",
+	"\t\tBefore evaluating each line, check the shapes of its results",
+	"\t\twhere they follow from the text and the current values of",
+	"\t\tvariables. A length mismatch between vectors in an elementwise",
+	"\t\toperation, or a result of rho, iota, outer product or catenation",
+	"\t\tbigger than maxelems, is reported without evaluating anything.",
+	"\t\tWith no argument, report the setting.",
+	"\t) clear vars|ops|all",
+	"\t\tDelete all variables, all user-defined operators, or both,",
+	"\t\tleaving the settings unchanged. The constants e and pi remain.",
+	"\t) color on|off",
+	"\t\tHighlight the input line as it is typed, coloring numbers,",
+	"\t\tstrings, operators and comments and flagging unbalanced",
+	"\t\tparentheses and brackets, and print error messages in red.",
+	"\t\tRequires a terminal that understands ANSI colors. With no",
+	"\t\targument, report the setting.",
+	"\t) copy",
+	"\t\tPut the text of the last result on the system clipboard.",
+	"\t\tSys \"paste\" returns the text on the clipboard. The clipboard",
+	"\t\tis not available on mobile platforms.",
 	"\t) cpu",
 	"\t\tPrint the duration of the last interactive calculation.",
 	"\t) debug name 0|1",
 	"\t\tToggle or set the named debugging flag. With no argument, lists",
-	"\t\tthe settings.",
+	"\t\tthe settings. If the traceback flag is set, errors report the",
+	"\t\tuser-defined operators that were active, innermost first. If the",
+	"\t\tfold flag is set, defining an operator shows the constant",
+	"\t\tsubexpressions of its body that are evaluated once, in advance.",
+	"\t\tIf the mem flag is set, each interactive calculation reports its",
+	"\t\tallocations and how many big-number temporaries it reused.",
+	"\t) display",
+	"\t\tShow the settings for displaying large or tabular values.",
+	"\t) display rows 0",
+	"\t) display cols 0",
+	"\t\tTo avoid flooding the terminal, print at most this many rows or",
+	"\t\tcolumns of each 2-d plane of a matrix, and this many elements of a",
+	"\t\tvector, keeping the first and last ones and printing ... in place",
+	"\t\tof the rest. If 0, there is no limit. The text operator always",
+	"\t\tproduces the full text.",
+	"\t) display align right",
+	"\t\tSet the alignment of the columns of a matrix: left, right, or",
+	"\t\tcenter. Several may be given, one per column; the last applies to",
+	"\t\tany remaining columns.",
+	"\t) display sep \" \"",
+	"\t\tSet the separator printed between the columns of a matrix.",
+	"\t) display tree off",
+	"\t\tIf on, print values with nested elements as a tree: each vector",
+	"\t\tor matrix is introduced by a line giving its shape, followed by",
+	"\t\tits elements indented beneath it, those of a matrix labeled with",
+	"\t\ttheir indexes.",
+	"\t) display raw off",
+	"\t\tIf on, print vectors and matrices plainly for other programs to",
+	"\t\tread: a vector one element per line and a matrix one row per",
+	"\t\tline, its elements separated by tabs, without padding. Text",
+	"\t\tprints as is, and nested elements print on one line within",
+	"\t\tparentheses. The -raw flag sets raw on at startup.",
 	"\t) demo",
 	"\t\tRun a line-by-line interactive demo. On mobile platforms,",
 	"\t\tuse the Demo menu option instead.",
+	"\t) edit name",
+	"\t\tOpen the definition of the user-defined operator name in the",
+	"\t\teditor named by $VISUAL or $EDITOR (default vi). When the editor",
+	"\t\texits, the text is evaluated, redefining the operator. If that",
+	"\t\tfails, the errors are reported with their line numbers, and the",
+	"\t\tnext )edit of the operator resumes with the edited text.",
+	"\t\t(Unimplemented on mobile.)",
 	"\t) format \"\"",
 	"\t\tSet the format for printing values. If empty, the output is printed",
 	"\t\tusing the output base. If non-empty, the format determines the",
@@ -395,6 +791,44 @@ var helpLines = []string{
 	"\t\tRead input from the named file; return to interactive execution",
 	"\t\tafterwards. If no file is specified, read from \"save.ivy\".",
 	"\t\t(Unimplemented on mobile.)",
+	"\t) history 10",
+	"\t\tList the most recent lines of interactive input, numbered; with",
+	"\t\tno argument, list them all. Typing !! on a line by itself",
+	"\t\tre-executes the previous line, and !n re-executes line n.",
+	"\t\tA reference to a line that does not exist is left alone.",
+	"\t\t(Write ! n, with a space, for the factorial of a literal.)",
+	"\t\tHistory is kept only for input typed at a terminal, and is",
+	"\t\tsaved across sessions in the file named by the -history flag,",
+	"\t\tby default $HOME/.ivy_history, which keeps at least the last 1000 lines.",
+	"\t\tWhen ivy runs on a terminal, the input line may be edited with",
+	"\t\tthe usual readline keys: arrows or ^B ^F ^A ^E to move, ^K ^U ^W",
+	"\t\tto delete and ^Y to restore the deletion, up and down arrows or",
+	"\t\t^P ^N to step through the history, and ^R to search it. Tab",
+	"\t\tcompletes the names of operators and variables, and of special",
+	"\t\tcommands and help topics after ) and )help.",
+	"\t) ieee off",
+	"\t\tIf off, a floating-point result too large to represent is an",
+	"\t\terror, as are operations, such as 0 times infinity, that have no",
+	"\t\tmeaningful result. If on, as in IEEE 754 arithmetic, the result",
+	"\t\tis instead +Inf or -Inf, or NaN, which propagate through later",
+	"\t\tcalculations. NaN compares unequal to every value, including",
+	"\t\titself; floor and ceil leave infinities unchanged. Isinf and",
+	"\t\tisnan report which values are infinite or NaN.",
+	"\t\tWith no argument, )ieee reports the setting.",
+	"\t) load \"save.ivyw\"",
+	"\t\tRestore the workspace saved in the named file by )save -b,",
+	"\t\tincluding its configuration settings. If no file is specified,",
+	"\t\tread from \"save.ivyw\".",
+	"\t\t(Unimplemented on mobile.)",
+	"\t) log \"ivy.log\"",
+	"\t\tAppend a transcript of all subsequent interactive input and",
+	"\t\toutput to the named file. Each line of input is preceded by a",
+	"\t\tcomment giving the time it was read, and output is indented by",
+	"\t\ta tab. Lines read from files, as by )get, are not recorded, nor",
+	"\t\tare prompts. The",
+	"\t\tcommand )log off stops logging; with no argument, )log reports",
+	"\t\tthe current log file.",
+	"\t\t(Unimplemented on mobile.)",
 	"\t) maxbits 1e6",
 	"\t\tTo avoid consuming too much memory, if an integer result would",
 	"\t\trequire more than this many bits to store, abort the calculation.",
@@ -403,35 +837,94 @@ var helpLines = []string{
 	"\t\tTo avoid overwhelming amounts of output, if an integer has more",
 	"\t\tthan this many digits, print it using the defined floating-point",
 	"\t\tformat. If maxdigits is 0, integers are always printed as integers.",
+	"\t) maxelems 1e8",
+	"\t\tTo avoid consuming too much memory, if a vector or matrix would",
+	"\t\thold more than this many elements, abort the calculation.",
+	"\t\tIf maxelems is 0, there is no limit; the default is 1e8.",
 	"\t) maxstack 1e5",
 	"\t\tTo avoid using too much stack, the number of nested active calls to",
 	"\t\tuser-defined operators is limited to maxstack.",
 	"\t) op X",
 	"\t\tIf X is absent, list all user-defined operators. Otherwise,",
-	"\t\tshow the definition of the user-defined operator X. Inside the",
-	"\t\tdefinition, numbers are always shown base 10, ignoring the ibase",
-	"\t\tand obase.",
+	"\t\tshow the definition of the user-defined operator X, preceded by",
+	"\t\tdeclarations of any operators it uses that were defined after it.",
+	"\t\tInside the definition, numbers are always shown base 10, ignoring",
+	"\t\tthe ibase and obase.",
 	"\t) origin 1",
 	"\t\tSet the origin for indexing a vector or matrix. Must be non-negative.",
+	"\t) plot \"ivy.svg\"",
+	"\t\tSet the file to which the plot operator writes. A name ending",
+	"\t\t.svg gets an SVG image; .gp, .gnuplot or .plt gets a gnuplot",
+	"\t\tscript. Plotting is not available on mobile platforms.",
+	"\t) polar off",
+	"\t\tIf on, print complex numbers in polar form, as magnitude∠phase,",
+	"\t\twith the phase in radians, at the current format and precision.",
+	"\t\tThe text operator follows the setting too.",
+	"\t\tWith no argument, )polar reports the setting.",
 	"\t) prec 256",
 	"\t\tSet the precision (mantissa length) for floating-point values.",
 	"\t\tThe value is in bits. The exponent always has 32 bits.",
+	"\t) profile on|off|report",
+	"\t\tRecord the number of calls to, and cumulative time spent in, each",
+	"\t\tbuilt-in and user-defined operator. The on argument discards any",
+	"\t\tprevious profile and starts recording, off stops it, and report",
+	"\t\tprints the operators sorted by time. With no argument, reports",
+	"\t\twhether profiling is on.",
 	"\t) prompt \"\"",
 	"\t\tSet the interactive prompt.",
 	"\t) save \"save.ivy\"",
 	"\t\tWrite definitions of user-defined operators and variables to the",
-	"\t\tnamed file, as ivy textual source. If no file is specified, save to",
-	"\t\t\"save.ivy\".",
+	"\t\tnamed file, as ivy textual source. Operators are declared before",
+	"\t\tuse as needed, so mutually recursive definitions read back",
+	"\t\tcorrectly. If no file is specified, save to \"save.ivy\".",
+	"\t) save -b \"save.ivyw\"",
+	"\t\tWrite the workspace to the named file in a structured (JSON)",
+	"\t\tformat that records every value exactly, including the precision",
+	"\t\tof floating-point values. Restore it with )load. If no file is",
+	"\t\tspecified, save to \"save.ivyw\".",
 	"\t\t(Unimplemented on mobile.)",
 	"\t) seed 0",
-	"\t\tSet the seed for the ? operator.",
+	"\t\tSet the seed for the ? operator. The named streams of the roll",
+	"\t\toperator are derived from it and start again. To save and",
+	"\t\trestore the state of all the generators, use sys \"randstate\".",
+	"\t) step 0|1",
+	"\t\tPause before every statement of every user-defined operator,",
+	"\t\tas if each had a breakpoint. The cont debugger command turns",
+	"\t\tstepping off.",
+	"\t) strict 0|1",
+	"\t\tIf 1, user-defined operators must declare each global variable",
+	"\t\tthey read, using the :global directive. With no argument, report",
+	"\t\tthe setting.",
+	"\t) test \"lib_test.ivy\"",
+	"\t\tRun the named file of tests, typically using the expect",
+	"\t\toperator (expected expect actual), in the current context.",
+	"\t\tEach failed expect, and any other error, is reported with its",
+	"\t\tposition in the file and evaluation continues. A summary of",
+	"\t\tthe expects that passed and failed follows; if anything",
+	"\t\tfailed, it is an error.",
+	"\t\t(Unimplemented on mobile.)",
 	"\t) timezone \"Local\"",
 	"\t\tSet the time zone to be used for display. If the argument is",
 	"\t\tmissing, print the name and zone offset in seconds east.",
+	"\t) undo",
+	"\t\tRestore the variables and operators to their state before the",
+	"\t\tprevious line of input, undoing its assignments and definitions.",
+	"\t\tOnly one line can be undone.",
 	"\t) var X",
 	"\t\tIf X is absent, list all defined variables. Otherwise, show the",
 	"\t\tdefinition of the variable X in a form that can be evaluated",
 	"\t\tto recreate the value.",
+	"\t) watch \"lib.ivy\"",
+	"\t\tRead input from the named file, like )get, and read it again",
+	"\t\twhenever it changes, just before the next line of interactive",
+	"\t\tinput is evaluated. Several files may be watched. With no",
+	"\t\targument, list the watched files; )watch off stops watching.",
+	"\t\t(Unimplemented on mobile.)",
+	"\t) ws save name",
+	"\t) ws load name",
+	"\t\tSave the variables and operators as a named workspace, kept in",
+	"\t\tmemory, or replace them with those of a saved workspace. The",
+	"\t\tsettings are unchanged. With no argument, list the workspaces.",
 }
 
 type helpIndexPair struct {
@@ -439,123 +932,204 @@ type helpIndexPair struct {
 }
 
 var helpUnary = map[string]helpIndexPair{
-	"?":       {61, 61},
-	"rand":    {62, 62},
-	"ceil":    {63, 64},
-	"floor":   {65, 66},
-	"rho":     {67, 67},
-	"count":   {68, 68},
-	"flatten": {69, 69},
-	"not":     {70, 70},
-	"abs":     {71, 71},
-	"iota":    {72, 73},
-	"where":   {74, 74},
-	"unique":  {75, 75},
-	"box":     {76, 76},
-	"first":   {77, 77},
-	"split":   {78, 78},
-	"mix":     {79, 79},
-	"**":      {80, 80},
-	"-":       {81, 81},
-	"+":       {82, 82},
-	"sgn":     {83, 83},
-	"/":       {84, 84},
-	",":       {85, 85},
-	"inv":     {86, 86},
-	"log":     {88, 88},
-	"rot":     {89, 89},
-	"flip":    {90, 90},
-	"up":      {91, 91},
-	"down":    {92, 92},
-	"ivy":     {93, 93},
-	"text":    {94, 94},
-	"transp":  {95, 95},
-	"!":       {96, 96},
-	"^":       {97, 97},
-	"sqrt":    {98, 98},
-	"sin":     {99, 99},
-	"cos":     {100, 100},
-	"tan":     {101, 101},
-	"asin":    {102, 102},
-	"acos":    {103, 103},
-	"atan":    {104, 104},
-	"sinh":    {105, 105},
-	"cosh":    {106, 106},
-	"tanh":    {107, 107},
-	"asinh":   {108, 108},
-	"acosh":   {109, 109},
-	"atanh":   {110, 110},
-	"j":       {111, 111},
-	"real":    {112, 112},
-	"imag":    {113, 113},
-	"phase":   {114, 114},
-	"conj":    {115, 115},
-	"sys":     {116, 116},
-	"print":   {117, 117},
-	"code":    {213, 213},
-	"char":    {214, 214},
-	"float":   {215, 217},
+	"?":        {101, 101},
+	"rand":     {102, 102},
+	"ceil":     {103, 104},
+	"floor":    {105, 106},
+	"rho":      {107, 107},
+	"count":    {108, 108},
+	"flatten":  {109, 109},
+	"raze":     {110, 113},
+	"not":      {114, 114},
+	"abs":      {115, 115},
+	"iota":     {116, 117},
+	"where":    {118, 120},
+	"sel":      {121, 121},
+	"unique":   {122, 122},
+	"box":      {123, 123},
+	"first":    {124, 124},
+	"split":    {125, 125},
+	"mix":      {126, 126},
+	"depth":    {127, 127},
+	"**":       {128, 128},
+	"-":        {129, 129},
+	"+":        {130, 130},
+	"sgn":      {131, 131},
+	"/":        {132, 132},
+	",":        {133, 133},
+	"inv":      {134, 134},
+	"log":      {136, 136},
+	"rot":      {137, 137},
+	"flip":     {138, 138},
+	"up":       {139, 139},
+	"down":     {140, 140},
+	"sort":     {141, 142},
+	"rsort":    {143, 143},
+	"group":    {144, 145},
+	"cummax":   {146, 146},
+	"cummin":   {147, 147},
+	"diff":     {148, 148},
+	"hist":     {149, 150},
+	"cross":    {151, 152},
+	"weekday":  {153, 153},
+	"upper":    {154, 154},
+	"lower":    {155, 155},
+	"nfc":      {156, 156},
+	"nfd":      {157, 157},
+	"hex":      {158, 159},
+	"unhex":    {160, 160},
+	"base64":   {161, 161},
+	"unbase64": {162, 162},
+	"ivy":      {163, 163},
+	"text":     {164, 164},
+	"type":     {165, 165},
+	"arity":    {166, 166},
+	"plot":     {167, 168},
+	"decimal":  {169, 170},
+	"transp":   {171, 171},
+	"!":        {172, 173},
+	"isinf":    {174, 174},
+	"isnan":    {175, 175},
+	"^":        {176, 176},
+	"popcount": {177, 177},
+	"bitlen":   {178, 178},
+	"baltern":  {179, 180},
+	"sqrt":     {181, 181},
+	"isqrt":    {182, 182},
+	"ispower":  {183, 183},
+	"sin":      {184, 184},
+	"cos":      {185, 185},
+	"tan":      {186, 186},
+	"asin":     {187, 187},
+	"acos":     {188, 188},
+	"atan":     {189, 189},
+	"sinh":     {190, 190},
+	"cosh":     {191, 191},
+	"tanh":     {192, 192},
+	"asinh":    {193, 193},
+	"acosh":    {194, 194},
+	"atanh":    {195, 195},
+	"j":        {196, 196},
+	"real":     {197, 197},
+	"imag":     {198, 198},
+	"phase":    {199, 199},
+	"conj":     {200, 200},
+	"sys":      {201, 201},
+	"print":    {202, 202},
+	"code":     {422, 422},
+	"char":     {423, 423},
+	"float":    {424, 426},
+	"time":     {427, 427},
 }
 
 var helpBinary = map[string]helpIndexPair{
-	"+":         {122, 122},
-	"-":         {123, 123},
-	"*":         {124, 124},
-	"/":         {125, 127},
-	"**":        {128, 128},
-	"?":         {134, 134},
-	"in":        {135, 135},
-	"intersect": {136, 136},
-	"union":     {137, 137},
-	"max":       {138, 138},
-	"min":       {139, 139},
-	"rho":       {140, 140},
-	"take":      {141, 141},
-	"drop":      {142, 142},
-	"decode":    {143, 144},
-	"encode":    {145, 146},
-	"mod":       {148, 149},
-	",":         {150, 150},
-	",%":        {151, 151},
-	"fill":      {152, 153},
-	"sel":       {154, 155},
-	"part":      {156, 158},
-	"iota":      {159, 160},
-	"mdiv":      {161, 162},
-	"rot":       {163, 163},
-	"flip":      {164, 164},
-	"log":       {165, 165},
-	"text":      {166, 171},
-	"transp":    {172, 172},
-	"!":         {173, 173},
-	"<":         {174, 174},
-	"<=":        {175, 175},
-	"==":        {176, 176},
-	">=":        {177, 177},
-	">":         {178, 178},
-	"!=":        {179, 179},
-	"===":       {180, 180},
-	"!==":       {181, 181},
-	"or":        {182, 182},
-	"and":       {183, 183},
-	"nor":       {184, 184},
-	"nand":      {185, 185},
-	"xor":       {186, 186},
-	"&":         {187, 187},
-	"|":         {188, 188},
-	"^":         {189, 189},
-	"<<":        {190, 190},
-	">>":        {191, 191},
-	"j":         {192, 192},
+	"+":          {207, 207},
+	"-":          {208, 208},
+	"*":          {209, 209},
+	"/":          {210, 212},
+	"**":         {213, 213},
+	"?":          {219, 219},
+	"roll":       {220, 221},
+	"in":         {222, 222},
+	"countin":    {223, 223},
+	"intersect":  {224, 224},
+	"union":      {225, 225},
+	"without":    {226, 226},
+	"unique":     {227, 228},
+	"hist":       {229, 232},
+	"interp":     {233, 235},
+	"findroot":   {236, 237},
+	"minimize":   {238, 239},
+	"integrate":  {240, 241},
+	"deriv":      {242, 242},
+	"recurrence": {243, 246},
+	"mpow":       {247, 247},
+	"cross":      {248, 251},
+	"find":       {252, 253},
+	"max":        {254, 254},
+	"min":        {255, 255},
+	"rho":        {256, 256},
+	"first":      {257, 257},
+	"split":      {258, 258},
+	"take":       {259, 260},
+	"drop":       {261, 261},
+	"decode":     {262, 263},
+	"encode":     {264, 267},
+	"radix":      {268, 270},
+	"mod":        {272, 275},
+	",":          {276, 276},
+	",%":         {277, 277},
+	"lam":        {278, 279},
+	"fill":       {280, 281},
+	"sel":        {282, 285},
+	"sel[1]":     {286, 286},
+	"fill[1]":    {287, 287},
+	"where":      {288, 290},
+	"part":       {291, 297},
+	"iota":       {298, 299},
+	"pio":        {300, 301},
+	"sort":       {302, 304},
+	"group":      {305, 307},
+	"topk":       {308, 309},
+	"interval":   {310, 311},
+	"mdiv":       {312, 313},
+	"rot":        {314, 314},
+	"flip":       {315, 315},
+	"log":        {316, 316},
+	"root":       {317, 318},
+	"fields":     {319, 320},
+	"text":       {321, 326},
+	"ifelse":     {327, 329},
+	"plot":       {330, 330},
+	"export":     {331, 332},
+	"transp":     {333, 333},
+	"!":          {334, 335},
+	"<":          {336, 336},
+	"<=":         {337, 337},
+	"==":         {338, 338},
+	">=":         {339, 339},
+	">":          {340, 340},
+	"!=":         {341, 341},
+	"===":        {342, 342},
+	"!==":        {343, 343},
+	"cmp":        {344, 346},
+	"expect":     {347, 348},
+	"or":         {349, 349},
+	"and":        {350, 350},
+	"nor":        {351, 351},
+	"nand":       {352, 352},
+	"xor":        {353, 353},
+	"&&":         {354, 355},
+	"||":         {356, 357},
+	"&":          {358, 358},
+	"|":          {359, 359},
+	"^":          {360, 360},
+	"<<":         {361, 362},
+	">>":         {363, 364},
+	"getbit":     {365, 365},
+	"setbit":     {366, 366},
+	"rotbits":    {367, 368},
+	"invmod":     {369, 369},
+	"powmod":     {370, 371},
+	"j":          {372, 372},
+	"polar":      {373, 373},
+	"addmonths":  {374, 375},
+	"addyears":   {376, 376},
+	"todates":    {377, 377},
+	"busdays":    {378, 379},
 }
 
 var helpAxis = map[string]helpIndexPair{
-	"/":   {197, 197},
-	"/%":  {198, 198},
-	"\\":  {199, 199},
-	"\\%": {200, 200},
-	".":   {201, 201},
-	"o.":  {202, 202},
-	"@f":  {205, 205},
-	"f@":  {207, 207},
+	"/":       {384, 384},
+	"/%":      {385, 385},
+	"\\":      {390, 390},
+	"\\%":     {391, 391},
+	".":       {392, 392},
+	"o.":      {393, 393},
+	"@f":      {396, 396},
+	"f@":      {398, 398},
+	"f#@":     {400, 400},
+	"inverse": {404, 404},
+	"under":   {407, 407},
+	"[K]":     {410, 410},
 }
@@ -5,9 +5,23 @@
 package parse
 
 import (
+	"slices"
 	"strings"
 )
 
+// HelpTopics returns the words )help accepts, sorted: the sections
+// of the documentation and the names of the operators.
+func HelpTopics() []string {
+	topics := []string{"about", "axis", "binary", "char", "constants", "intro", "ops", "special", "types", "unary"}
+	for _, m := range []map[string]helpIndexPair{helpUnary, helpBinary, helpAxis} {
+		for name := range m {
+			topics = append(topics, name)
+		}
+	}
+	slices.Sort(topics)
+	return slices.Compact(topics)
+}
+
 func (p *Parser) helpOverview() {
 	p.Println("Overview:")
 	p.Println("\t)help intro")
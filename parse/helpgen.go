@@ -199,6 +199,7 @@ func main() {
 	for i = 0; lines[i] != "Operators and axis indicator"; i++ {
 	}
 	s("var helpAxis = map[string]helpIndexPair{")
+	seen := make(map[string]bool) // Some ops, like /, have several entries; use the first.
 	for i++; i < len(lines); i++ {
 		line := lines[i]
 		if line == "Type-converting operations" {
@@ -219,9 +220,10 @@ func main() {
 				break
 			}
 		}
-		if len(op) == 0 {
+		if len(op) == 0 || seen[string(op)] {
 			continue
 		}
+		seen[string(op)] = true
 		fmt.Fprintf(buf, `%q: {%d, %d},`+"\n", string(op), i, i)
 	}
 	s("}")
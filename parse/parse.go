@@ -27,6 +27,8 @@ func tree(e interface{}) string {
 		return fmt.Sprintf("<float %s>", e)
 	case value.Complex:
 		return fmt.Sprintf("<complex %s>", e)
+	case value.Time:
+		return fmt.Sprintf("<time %s>", e)
 	case value.VectorExpr:
 		s := "<"
 		for i, x := range e {
@@ -39,12 +41,30 @@ func tree(e interface{}) string {
 		return s
 	case *value.VarExpr:
 		return fmt.Sprintf("<var %s>", e.Name)
+	case *value.ConstExpr:
+		return fmt.Sprintf("<const %s>", tree(e.Value))
 	case *value.UnaryExpr:
+		if e.Axis != nil {
+			return fmt.Sprintf("(%s[%s] %s)", e.Op, tree(e.Axis), tree(e.Right))
+		}
 		return fmt.Sprintf("(%s %s)", e.Op, tree(e.Right))
 	case *value.BinaryExpr:
+		if e.Axis != nil {
+			return fmt.Sprintf("(%s %s[%s] %s)", tree(e.Left), e.Op, tree(e.Axis), tree(e.Right))
+		}
 		return fmt.Sprintf("(%s %s %s)", tree(e.Left), e.Op, tree(e.Right))
 	case *value.CondExpr:
 		return tree(e.Cond)
+	case value.MatrixExpr:
+		s := "["
+		for i, x := range e {
+			if i > 0 {
+				s += "; "
+			}
+			s += tree(x)
+		}
+		s += "]"
+		return s
 	case *value.IndexExpr:
 		s := fmt.Sprintf("(%s[", tree(e.Left))
 		for i, v := range e.Right {
@@ -135,6 +155,20 @@ func (p *Parser) Loc() string {
 	return fmt.Sprintf("%s:%d: ", p.fileName, p.lineNum)
 }
 
+// ErrorMessage returns the text with which to report err: the input location
+// and message or, if the traceback debug flag is set, a multi-line traceback
+// showing the active user-defined ops.
+func (p *Parser) ErrorMessage(err value.Error) string {
+	if p.context.Config().Debug("traceback") == 0 {
+		return p.Loc() + err.Error()
+	}
+	if err.File == "" && p.fileName != "<stdin>" {
+		err.File = p.fileName
+		err.Line = p.lineNum
+	}
+	return err.Traceback()
+}
+
 func (p *Parser) errorf(format string, args ...interface{}) {
 	p.tokens = p.tokenBuf[:0]
 	value.Errorf(format, args...)
@@ -155,6 +189,9 @@ func (p *Parser) Line() ([]value.Expr, bool) {
 		return nil, false
 	}
 	tok := p.peek()
+	if tok.Type != scan.EOF && !p.atUndo() {
+		p.context.Snapshot()
+	}
 	switch tok.Type {
 	case scan.EOF:
 		return nil, true
@@ -173,9 +210,18 @@ func (p *Parser) Line() ([]value.Expr, bool) {
 	if !ok {
 		return nil, false
 	}
+	if p.context.Config().Check() {
+		p.check(exprs)
+	}
 	return exprs, true
 }
 
+// atUndo reports whether the line is the special command )undo,
+// which must not replace the state it is to restore.
+func (p *Parser) atUndo() bool {
+	return len(p.tokens) > 1 && p.tokens[0].Type == scan.RightParen && p.tokens[1].Text == "undo"
+}
+
 // readTokensToNewline returns the next line of input.
 // The boolean is false at EOF.
 // We read all tokens before parsing for easy error recovery
@@ -258,6 +304,7 @@ func (p *Parser) statementList() ([]value.Expr, bool) {
 //
 //	operand
 //	operand binop expr
+//	operand binop [ expr ] expr
 func (p *Parser) expr() value.Expr {
 	tok := p.next()
 	expr := p.operand(tok, true)
@@ -270,7 +317,16 @@ func (p *Parser) expr() value.Expr {
 			p.next()
 			return &value.BinaryExpr{
 				Left:  expr,
-				Op:    tok.Text,
+				Op:    p.under(tok.Text),
+				Axis:  p.axis(),
+				Right: p.expr(),
+			}
+		}
+		if op := p.inverseOp(0, true); op != "" {
+			p.skip(op)
+			return &value.BinaryExpr{
+				Left:  expr,
+				Op:    p.under(op),
 				Right: p.expr(),
 			}
 		}
@@ -286,7 +342,8 @@ func (p *Parser) expr() value.Expr {
 		p.next()
 		return &value.BinaryExpr{
 			Left:  expr,
-			Op:    tok.Text,
+			Op:    p.under(tok.Text),
+			Axis:  p.axis(),
 			Right: p.expr(),
 		}
 	}
@@ -301,6 +358,11 @@ func (p *Parser) checkAssign(e value.Expr) {
 		p.errorf("cannot assign to %s", e.ProgString())
 	case *value.VarExpr:
 		// ok
+	case *value.BinaryExpr:
+		// (mask sel x) = value.
+		if _, ok := e.Right.(*value.VarExpr); !ok || e.Op != "sel" {
+			p.errorf("cannot assign to %s", e.ProgString())
+		}
 	case *value.IndexExpr:
 		switch e.Left.(type) {
 		case *value.VarExpr:
@@ -330,20 +392,36 @@ func (p *Parser) checkAssign(e value.Expr) {
 //	char constant
 //	string constant
 //	vector
+//	matrix
 //	operand [ Expr ]...
 //	unop Expr
+//	unop [ Expr ] Expr
+//	"inverse" unop Expr
+//	unop "under" unop Expr
 func (p *Parser) operand(tok scan.Token, indexOK bool) value.Expr {
 	var expr value.Expr
 	switch tok.Type {
 	case scan.Operator:
 		expr = &value.UnaryExpr{
-			Op:    tok.Text,
+			Op:    p.under(tok.Text),
+			Axis:  p.axis(),
 			Right: p.expr(),
 		}
 	case scan.Identifier:
+		if tok.Text == "inverse" {
+			if op := p.inverseOp(-1, false); op != "" {
+				p.skip(op[len("inverse "):])
+				expr = &value.UnaryExpr{
+					Op:    p.under(op),
+					Right: p.expr(),
+				}
+				break
+			}
+		}
 		if p.context.DefinedUnary(strings.Trim(tok.Text, "@")) {
 			expr = &value.UnaryExpr{
-				Op:    tok.Text,
+				Op:    p.under(tok.Text),
+				Axis:  p.axis(),
 				Right: p.expr(),
 			}
 			break
@@ -351,6 +429,8 @@ func (p *Parser) operand(tok scan.Token, indexOK bool) value.Expr {
 		fallthrough
 	case scan.Number, scan.Rational, scan.Complex, scan.String, scan.LeftParen:
 		expr = p.numberOrVector(tok)
+	case scan.LeftBrack:
+		expr = p.matrix()
 	default:
 		p.errorf("unexpected %s", tok)
 	}
@@ -360,6 +440,145 @@ func (p *Parser) operand(tok scan.Token, indexOK bool) value.Expr {
 	return expr
 }
 
+// inverseOp returns the name of the operator, such as "inverse +\",
+// formed by the word "inverse" at index i of the remaining tokens and
+// the unary or binary operator after it, which may itself be an
+// inverse. It returns the empty string if there is no such operator.
+// An index of -1 stands for a leading "inverse" already consumed.
+func (p *Parser) inverseOp(i int, isBinary bool) string {
+	if i >= 0 && (i >= len(p.tokens) || p.tokens[i].Type != scan.Identifier || p.tokens[i].Text != "inverse") {
+		return ""
+	}
+	if i+1 >= len(p.tokens) {
+		return ""
+	}
+	tok := p.tokens[i+1]
+	switch tok.Type {
+	case scan.Operator:
+		return "inverse " + tok.Text
+	case scan.Identifier:
+		if op := p.inverseOp(i+1, isBinary); op != "" {
+			return "inverse " + op
+		}
+		if isBinary && p.context.DefinedBinary(tok.Text) || !isBinary && p.context.DefinedUnary(tok.Text) {
+			return "inverse " + tok.Text
+		}
+	}
+	return ""
+}
+
+// under returns the operator f extended by any "under g" clauses that
+// follow it, as in "floor under log", consuming them.
+func (p *Parser) under(f string) string {
+	for {
+		tok := p.peek()
+		if tok.Type != scan.Identifier || tok.Text != "under" {
+			return f
+		}
+		g := p.unaryOpAt(1)
+		if g == "" {
+			return f
+		}
+		p.next()
+		p.skip(g)
+		f += " under " + g
+	}
+}
+
+// unaryOpAt returns the unary operator, possibly an inverse, at index i
+// of the remaining tokens, or the empty string if there is none.
+func (p *Parser) unaryOpAt(i int) string {
+	if i >= len(p.tokens) {
+		return ""
+	}
+	switch tok := p.tokens[i]; tok.Type {
+	case scan.Operator:
+		return tok.Text
+	case scan.Identifier:
+		if op := p.inverseOp(i, false); op != "" {
+			return op
+		}
+		if p.context.DefinedUnary(strings.Trim(tok.Text, "@")) {
+			return tok.Text
+		}
+	}
+	return ""
+}
+
+// skip consumes the tokens that spell op.
+func (p *Parser) skip(op string) {
+	for range strings.Fields(op) {
+		p.next()
+	}
+}
+
+// axis
+//
+//	[ expr ]
+//
+// The axis specifier is optional; if absent, axis returns nil.
+func (p *Parser) axis() value.Expr {
+	if p.peek().Type != scan.LeftBrack || p.atMatrix() {
+		return nil
+	}
+	p.next()
+	expr := p.expr()
+	tok := p.next()
+	if tok.Type != scan.RightBrack {
+		p.errorf("expected right bracket in axis, found %s", tok)
+	}
+	return expr
+}
+
+// atMatrix reports whether the bracketed tokens that begin the
+// remaining input are a matrix literal rather than an axis. They are
+// if they hold a semicolon or nothing follows them in the expression,
+// so -[1 2; 3 4] and 1 + [1 2 3] are literals but +/[1] x has an axis.
+func (p *Parser) atMatrix() bool {
+	depth := 0
+	for i, tok := range p.tokens {
+		switch tok.Type {
+		case scan.LeftBrack, scan.LeftParen:
+			depth++
+		case scan.RightBrack, scan.RightParen:
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if i+1 == len(p.tokens) {
+				return true
+			}
+			switch p.tokens[i+1].Type {
+			case scan.RightParen, scan.RightBrack, scan.Semicolon, scan.Colon:
+				return true
+			}
+			return false
+		case scan.Semicolon:
+			if depth == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matrix
+//
+//	'[' expr [';' expr]... ']'
+func (p *Parser) matrix() value.Expr {
+	var rows value.MatrixExpr
+	for {
+		rows = append(rows, p.expr())
+		switch tok := p.next(); tok.Type {
+		case scan.RightBrack:
+			return rows
+		case scan.Semicolon:
+		default:
+			p.errorf("expected semicolon or right bracket in matrix, found %s", tok)
+		}
+	}
+}
+
 // index
 //
 //	expr
@@ -470,7 +689,7 @@ func (p *Parser) numberOrVector(tok scan.Token) value.Expr {
 			case scan.LeftParen:
 				fallthrough
 			case scan.Identifier:
-				if p.context.DefinedOp(tok.Text) {
+				if p.context.DefinedOp(tok.Text) || p.inverseOp(0, true) != "" {
 					break Loop
 				}
 				fallthrough
@@ -76,38 +76,13 @@ func save(c *exec.Context, file string) {
 	ibase, obase := conf.Base()
 	fmt.Fprintf(out, ")maxbits %d\n", conf.MaxBits())
 	fmt.Fprintf(out, ")maxdigits %d\n", conf.MaxDigits())
+	fmt.Fprintf(out, ")maxelems %d\n", conf.MaxElems())
 	fmt.Fprintf(out, ")origin %d\n", conf.Origin())
 	fmt.Fprintf(out, ")prompt %q\n", conf.Prompt())
 	fmt.Fprintf(out, ")format %q\n", conf.Format())
 	conf.SetBase(10, 10)
 
-	// Ops.
-	printed := make(map[exec.OpDef]bool)
-	for _, def := range c.Defs {
-		var fn *exec.Function
-		if def.IsBinary {
-			fn = c.BinaryFn[def.Name]
-		} else {
-			fn = c.UnaryFn[def.Name]
-		}
-		for _, ref := range references(c, fn.Body) {
-			if !printed[ref] {
-				if ref.IsBinary {
-					fmt.Fprintf(out, "op _ %s _\n", ref.Name)
-				} else {
-					fmt.Fprintf(out, "op %s _\n", ref.Name)
-				}
-				printed[ref] = true
-			}
-		}
-		printed[def] = true
-		s := fn.String()
-		if strings.Contains(s, "\n") {
-			// Multiline def must end in blank line.
-			s += "\n"
-		}
-		fmt.Fprintln(out, s)
-	}
+	saveOps(c, out)
 
 	// Global variables.
 	syms := c.Globals
@@ -131,6 +106,90 @@ func save(c *exec.Context, file string) {
 	conf.SetBase(ibase, obase)
 }
 
+// saveOps writes the definitions of the user-defined ops to out in the
+// order they were defined, adding forward declarations where needed.
+func saveOps(c *exec.Context, out io.Writer) {
+	printed := make(map[exec.OpDef]bool)
+	for _, def := range c.Defs {
+		fn := lookupOp(c, def)
+		forwardDecls(out, fn, printed)
+		printed[def] = true
+		saveOp(out, fn)
+		if inv := lookupInverse(c, def); inv != nil {
+			forwardDecls(out, inv, printed)
+			saveOp(out, inv)
+		}
+	}
+}
+
+// saveOp writes the definition of fn to out.
+func saveOp(out io.Writer, fn *exec.Function) {
+	s := fn.String()
+	if strings.Contains(s, "\n") {
+		// Multiline def must end in blank line.
+		s += "\n"
+	}
+	fmt.Fprintln(out, s)
+}
+
+// opSource returns the definition of fn, preceded by declarations of the ops
+// it refers to that were defined after it, so the text reads back as is.
+func opSource(c *exec.Context, fn *exec.Function) string {
+	self := exec.OpDef{Name: fn.Name, IsBinary: fn.IsBinary}
+	printed := make(map[exec.OpDef]bool)
+	for _, def := range c.Defs {
+		if def == self {
+			break
+		}
+		printed[def] = true
+	}
+	var b strings.Builder
+	forwardDecls(&b, fn, printed)
+	b.WriteString(fn.String())
+	if inv := lookupInverse(c, self); inv != nil {
+		printed[self] = true
+		b.WriteString("\n")
+		forwardDecls(&b, inv, printed)
+		b.WriteString(inv.String())
+	}
+	return b.String()
+}
+
+// lookupOp returns the user-defined op described by def.
+func lookupOp(c *exec.Context, def exec.OpDef) *exec.Function {
+	if def.IsBinary {
+		return c.BinaryFn[def.Name]
+	}
+	return c.UnaryFn[def.Name]
+}
+
+// lookupInverse returns the user-defined inverse of the op described by def,
+// or nil if it has none.
+func lookupInverse(c *exec.Context, def exec.OpDef) *exec.Function {
+	if def.IsBinary {
+		return c.BinaryInverse[def.Name]
+	}
+	return c.UnaryInverse[def.Name]
+}
+
+// forwardDecls writes to out a declaration of each op referenced by fn
+// that is not yet in printed, so fn's definition parses when read back.
+// A recursive reference to fn itself needs no declaration.
+func forwardDecls(out io.Writer, fn *exec.Function, printed map[exec.OpDef]bool) {
+	self := exec.OpDef{Name: fn.Name, IsBinary: fn.IsBinary}
+	for _, ref := range fn.Refs {
+		if ref == self || printed[ref] {
+			continue
+		}
+		if ref.IsBinary {
+			fmt.Fprintf(out, "op _x %s _y\n", ref.Name)
+		} else {
+			fmt.Fprintf(out, "op %s _\n", ref.Name)
+		}
+		printed[ref] = true
+	}
+}
+
 // saveSym holds a variable's name and value so we can sort them for saving.
 type saveSym struct {
 	name string
@@ -180,6 +239,8 @@ func put(conf *config.Config, out io.Writer, val value.Value, withParens bool) {
 		// Probably not important but it would be nice to fix it.
 		digits := int(float64(val.Prec()) * 0.301029995664) // 10 log 2.
 		fmt.Fprintf(out, "%.*g", digits+1, val.Float)       // Add another digit to be sure.
+	case value.Time:
+		fmt.Fprint(out, val.ProgString())
 	case value.Complex:
 		real, imag := val.Components()
 		put(conf, out, real, false)
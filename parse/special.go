@@ -24,6 +24,16 @@ import (
 
 const defaultFile = "save.ivy"
 
+// SpecialCommands lists the names of the special commands, for completion.
+var SpecialCommands = []string{
+	"base", "bench", "break", "check", "clear", "color", "copy", "cpu", "debug", "demo",
+	"display", "edit", "format", "get", "help", "history", "ibase", "ieee",
+	"load", "log", "maxbits", "maxdigits", "maxelems", "maxstack", "obase",
+	"op", "ops", "origin", "plot", "polar", "prec", "profile", "prompt",
+	"save", "seed", "step", "strict", "test", "timezone", "undo", "var",
+	"vars", "watch", "ws",
+}
+
 func (p *Parser) need(want ...scan.Type) scan.Token {
 	tok := p.next()
 	for _, w := range want {
@@ -89,6 +99,27 @@ func truth(x bool) int {
 	return 0
 }
 
+// sandboxed holds the special commands that reach beyond ivy itself,
+// to files, the clipboard, the terminal, or other programs, or that
+// change the limits on the resources a computation may use.
+var sandboxed = map[string]bool{
+	"copy":     true,
+	"debug":    true,
+	"demo":     true,
+	"edit":     true,
+	"get":      true,
+	"load":     true,
+	"log":      true,
+	"maxbits":  true,
+	"maxelems": true,
+	"maxstack": true,
+	"plot":     true,
+	"prec":     true,
+	"save":     true,
+	"test":     true,
+	"watch":    true,
+}
+
 func (p *Parser) special() {
 	p.need(scan.RightParen)
 	conf := p.context.Config()
@@ -100,10 +131,14 @@ func (p *Parser) special() {
 		conf.SetBase(ibase, obase)
 	}()
 	conf.SetBase(0, 0)
-Switch:
 	// Permit scan.Number in case we are in a high base (say 52) in which
 	// case text looks numeric.
-	switch text := p.need(scan.Identifier, scan.Number, scan.Op).Text; text {
+	text := p.need(scan.Identifier, scan.Number, scan.Op).Text
+	if conf.Sandbox() && sandboxed[text] {
+		p.errorf(")%s not allowed in sandbox", text)
+	}
+Switch:
+	switch text {
 	case "help":
 		p.Println("")
 		tok := p.peek()
@@ -164,7 +199,7 @@ Switch:
 			break Switch
 		}
 		base := p.nextDecimalNumber()
-		if base != 0 && (base < 2 || 16 < base) {
+		if base != 0 && (base < 2 || 36 < base) {
 			p.errorf("illegal base %d", base)
 		}
 		switch text {
@@ -175,6 +210,93 @@ Switch:
 		case "obase":
 			obase = base
 		}
+	case "bench":
+		text := p.getString()
+		n := 0
+		if p.peek().Type != scan.EOF {
+			n = p.nextDecimalNumber()
+		}
+		p.bench(text, n)
+	case "break":
+		if p.peek().Type == scan.EOF {
+			for _, name := range p.context.Breaks() {
+				p.Println("\t" + name)
+			}
+			break Switch
+		}
+		name := p.need(scan.Identifier).Text
+		if p.context.UnaryFn[name] == nil && p.context.BinaryFn[name] == nil {
+			p.errorf("%q not defined", name)
+		}
+		on := true
+		if p.peek().Type != scan.EOF {
+			on = p.nextDecimalNumber() != 0
+		}
+		p.context.SetBreak(name, on)
+	case "check":
+		if p.peek().Type == scan.EOF {
+			if conf.Check() {
+				p.Println("on")
+			} else {
+				p.Println("off")
+			}
+			break Switch
+		}
+		switch arg := p.need(scan.Identifier).Text; arg {
+		case "on":
+			conf.SetCheck(true)
+		case "off":
+			conf.SetCheck(false)
+		default:
+			p.errorf("usage: )check on|off")
+		}
+	case "clear":
+		var arg string
+		if p.peek().Type == scan.Identifier {
+			arg = p.next().Text
+		}
+		switch arg {
+		case "vars":
+			p.context.Clear(true, false)
+		case "ops":
+			p.context.Clear(false, true)
+		case "all":
+			p.context.Clear(true, true)
+		default:
+			p.errorf("usage: )clear vars|ops|all")
+		}
+		p.need(scan.EOF)
+	case "color":
+		if p.peek().Type == scan.EOF {
+			if conf.Color() {
+				p.Println("on")
+			} else {
+				p.Println("off")
+			}
+			break Switch
+		}
+		switch arg := p.need(scan.Identifier).Text; arg {
+		case "on":
+			conf.SetColor(true)
+		case "off":
+			conf.SetColor(false)
+		default:
+			p.errorf("usage: )color on|off")
+		}
+	case "copy":
+		p.need(scan.EOF)
+		clip := conf.Clipboard()
+		if clip == nil {
+			p.errorf("no clipboard available")
+		}
+		last := p.context.Global("_")
+		if last == nil {
+			p.errorf("no value to copy")
+		}
+		text := p.context.EvalUnary("text", last.Value()).Sprint(conf)
+		if err := clip.Copy(text); err != nil {
+			p.errorf("copy: %v", err)
+		}
 	case "cpu":
 		p.Printf("%s\n", conf.PrintCPUTime())
 	case "debug":
@@ -202,6 +324,63 @@ Switch:
 			}
 			conf.SetDebug(name, number)
 		}
+	case "display":
+		if p.peek().Type == scan.EOF {
+			p.Printf("rows\t%d\n", conf.MaxRows())
+			p.Printf("cols\t%d\n", conf.MaxCols())
+			align := conf.Align()
+			if len(align) == 0 {
+				align = []string{"right"}
+			}
+			p.Printf("align\t%s\n", strings.Join(align, " "))
+			p.Printf("sep\t%q\n", conf.ColumnSep())
+			if conf.Tree() {
+				p.Printf("tree\ton\n")
+			} else {
+				p.Printf("tree\toff\n")
+			}
+			if conf.Raw() {
+				p.Printf("raw\ton\n")
+			} else {
+				p.Printf("raw\toff\n")
+			}
+			break Switch
+		}
+		switch setting := p.need(scan.Identifier).Text; setting {
+		case "rows":
+			conf.SetMaxRows(p.nextDecimalNumber())
+		case "cols":
+			conf.SetMaxCols(p.nextDecimalNumber())
+		case "align":
+			var align []string
+			for p.peek().Type != scan.EOF {
+				switch a := p.need(scan.Identifier).Text; a {
+				case "left", "right", "center":
+					align = append(align, a)
+				default:
+					p.errorf("unknown alignment %q", a)
+				}
+			}
+			conf.SetAlign(align)
+		case "sep":
+			conf.SetColumnSep(p.getString())
+		case "tree", "raw":
+			var on bool
+			switch arg := p.need(scan.Identifier).Text; arg {
+			case "on":
+				on = true
+			case "off":
+			default:
+				p.errorf("usage: )display %s on|off", setting)
+			}
+			if setting == "tree" {
+				conf.SetTree(on)
+			} else {
+				conf.SetRaw(on)
+			}
+		default:
+			p.errorf("usage: )display rows|cols|align|sep|tree|raw value")
+		}
 	case "demo":
 		p.need(scan.EOF)
 		if conf.Mobile() {
@@ -215,6 +394,8 @@ Switch:
 			p.errorf("%v", err)
 		}
 		p.Println("Demo finished")
+	case "edit":
+		p.edit(p.need(scan.Operator, scan.Identifier).Text)
 	case "format":
 		if p.peek().Type == scan.EOF {
 			p.Printf("%q\n", conf.Format())
@@ -227,6 +408,62 @@ Switch:
 		} else {
 			p.runFromFile(p.context, p.getString())
 		}
+	case "history":
+		hist := conf.History()
+		start := 0
+		if p.peek().Type != scan.EOF {
+			n := p.nextDecimalNumber()
+			if n < len(hist) {
+				start = len(hist) - n
+			}
+		}
+		for i := start; i < len(hist); i++ {
+			p.Printf("%5d\t%s\n", i+1, hist[i])
+		}
+	case "ieee":
+		if p.peek().Type == scan.EOF {
+			if conf.IEEE() {
+				p.Println("on")
+			} else {
+				p.Println("off")
+			}
+			break Switch
+		}
+		switch arg := p.need(scan.Identifier).Text; arg {
+		case "on":
+			conf.SetIEEE(true)
+		case "off":
+			conf.SetIEEE(false)
+		default:
+			p.errorf("usage: )ieee on|off")
+		}
+	case "load":
+		file := defaultWorkspace
+		if p.peek().Type != scan.EOF {
+			file = p.getString()
+		}
+		p.loadWorkspace(file)
+		ibase, obase = conf.Base()
+	case "log":
+		if p.peek().Type == scan.EOF {
+			if conf.Log() == "" {
+				p.Println("off")
+			} else {
+				p.Printf("%q\n", conf.Log())
+			}
+			break Switch
+		}
+		if tok := p.peek(); tok.Type == scan.Identifier && tok.Text == "off" {
+			p.next()
+			conf.SetLog("", nil)
+			break Switch
+		}
+		name := p.getString()
+		fd, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+		if err != nil {
+			p.errorf("%s", err)
+		}
+		conf.SetLog(name, fd)
 	case "maxbits":
 		if p.peek().Type == scan.EOF {
 			p.Printf("%d\n", conf.MaxBits())
@@ -241,6 +478,13 @@ Switch:
 		}
 		max := p.nextDecimalNumber()
 		conf.SetMaxDigits(uint(max))
+	case "maxelems":
+		if p.peek().Type == scan.EOF {
+			p.Printf("%d\n", conf.MaxElems())
+			break Switch
+		}
+		max := p.nextDecimalNumber()
+		conf.SetMaxElems(uint(max))
 	case "maxstack":
 		if p.peek().Type == scan.EOF {
 			p.Printf("%d\n", conf.MaxStack())
@@ -278,12 +522,12 @@ Switch:
 		fn := p.context.UnaryFn[name]
 		found := false
 		if fn != nil {
-			p.Println(fn)
+			p.Println(opSource(p.context, fn))
 			found = true
 		}
 		fn = p.context.BinaryFn[name]
 		if fn != nil {
-			p.Println(fn)
+			p.Println(opSource(p.context, fn))
 			found = true
 		}
 		if !found {
@@ -300,6 +544,29 @@ Switch:
 			p.errorf("illegal origin %d", origin)
 		}
 		conf.SetOrigin(origin)
+	case "plot":
+		if p.peek().Type == scan.EOF {
+			p.Printf("%q\n", conf.PlotFile())
+			break Switch
+		}
+		conf.SetPlotFile(p.getString())
+	case "polar":
+		if p.peek().Type == scan.EOF {
+			if conf.Polar() {
+				p.Println("on")
+			} else {
+				p.Println("off")
+			}
+			break Switch
+		}
+		switch arg := p.need(scan.Identifier).Text; arg {
+		case "on":
+			conf.SetPolar(true)
+		case "off":
+			conf.SetPolar(false)
+		default:
+			p.errorf("usage: )polar on|off")
+		}
 	case "prec":
 		if p.peek().Type == scan.EOF {
 			p.Printf("%d\n", conf.FloatPrec())
@@ -310,6 +577,25 @@ Switch:
 			p.errorf("illegal prec %d", prec) // TODO: make 0 be disable?
 		}
 		conf.SetFloatPrec(uint(prec))
+	case "profile":
+		if p.peek().Type == scan.EOF {
+			if p.context.Profiling() {
+				p.Println("on")
+			} else {
+				p.Println("off")
+			}
+			break Switch
+		}
+		switch arg := p.need(scan.Identifier).Text; arg {
+		case "on":
+			p.context.SetProfile(true)
+		case "off":
+			p.context.SetProfile(false)
+		case "report":
+			p.context.ProfileReport(conf.Output())
+		default:
+			p.errorf("usage: )profile on|off|report")
+		}
 	case "prompt":
 		if p.peek().Type == scan.EOF {
 			p.Printf("%q\n", conf.Format())
@@ -319,6 +605,18 @@ Switch:
 	case "save":
 		// Must restore ibase, obase for save.
 		conf.SetBase(ibase, obase)
+		if tok := p.peek(); tok.Type == scan.Operator && tok.Text == "-" {
+			p.next()
+			if p.need(scan.Identifier).Text != "b" {
+				p.errorf("usage: )save -b \"file\"")
+			}
+			if p.peek().Type == scan.EOF {
+				saveWorkspace(p.context, defaultWorkspace)
+			} else {
+				saveWorkspace(p.context, p.getString())
+			}
+			break Switch
+		}
 		if p.peek().Type == scan.EOF {
 			save(p.context, defaultFile)
 		} else {
@@ -330,6 +628,24 @@ Switch:
 			break Switch
 		}
 		conf.SetRandomSeed(uint64(p.nextDecimalNumber64()))
+	case "step":
+		on := true
+		if p.peek().Type != scan.EOF {
+			on = p.nextDecimalNumber() != 0
+		}
+		p.context.SetStep(on)
+	case "strict":
+		if p.peek().Type == scan.EOF {
+			if conf.Strict() {
+				p.Println(1)
+			} else {
+				p.Println(0)
+			}
+			break Switch
+		}
+		conf.SetStrict(p.nextDecimalNumber() != 0)
+	case "test":
+		p.runTests(p.getString())
 	case "timezone":
 		if p.peek().Type == scan.EOF {
 			_, offset := time.Now().In(conf.Location()).Zone()
@@ -340,6 +656,42 @@ Switch:
 		if err != nil {
 			p.errorf("no such location: %s", err)
 		}
+	case "undo":
+		p.need(scan.EOF)
+		if !p.context.Undo() {
+			p.errorf("nothing to undo")
+		}
+	case "ws":
+		if p.peek().Type == scan.EOF {
+			for _, name := range p.context.Workspaces() {
+				p.Println(name)
+			}
+			break Switch
+		}
+		arg := p.need(scan.Identifier).Text
+		name := p.need(scan.Identifier).Text
+		p.need(scan.EOF)
+		switch arg {
+		case "save":
+			p.context.SaveWorkspace(name)
+		case "load":
+			if !p.context.LoadWorkspace(name) {
+				p.errorf("no workspace %s", name)
+			}
+		default:
+			p.errorf("usage: )ws save|load name")
+		}
+	case "watch":
+		if p.peek().Type == scan.EOF {
+			p.printWatched()
+			break Switch
+		}
+		if tok := p.peek(); tok.Type == scan.Identifier && tok.Text == "off" {
+			p.next()
+			p.unwatch()
+			break Switch
+		}
+		p.watch(p.getString())
 	case "var", "vars":
 		if p.peek().Type == scan.EOF {
 			var vars []string
@@ -402,7 +754,7 @@ func (p *Parser) runFromReader(context value.Context, name string, reader io.Rea
 			return
 		}
 		if err, ok := err.(value.Error); ok {
-			fmt.Fprintf(p.context.Config().ErrOutput(), "%s%s\n", p.Loc(), err)
+			fmt.Fprintln(p.context.Config().ErrOutput(), p.ErrorMessage(err))
 			return
 		}
 		panic(err)
@@ -428,7 +780,7 @@ func (p *Parser) runUntilError(name string) error {
 			return
 		}
 		if err, ok := err.(value.Error); ok {
-			fmt.Fprintf(p.context.Config().ErrOutput(), "%s%s\n", p.Loc(), err)
+			fmt.Fprintln(p.context.Config().ErrOutput(), p.ErrorMessage(err))
 			return
 		}
 		panic(err)
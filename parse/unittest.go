@@ -0,0 +1,46 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"robpike.io/ivy/scan"
+)
+
+// runTests implements )test: it evaluates the named file, which
+// typically holds expect operations, and prints a summary. Each failed
+// expect, like any other error, is reported with its position in the
+// file, and evaluation continues with the next line. If anything
+// failed, the summary is an error.
+func (p *Parser) runTests(name string) {
+	fd, err := os.Open(name)
+	if err != nil {
+		p.errorf("%s", err)
+	}
+	defer fd.Close()
+	conf := p.context.Config()
+	passed0, failed0 := conf.Expectations()
+	errors := 0
+	scanner := scan.New(p.context, name, bufio.NewReader(fd))
+	parser := NewParser(name, scanner, p.context)
+	for parser.runUntilError(name) != io.EOF {
+		errors++
+	}
+	passed, failed := conf.Expectations()
+	passed -= passed0
+	failed -= failed0
+	summary := fmt.Sprintf("%s: %d passed, %d failed", name, passed, failed)
+	if other := errors - failed; other > 0 {
+		summary += fmt.Sprintf(", %d other errors", other)
+	}
+	if errors > 0 {
+		p.errorf("%s", summary)
+	}
+	p.Println(summary)
+}
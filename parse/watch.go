@@ -0,0 +1,72 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import (
+	"os"
+	"slices"
+	"time"
+
+	"robpike.io/ivy/exec"
+)
+
+// A watchedFile is a file loaded by )watch.
+type watchedFile struct {
+	name    string
+	modTime time.Time // When the file was last modified as of loading.
+}
+
+// watched holds the files being watched in each context.
+var watched = make(map[*exec.Context][]*watchedFile)
+
+// watch implements )watch: it loads the named file and arranges for
+// ReloadWatched to load it again whenever it changes.
+func (p *Parser) watch(name string) {
+	info, err := os.Stat(name)
+	if err != nil {
+		p.errorf("%s", err)
+	}
+	files := watched[p.context]
+	i := slices.IndexFunc(files, func(f *watchedFile) bool { return f.name == name })
+	if i < 0 {
+		watched[p.context] = append(files, &watchedFile{name: name, modTime: info.ModTime()})
+	} else {
+		files[i].modTime = info.ModTime()
+	}
+	p.runFromFile(p.context, name)
+}
+
+// unwatch stops watching all files.
+func (p *Parser) unwatch() {
+	delete(watched, p.context)
+}
+
+// printWatched lists the files being watched.
+func (p *Parser) printWatched() {
+	for _, f := range watched[p.context] {
+		p.Printf("%q\n", f.name)
+	}
+}
+
+// ReloadWatched loads again each file named by )watch that has been
+// modified since it was last loaded. Errors in the file are reported
+// with their line numbers; a file that cannot be read is skipped, as
+// it may be in the middle of being saved.
+func (p *Parser) ReloadWatched() {
+	for _, f := range watched[p.context] {
+		info, err := os.Stat(f.name)
+		if err != nil || info.ModTime().Equal(f.modTime) {
+			continue
+		}
+		f.modTime = info.ModTime()
+		fd, err := os.Open(f.name)
+		if err != nil {
+			continue
+		}
+		p.Printf("reloading %s\n", f.name)
+		p.runFromReader(p.context, f.name, fd, true)
+		fd.Close()
+	}
+}
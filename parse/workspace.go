@@ -0,0 +1,261 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+// Saving and loading workspaces in a structured format.
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"robpike.io/ivy/exec"
+	"robpike.io/ivy/value"
+)
+
+/*
+Unlike the textual output of )save, a workspace file records every value
+exactly: floating-point numbers keep their own precision and are stored
+in hexadecimal so no bits are lost, and nested values need no delicate
+parenthesization. The file is JSON. Ops are still stored as source text,
+in the order written by )save, since their parse depends only on the ops
+defined before them.
+*/
+
+const (
+	defaultWorkspace = "save.ivyw"
+	workspaceVersion = 1
+)
+
+// workspace is the top-level structure of a workspace file.
+type workspace struct {
+	Version int                      `json:"version"`
+	Config  workspaceConfig          `json:"config"`
+	Ops     string                   `json:"ops"`
+	Vars    map[string]*workspaceVal `json:"vars"`
+}
+
+// workspaceConfig holds the configuration settings saved in a workspace.
+type workspaceConfig struct {
+	Prec      uint   `json:"prec"`
+	MaxBits   uint   `json:"maxbits"`
+	MaxDigits uint   `json:"maxdigits"`
+	MaxStack  uint   `json:"maxstack"`
+	Origin    int    `json:"origin"`
+	Prompt    string `json:"prompt"`
+	Format    string `json:"format"`
+	IBase     int    `json:"ibase"`
+	OBase     int    `json:"obase"`
+	Seed      uint64 `json:"seed"`
+	TimeZone  string `json:"timezone"`
+}
+
+// workspaceVal is the encoding of a value. Exactly one field is set.
+type workspaceVal struct {
+	Char    *rune            `json:"char,omitempty"`
+	Int     string           `json:"int,omitempty"`
+	Rat     string           `json:"rat,omitempty"`
+	Float   string           `json:"float,omitempty"`
+	Prec    uint             `json:"prec,omitempty"`
+	Complex []*workspaceVal  `json:"complex,omitempty"`
+	Time    string           `json:"time,omitempty"`
+	Vector  *[]*workspaceVal `json:"vector,omitempty"`
+	Shape   []int            `json:"shape,omitempty"`
+}
+
+// saveWorkspace writes the state of the workspace to the named file
+// as a structured workspace file.
+func saveWorkspace(c *exec.Context, file string) {
+	conf := c.Config()
+	ibase, obase := conf.Base()
+	ws := workspace{
+		Version: workspaceVersion,
+		Config: workspaceConfig{
+			Prec:      conf.FloatPrec(),
+			MaxBits:   conf.MaxBits(),
+			MaxDigits: conf.MaxDigits(),
+			MaxStack:  conf.MaxStack(),
+			Origin:    conf.Origin(),
+			Prompt:    conf.Prompt(),
+			Format:    conf.Format(),
+			IBase:     ibase,
+			OBase:     obase,
+			Seed:      conf.RandomSeed(),
+			TimeZone:  conf.TimeZone(),
+		},
+		Vars: make(map[string]*workspaceVal),
+	}
+	// Op definitions must be printed base 10.
+	var ops strings.Builder
+	conf.SetBase(10, 10)
+	saveOps(c, &ops)
+	conf.SetBase(ibase, obase)
+	ws.Ops = ops.String()
+	for name, v := range c.Globals {
+		ws.Vars[name] = encodeValue(v.Value())
+	}
+	data, err := json.MarshalIndent(&ws, "", "\t")
+	if err != nil {
+		value.Errorf("%s", err)
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0666); err != nil {
+		value.Errorf("%s", err)
+	}
+}
+
+// loadWorkspace restores the state saved in the named workspace file.
+// Definitions and variables are added to those already present.
+func (p *Parser) loadWorkspace(file string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		p.errorf("%s", err)
+	}
+	var ws workspace
+	if err := json.Unmarshal(data, &ws); err != nil {
+		p.errorf("%s: %s", file, err)
+	}
+	if ws.Version != workspaceVersion {
+		p.errorf("%s: unsupported workspace version %d", file, ws.Version)
+	}
+	conf := p.context.Config()
+	cf := ws.Config
+	if cf.Prec == 0 {
+		p.errorf("%s: illegal prec 0", file)
+	}
+	conf.SetFloatPrec(cf.Prec)
+	conf.SetMaxBits(cf.MaxBits)
+	conf.SetMaxDigits(cf.MaxDigits)
+	conf.SetMaxStack(cf.MaxStack)
+	conf.SetOrigin(cf.Origin)
+	conf.SetPrompt(cf.Prompt)
+	conf.SetFormat(cf.Format)
+	conf.SetRandomSeed(cf.Seed)
+	if cf.TimeZone != "" {
+		if err := conf.SetLocation(cf.TimeZone); err != nil {
+			p.errorf("%s: %s", file, err)
+		}
+	}
+	// The ops are source text and must be parsed base 10.
+	conf.SetBase(10, 10)
+	p.runFromReader(p.context, file, strings.NewReader(ws.Ops), true)
+	for name, v := range ws.Vars {
+		p.context.AssignGlobal(name, decodeValue(file, v))
+	}
+	conf.SetBase(cf.IBase, cf.OBase)
+}
+
+// encodeValue returns the workspace encoding of v.
+func encodeValue(v value.Value) *workspaceVal {
+	switch v := v.(type) {
+	case value.Char:
+		r := rune(v)
+		return &workspaceVal{Char: &r}
+	case value.Int:
+		return &workspaceVal{Int: fmt.Sprint(int(v))}
+	case value.BigInt:
+		return &workspaceVal{Int: v.Int.String()}
+	case value.BigRat:
+		return &workspaceVal{Rat: v.Rat.String()}
+	case value.BigFloat:
+		return &workspaceVal{Float: v.Float.Text('p', 0), Prec: v.Float.Prec()}
+	case value.Time:
+		return &workspaceVal{Time: v.Format(time.RFC3339Nano)}
+	case value.Complex:
+		real, imag := v.Components()
+		return &workspaceVal{Complex: []*workspaceVal{encodeValue(real), encodeValue(imag)}}
+	case *value.Vector:
+		// A pointer so an empty vector is distinct from a missing field.
+		elems := make([]*workspaceVal, v.Len())
+		for i, x := range v.All() {
+			elems[i] = encodeValue(x)
+		}
+		return &workspaceVal{Vector: &elems}
+	case *value.Matrix:
+		w := encodeValue(v.Data())
+		w.Shape = v.Shape()
+		return w
+	}
+	value.Errorf("internal error: can't save type %T", v)
+	panic("not reached")
+}
+
+// decodeValue returns the value represented by w, which was read from file.
+func decodeValue(file string, w *workspaceVal) value.Value {
+	bad := func() {
+		value.Errorf("%s: bad value in workspace", file)
+	}
+	switch {
+	case w == nil:
+		bad()
+	case w.Char != nil:
+		return value.Char(*w.Char)
+	case w.Int != "":
+		i, ok := new(big.Int).SetString(w.Int, 10)
+		if !ok {
+			bad()
+		}
+		// Small integers are always of type Int.
+		if i.IsInt64() && i.Int64() == int64(int32(i.Int64())) {
+			return value.Int(i.Int64())
+		}
+		return value.BigInt{Int: i}
+	case w.Rat != "":
+		r, ok := new(big.Rat).SetString(w.Rat)
+		if !ok {
+			bad()
+		}
+		return value.BigRat{Rat: r}
+	case w.Float != "":
+		f, _, err := big.ParseFloat(w.Float, 0, w.Prec, big.ToNearestEven)
+		if err != nil {
+			bad()
+		}
+		return value.BigFloat{Float: f}
+	case w.Complex != nil:
+		if len(w.Complex) != 2 {
+			bad()
+		}
+		return value.NewComplex(decodeValue(file, w.Complex[0]), decodeValue(file, w.Complex[1]))
+	case w.Time != "":
+		t, err := time.Parse(time.RFC3339Nano, w.Time)
+		if err != nil {
+			bad()
+		}
+		return value.Time{Time: t}
+	case w.Vector != nil:
+		elems := make([]value.Value, len(*w.Vector))
+		for i, x := range *w.Vector {
+			elems[i] = decodeValue(file, x)
+		}
+		vec := value.NewVector(elems...)
+		if w.Shape != nil {
+			// The shape must account for exactly the elements.
+			size := 1
+			for _, d := range w.Shape {
+				if d < 0 {
+					bad()
+				}
+				if d == 0 {
+					size = 0
+				} else if size != 0 {
+					if size > len(elems)/d {
+						bad()
+					}
+					size *= d
+				}
+			}
+			if size != len(elems) {
+				bad()
+			}
+			return value.NewMatrix(w.Shape, vec)
+		}
+		return vec
+	}
+	bad()
+	panic("not reached")
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package plot provides the standard plotting backends for ivy's
+// plot operator: SVG line plots and gnuplot scripts.
+package plot // import "robpike.io/ivy/plot"
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File is a config.Plotter that writes SVG to files ending .svg and
+// a gnuplot script to files ending .gp, .gnuplot, or .plt.
+type File struct{}
+
+// Plot writes the plot of ys against xs to the named file.
+func (File) Plot(file string, xs []float64, ys [][]float64) error {
+	var write func(io.Writer, []float64, [][]float64) error
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".svg":
+		write = SVG
+	case ".gp", ".gnuplot", ".plt":
+		write = Gnuplot
+	default:
+		return fmt.Errorf("unknown plot format for %q; use .svg or .gp", file)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	err = write(f, xs, ys)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Layout of the SVG image, in pixels.
+const (
+	width  = 640
+	height = 480
+	margin = 40
+)
+
+// colors are the stroke colors of successive series.
+var colors = []string{"#1f77b4", "#d62728", "#2ca02c", "#ff7f0e", "#9467bd", "#8c564b"}
+
+// SVG writes an SVG line plot of each of ys against xs, with axes
+// labeled by the extreme values.
+func SVG(w io.Writer, xs []float64, ys [][]float64) error {
+	xmin, xmax := bounds(xs)
+	ymin, ymax := math.Inf(1), math.Inf(-1)
+	for _, y := range ys {
+		lo, hi := bounds(y)
+		ymin, ymax = min(ymin, lo), max(ymax, hi)
+	}
+	sx := func(x float64) float64 {
+		return margin + (x-xmin)/(xmax-xmin)*(width-2*margin)
+	}
+	sy := func(y float64) float64 {
+		return height - margin - (y-ymin)/(ymax-ymin)*(height-2*margin)
+	}
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\">\n", width, height)
+	fmt.Fprintf(b, "<rect width=\"100%%\" height=\"100%%\" fill=\"white\"/>\n")
+	fmt.Fprintf(b, "<path d=\"M%d %d V%d H%d\" stroke=\"black\" fill=\"none\"/>\n", margin, margin, height-margin, width-margin)
+	label := func(x, y float64, anchor string, v float64) {
+		fmt.Fprintf(b, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"%s\" font-size=\"12\">%g</text>\n", x, y, anchor, v)
+	}
+	label(margin, height-margin+15, "middle", xmin)
+	label(width-margin, height-margin+15, "middle", xmax)
+	label(margin-4, height-margin, "end", ymin)
+	label(margin-4, margin+4, "end", ymax)
+	for i, y := range ys {
+		fmt.Fprintf(b, "<polyline stroke=\"%s\" fill=\"none\" points=\"", colors[i%len(colors)])
+		for j := range y {
+			if j > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(b, "%.2f,%.2f", sx(xs[j]), sy(y[j]))
+		}
+		fmt.Fprintf(b, "\"/>\n")
+	}
+	fmt.Fprintf(b, "</svg>\n")
+	return b.Flush()
+}
+
+// bounds returns the smallest and largest of the values, widened if
+// necessary so they differ.
+func bounds(vals []float64) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, v := range vals {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	if lo == hi {
+		lo, hi = lo-1, hi+1
+	}
+	return lo, hi
+}
+
+// Gnuplot writes a gnuplot script that plots each of ys against xs
+// as lines. The data is held in the script.
+func Gnuplot(w io.Writer, xs []float64, ys [][]float64) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "$data << EOD\n")
+	for j, x := range xs {
+		fmt.Fprintf(b, "%g", x)
+		for _, y := range ys {
+			fmt.Fprintf(b, " %g", y[j])
+		}
+		fmt.Fprintf(b, "\n")
+	}
+	fmt.Fprintf(b, "EOD\n")
+	fmt.Fprintf(b, "plot")
+	for i := range ys {
+		if i > 0 {
+			fmt.Fprintf(b, ",")
+		}
+		fmt.Fprintf(b, " $data using 1:%d with lines title \"%d\"", i+2, i+1)
+	}
+	fmt.Fprintf(b, "\n")
+	return b.Flush()
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGnuplot(t *testing.T) {
+	var b strings.Builder
+	err := Gnuplot(&b, []float64{1, 2, 3}, [][]float64{{3, 1, 2}, {0.5, 0, -1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `$data << EOD
+1 3 0.5
+2 1 0
+3 2 -1
+EOD
+plot $data using 1:2 with lines title "1", $data using 1:3 with lines title "2"
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	var b strings.Builder
+	err := SVG(&b, []float64{1, 2, 3}, [][]float64{{5, 5, 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A constant series is drawn across the middle.
+	const want = `points="40.00,240.00 320.00,240.00 600.00,240.00"`
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("missing %s in:\n%s", want, b.String())
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.svg", "a.gp"} {
+		file := filepath.Join(dir, name)
+		if err := (File{}).Plot(file, []float64{1, 2}, [][]float64{{1, 2}}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(file); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := (File{}).Plot(filepath.Join(dir, "a.png"), []float64{1}, [][]float64{{1}}); err == nil {
+		t.Error("no error for unknown format")
+	}
+}
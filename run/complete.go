@@ -0,0 +1,83 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package run
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"robpike.io/ivy/exec"
+	"robpike.io/ivy/parse"
+	"robpike.io/ivy/value"
+)
+
+// Complete returns the possible completions of the word that ends at
+// position pos, counted in runes, of the line: the special commands
+// after ")", the help topics after ")help", the user-defined ops after
+// ")op", and otherwise the names of operators and variables. It also
+// returns the position at which the word starts. The completions are
+// sorted.
+func Complete(context value.Context, line string, pos int) (start int, matches []string) {
+	text := []rune(line)
+	pos = min(max(pos, 0), len(text))
+	start = pos
+	for start > 0 && isIdentRune(text[start-1]) {
+		start--
+	}
+	prefix := string(text[start:pos])
+	before := strings.TrimSpace(string(text[:start]))
+	c, _ := context.(*exec.Context)
+	var names []string
+	switch {
+	case before == ")":
+		names = parse.SpecialCommands
+	case strings.HasPrefix(before, ")"):
+		switch strings.Join(strings.Fields(before[1:]), " ") {
+		case "help":
+			names = parse.HelpTopics()
+		case "op", "ops":
+			if c != nil {
+				names = userOps(c)
+			}
+		}
+	default:
+		for name := range value.UnaryOps {
+			names = append(names, name)
+		}
+		for name := range value.BinaryOps {
+			names = append(names, name)
+		}
+		if c != nil {
+			names = append(names, userOps(c)...)
+			for name := range c.Globals {
+				names = append(names, name)
+			}
+		}
+	}
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) && name != "" && isIdentRune([]rune(name)[0]) {
+			matches = append(matches, name)
+		}
+	}
+	slices.Sort(matches)
+	return start, slices.Compact(matches)
+}
+
+// userOps returns the names of the user-defined ops.
+func userOps(c *exec.Context) []string {
+	var names []string
+	for name := range c.UnaryFn {
+		names = append(names, name)
+	}
+	for name := range c.BinaryFn {
+		names = append(names, name)
+	}
+	return names
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,416 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package run
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"robpike.io/ivy/config"
+)
+
+// Editor reads lines from a terminal, with the keys familiar from
+// readline and Emacs for editing the line and recalling the history
+// recorded in the configuration:
+//
+//	Left, Right, ^B, ^F	move by character
+//	Esc-b, Esc-f		move by word
+//	Home, End, ^A, ^E	move to the start or end of the line
+//	Backspace, Delete, ^D	delete a character; ^D on an empty line is EOF
+//	^K, ^U, ^W		delete to the end, to the start, or the previous word
+//	^Y			insert the text most recently deleted
+//	Up, Down, ^P, ^N	step through the history
+//	^R			search the history; ^R again finds an older match
+//	Tab			complete the word before the cursor
+//	^C			abandon the line
+//
+// The terminal is in raw mode only while a line is being read.
+type Editor struct {
+	conf   *config.Config
+	term   *os.File // The terminal, or nil if there is none to put in raw mode.
+	in     *bufio.Reader
+	out    io.Writer
+	buf    []rune // The line being edited.
+	pos    int    // Position of the cursor in buf.
+	cursor int    // Column of the cursor on the screen, relative to the start of the line.
+	hist   int    // Index in the history of the line being shown; len(history) is the new line.
+	saved  []rune // The new line, while stepping through the history.
+	killed []rune // Most recently deleted text, for ^Y.
+
+	// Complete, if set, returns the completions of the word that ends at
+	// rune position pos of the line, and the position of its start.
+	Complete func(line string, pos int) (start int, matches []string)
+
+	// Highlight, if set, returns the line decorated with terminal color
+	// escapes. It is used when the configuration enables color.
+	Highlight func(line string) string
+
+	searching bool // Whether a history search is showing.
+}
+
+// NewEditor returns an Editor reading from the terminal in and echoing to
+// out, or nil if either is not a terminal.
+func NewEditor(conf *config.Config, in, out *os.File) *Editor {
+	if !isTerminal(in) || !isTerminal(out) {
+		return nil
+	}
+	e := newEditor(conf, in, out)
+	e.term = in
+	return e
+}
+
+func newEditor(conf *config.Config, in io.Reader, out io.Writer) *Editor {
+	return &Editor{
+		conf: conf,
+		in:   bufio.NewReader(in),
+		out:  out,
+	}
+}
+
+// ReadLine reads and returns a line, without its newline. The prompt, if
+// any, has already been printed. At end of input it returns io.EOF.
+func (e *Editor) ReadLine() (string, error) {
+	if e.term != nil {
+		restore, err := makeRaw(e.term)
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+	e.buf = e.buf[:0]
+	e.pos = 0
+	e.cursor = 0
+	e.hist = len(e.conf.History())
+	e.saved = nil
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(e.buf) > 0 {
+				err = nil
+			}
+			e.finish()
+			return string(e.buf), err
+		}
+		switch r {
+		case '\r', '\n':
+			e.finish()
+			return string(e.buf), nil
+		case ctrl('C'):
+			e.refresh(e.buf, len(e.buf))
+			io.WriteString(e.out, "^C")
+			e.buf = e.buf[:0]
+			e.finish()
+			return "", nil
+		case ctrl('D'):
+			if len(e.buf) == 0 {
+				e.finish()
+				return "", io.EOF
+			}
+			e.delete(e.pos, e.pos+1)
+		case ctrl('R'):
+			if !e.search() {
+				e.finish()
+				return string(e.buf), nil
+			}
+		case '\x1b':
+			e.escape()
+		default:
+			e.key(r)
+		}
+		e.refresh(e.buf, e.pos)
+	}
+}
+
+// ctrl returns the character typed by the key with the control key held.
+func ctrl(c rune) rune {
+	return c & 0x1f
+}
+
+// key performs the editing action of a key that is not part of an escape sequence.
+func (e *Editor) key(r rune) {
+	switch r {
+	case ctrl('A'):
+		e.pos = 0
+	case ctrl('E'):
+		e.pos = len(e.buf)
+	case ctrl('B'):
+		e.pos = max(e.pos-1, 0)
+	case ctrl('F'):
+		e.pos = min(e.pos+1, len(e.buf))
+	case ctrl('H'), 0x7f:
+		e.delete(e.pos-1, e.pos)
+	case ctrl('K'):
+		e.kill(e.pos, len(e.buf))
+	case ctrl('U'):
+		e.kill(0, e.pos)
+	case ctrl('W'):
+		e.kill(e.wordLeft(), e.pos)
+	case ctrl('Y'):
+		e.insert(e.killed...)
+	case ctrl('P'):
+		e.history(-1)
+	case ctrl('N'):
+		e.history(1)
+	case '\t':
+		if e.Complete == nil {
+			e.insert(r)
+			break
+		}
+		e.complete()
+	default:
+		if unicode.IsPrint(r) {
+			e.insert(r)
+		}
+	}
+}
+
+// escape reads and acts on the rest of an escape sequence.
+func (e *Editor) escape() {
+	r, _, err := e.in.ReadRune()
+	if err != nil {
+		return
+	}
+	switch r {
+	case 'b':
+		e.pos = e.wordLeft()
+		return
+	case 'f':
+		e.pos = e.wordRight()
+		return
+	case '[', 'O':
+	default:
+		return
+	}
+	// A control sequence: parameters, then a final character.
+	var params []rune
+	for {
+		r, _, err = e.in.ReadRune()
+		if err != nil {
+			return
+		}
+		if r >= 0x40 && r <= 0x7e {
+			break
+		}
+		params = append(params, r)
+	}
+	word := strings.HasSuffix(string(params), ";5") // Control-arrow.
+	switch {
+	case r == 'A':
+		e.history(-1)
+	case r == 'B':
+		e.history(1)
+	case r == 'C' && word:
+		e.pos = e.wordRight()
+	case r == 'C':
+		e.key(ctrl('F'))
+	case r == 'D' && word:
+		e.pos = e.wordLeft()
+	case r == 'D':
+		e.key(ctrl('B'))
+	case r == 'H', r == '~' && (string(params) == "1" || string(params) == "7"):
+		e.pos = 0
+	case r == 'F', r == '~' && (string(params) == "4" || string(params) == "8"):
+		e.pos = len(e.buf)
+	case r == '~' && string(params) == "3":
+		e.delete(e.pos, e.pos+1)
+	}
+}
+
+// insert inserts the text at the cursor.
+func (e *Editor) insert(text ...rune) {
+	e.buf = append(e.buf[:e.pos], append(text, e.buf[e.pos:]...)...)
+	e.pos += len(text)
+}
+
+// delete deletes the text between start and end, within the line.
+func (e *Editor) delete(start, end int) {
+	start = max(start, 0)
+	end = min(end, len(e.buf))
+	if start >= end {
+		return
+	}
+	e.buf = append(e.buf[:start], e.buf[end:]...)
+	e.pos = start
+}
+
+// kill deletes the text between start and end, saving it for ^Y.
+func (e *Editor) kill(start, end int) {
+	if start < end {
+		e.killed = append([]rune(nil), e.buf[start:end]...)
+	}
+	e.delete(start, end)
+}
+
+// wordLeft returns the position of the start of the word before the cursor.
+func (e *Editor) wordLeft() int {
+	i := e.pos
+	for i > 0 && unicode.IsSpace(e.buf[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(e.buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordRight returns the position of the end of the word after the cursor.
+func (e *Editor) wordRight() int {
+	i := e.pos
+	for i < len(e.buf) && unicode.IsSpace(e.buf[i]) {
+		i++
+	}
+	for i < len(e.buf) && !unicode.IsSpace(e.buf[i]) {
+		i++
+	}
+	return i
+}
+
+// history replaces the line with the one delta steps away in the history.
+func (e *Editor) history(delta int) {
+	hist := e.conf.History()
+	i := e.hist + delta
+	if i < 0 || i > len(hist) {
+		return
+	}
+	if e.hist == len(hist) {
+		e.saved = append([]rune(nil), e.buf...)
+	}
+	e.hist = i
+	if i == len(hist) {
+		e.buf = append(e.buf[:0], e.saved...)
+	} else {
+		e.buf = append(e.buf[:0], []rune(hist[i])...)
+	}
+	e.pos = len(e.buf)
+}
+
+// complete completes the word before the cursor as far as the
+// completions agree. If that adds nothing, it lists the completions
+// and shows the line again on a fresh prompt.
+func (e *Editor) complete() {
+	start, matches := e.Complete(string(e.buf), e.pos)
+	if len(matches) == 0 {
+		return
+	}
+	common := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	if len(matches) == 1 && e.pos == len(e.buf) {
+		common += " "
+	}
+	if word := string(e.buf[start:e.pos]); common != word {
+		e.delete(start, e.pos)
+		e.insert([]rune(common)...)
+		return
+	}
+	if len(matches) > 1 {
+		e.refresh(e.buf, len(e.buf))
+		fmt.Fprintf(e.out, "\r\n%s\r\n%s", strings.Join(matches, "  "), e.conf.Prompt())
+		e.cursor = 0
+	}
+}
+
+// search runs an incremental search of the history, older lines first,
+// for lines containing the text typed. Enter accepts the line found and
+// ^G restores the original; any other key leaves the line found for
+// editing and then takes effect. Search reports whether editing should
+// continue, which is false if Enter was typed.
+func (e *Editor) search() bool {
+	e.searching = true
+	defer func() { e.searching = false }()
+	hist := e.conf.History()
+	var query []rune
+	found := len(hist) // Index of the matching line; len(hist) if none.
+	find := func(from int) {
+		for i := min(from, len(hist)-1); i >= 0; i-- {
+			if strings.Contains(hist[i], string(query)) {
+				found = i
+				return
+			}
+		}
+	}
+	for {
+		match := ""
+		if found < len(hist) {
+			match = hist[found]
+		}
+		prompt := []rune(fmt.Sprintf("(search)'%s': ", string(query)))
+		at := strings.Index(match, string(query))
+		e.refresh(append(prompt, []rune(match)...), len(prompt)+len([]rune(match[:max(at, 0)])))
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return true
+		}
+		switch r {
+		case ctrl('R'):
+			find(found - 1)
+			continue
+		case ctrl('G'):
+			return true
+		case ctrl('H'), 0x7f:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				found = len(hist)
+				find(len(hist) - 1)
+			}
+			continue
+		}
+		if unicode.IsPrint(r) {
+			query = append(query, r)
+			find(found)
+			continue
+		}
+		if found < len(hist) {
+			e.hist = found
+			e.buf = append(e.buf[:0], []rune(match)...)
+			e.pos = len(e.buf)
+		}
+		switch r {
+		case '\r', '\n':
+			e.refresh(e.buf, e.pos)
+			return false
+		case '\x1b':
+			e.escape()
+		default:
+			e.key(r)
+		}
+		return true
+	}
+}
+
+// refresh redraws the line as text with the cursor at pos, assuming
+// the line fits within the width of the terminal.
+func (e *Editor) refresh(text []rune, pos int) {
+	var b bytes.Buffer
+	if e.cursor > 0 {
+		fmt.Fprintf(&b, "\x1b[%dD", e.cursor)
+	}
+	if e.Highlight != nil && e.conf.Color() && !e.searching {
+		b.WriteString(e.Highlight(string(text)))
+	} else {
+		b.WriteString(string(text))
+	}
+	b.WriteString("\x1b[K")
+	if n := len(text) - pos; n > 0 {
+		fmt.Fprintf(&b, "\x1b[%dD", n)
+	}
+	e.cursor = pos
+	e.out.Write(b.Bytes())
+}
+
+// finish shows the whole line and moves to the next.
+func (e *Editor) finish() {
+	e.refresh(e.buf, len(e.buf))
+	io.WriteString(e.out, "\r\n")
+	e.cursor = 0
+}
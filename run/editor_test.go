@@ -0,0 +1,96 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package run
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/exec"
+)
+
+var editorTests = []struct {
+	name  string
+	keys  string
+	lines []string
+}{
+	{"plain", "1+2\r", []string{"1+2"}},
+	{"left", "12\x1b[D3\r", []string{"132"}},
+	{"home end", "bc\x01a\x05d\r", []string{"abcd"}},
+	{"backspace", "abx\x7fc\r", []string{"abc"}},
+	{"delete", "abxc\x02\x02\x1b[3~\r", []string{"abc"}},
+	{"kill yank", "iota 3\x01\x0b\x19\x19\r", []string{"iota 3iota 3"}},
+	{"kill word", "rho iota 3\x17\x17x\r", []string{"rho x"}},
+	{"word moves", "a bb c\x1bbX\x1bb\x1bbY\x1bfZ\r", []string{"a YbbZ Xc"}},
+	{"history", "1\r2\r\x1b[A\x1b[A\x1b[B3\r", []string{"1", "2", "23"}},
+	{"history new line", "1\rx\x10\x0e\r", []string{"1", "x"}},
+	{"interrupt", "abc\x03def\r", []string{"", "def"}},
+	{"eof", "\x04", nil},
+	{"search", "iota 4\rrho 3\r1\r\x12ot\r", []string{"iota 4", "rho 3", "1", "iota 4"}},
+	{"search again", "a1\rb\ra2\r\x12a\x12\x05x\r", []string{"a1", "b", "a2", "a1x"}},
+	{"search cancel", "x1\rab\x12x\x07\r", []string{"x1", "ab"}},
+}
+
+func TestEditor(t *testing.T) {
+	for _, test := range editorTests {
+		var conf config.Config
+		var out bytes.Buffer
+		e := newEditor(&conf, strings.NewReader(test.keys), &out)
+		var lines []string
+		for {
+			line, err := e.ReadLine()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			lines = append(lines, line)
+			conf.AddHistory(line)
+		}
+		if strings.Join(lines, "\n") != strings.Join(test.lines, "\n") {
+			t.Errorf("%s: got %q; want %q", test.name, lines, test.lines)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	var conf config.Config
+	context := exec.NewContext(&conf)
+	IvyEval(context, "op sigma x = +/x")
+	IvyEval(context, "sigmoid = 1")
+	tests := []struct {
+		line    string
+		start   int
+		matches []string
+	}{
+		{"io", 0, []string{"iota"}},
+		{"1 + sig", 4, []string{"sigma", "sigmoid"}},
+		{")ma", 1, []string{"maxbits", "maxdigits", "maxelems", "maxstack"}},
+		{") help rh", 7, []string{"rho"}},
+		{")op s", 4, []string{"sigma"}},
+		{")prec io", 6, nil},
+	}
+	for _, test := range tests {
+		start, matches := Complete(context, test.line, len(test.line))
+		if start != test.start || !slices.Equal(matches, test.matches) {
+			t.Errorf("%q: got %d %q; want %d %q", test.line, start, matches, test.start, test.matches)
+		}
+	}
+	// Tab in the editor.
+	e := newEditor(&conf, strings.NewReader("1 + io\t3\rsig\ta\t\r"), io.Discard)
+	e.Complete = func(line string, pos int) (int, []string) {
+		return Complete(context, line, pos)
+	}
+	for _, want := range []string{"1 + iota 3", "sigma "} {
+		if got, _ := e.ReadLine(); got != want {
+			t.Errorf("tab: got %q; want %q", got, want)
+		}
+	}
+}
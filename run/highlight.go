@@ -0,0 +1,119 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package run
+
+import (
+	"strings"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/exec"
+	"robpike.io/ivy/scan"
+	"robpike.io/ivy/value"
+)
+
+// ANSI terminal escapes for the colors used with )color on.
+const (
+	colorReset      = "\x1b[0m"
+	colorNumber     = "\x1b[36m" // Cyan.
+	colorString     = "\x1b[32m" // Green.
+	colorOperator   = "\x1b[33m" // Yellow.
+	colorKeyword    = "\x1b[35m" // Magenta.
+	colorComment    = "\x1b[90m" // Gray.
+	colorError      = "\x1b[31m" // Red.
+	colorUnbalanced = "\x1b[41m" // Red background.
+)
+
+// highlightContext is a Context whose configuration is replaced, so
+// that scanning for highlighting does not disturb the real one, for
+// instance by writing to the log.
+type highlightContext struct {
+	value.Context
+	conf *config.Config
+}
+
+func (c highlightContext) Config() *config.Config {
+	return c.conf
+}
+
+// Highlight returns the line of input with ANSI color escapes added
+// to distinguish numbers, strings, operators, keywords and comments,
+// and to flag parentheses and brackets that do not balance. The
+// tokens are those of the scanner, so the operators are the ones
+// defined in the context. Only the escapes are added; stripped of
+// them, the result is the line.
+func Highlight(context value.Context, line string) string {
+	conf := new(config.Config)
+	conf.SetBase(context.Config().Base())
+	scanner := scan.New(highlightContext{context, conf}, "<highlight>", strings.NewReader(line+"\n"))
+	colors := make([]string, len(line)) // Color of each byte of the line.
+	var open []int                      // Offsets of unmatched ( and [.
+	command := strings.HasPrefix(strings.TrimSpace(line), ")")
+	for pos := 0; pos < len(line); {
+		tok := scanner.Next()
+		end := scanner.Offset()
+		if tok.Type == scan.EOF || tok.Type == scan.Error || end <= pos {
+			break // Leave the rest plain.
+		}
+		start := max(end-len(tok.Text), pos)
+		end = min(end, len(line))
+		color := ""
+		switch tok.Type {
+		case scan.Number, scan.Rational, scan.Complex:
+			color = colorNumber
+		case scan.String:
+			color = colorString
+		case scan.Operator:
+			color = colorOperator
+		case scan.Identifier:
+			if exec.Predefined(tok.Text) || context.UserDefined(tok.Text, false) || context.UserDefined(tok.Text, true) {
+				color = colorOperator
+			}
+		case scan.Op, scan.OpDelete:
+			color = colorKeyword
+		case scan.Newline:
+			if strings.Contains(tok.Text, "#") {
+				color = colorComment
+			}
+		case scan.LeftParen, scan.LeftBrack:
+			open = append(open, start)
+		case scan.RightParen, scan.RightBrack:
+			match := byte('(')
+			if tok.Type == scan.RightBrack {
+				match = '['
+			}
+			switch {
+			case command && pos == 0:
+				color = colorKeyword // The ) of a special command.
+			case len(open) > 0 && line[open[len(open)-1]] == match:
+				open = open[:len(open)-1]
+			default:
+				color = colorUnbalanced
+			}
+		}
+		for i := start; i < end; i++ {
+			colors[i] = color
+		}
+		pos = end
+	}
+	for _, i := range open {
+		colors[i] = colorUnbalanced
+	}
+	var b strings.Builder
+	current := ""
+	for i := range len(line) {
+		if colors[i] != current {
+			if current != "" {
+				b.WriteString(colorReset)
+			}
+			b.WriteString(colors[i])
+			current = colors[i]
+		}
+		b.WriteByte(line[i])
+	}
+	if current != "" {
+		b.WriteString(colorReset)
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package run
+
+import (
+	"strings"
+	"testing"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/exec"
+)
+
+func TestHighlight(t *testing.T) {
+	var conf config.Config
+	context := exec.NewContext(&conf)
+	IvyEval(context, "op double x = 2*x")
+	// Colors are shown as letters: N number, S string, O operator,
+	// K keyword, C comment, U unbalanced.
+	names := strings.NewReplacer(
+		colorNumber, "<N>", colorString, "<S>", colorOperator, "<O>",
+		colorKeyword, "<K>", colorComment, "<C>", colorUnbalanced, "<U>",
+		colorReset, "</>")
+	strip := strings.NewReplacer(
+		colorNumber, "", colorString, "", colorOperator, "",
+		colorKeyword, "", colorComment, "", colorUnbalanced, "",
+		colorReset, "")
+	tests := []struct {
+		line, want string
+	}{
+		{"1 + 2/3", "<N>1</> <O>+</> <N>2/3</>"},
+		{"'ab' , x", "<S>'ab'</> <O>,</> x"},
+		{"double iota 3 # comment", "<O>double</> <O>iota</> <N>3</> <C># comment</>"},
+		{"op f x = x", "<K>op</> f x = x"},
+		{"(1 + (2)", "<U>(</><N>1</> <O>+</> (<N>2</>)"},
+		{"x[1]]", "x[<N>1</>]<U>]</>"},
+		{"(x]", "<U>(</>x<U>]</>"},
+		{")help rho", "<K>)</>help <O>rho</>"},
+		{"'unterminated", "'unterminated"},
+	}
+	for _, test := range tests {
+		got := Highlight(context, test.line)
+		if names.Replace(got) != test.want {
+			t.Errorf("%q: got %q; want %q", test.line, names.Replace(got), test.want)
+		}
+		if plain := strip.Replace(got); plain != test.line {
+			t.Errorf("%q: highlighting changed the text to %q", test.line, plain)
+		}
+	}
+}
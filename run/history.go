@@ -0,0 +1,209 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package run
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"robpike.io/ivy/config"
+)
+
+// History is an io.ByteReader that records lines of interactive input
+// in the configuration, where the )history command can find them.
+// History is kept only when input comes from the line editor, that is,
+// from a terminal; otherwise the input is delivered unchanged, so
+// programs piped to ivy behave as they would from a file.
+// As each line is read, the history references !! (the previous line)
+// and !n (line n) are replaced by the text of the line they identify.
+// A reference to a line that does not exist is left alone, so !3 with
+// fewer than three lines of history is still the factorial of 3.
+// If a file is provided, history is loaded from it at startup and
+// each new line is appended to it, so history persists across sessions.
+// The file is trimmed to its last maxHistoryFile lines when it is
+// loaded and when it grows to twice that, so appending stays cheap.
+type History struct {
+	conf  *config.Config
+	r     *bufio.Reader
+	file  string
+	lines int     // Number of lines in the file.
+	edit  *Editor // If non-nil, lines are read from the Editor rather than r.
+	hook  func()  // If non-nil, called as each line is read.
+	line  string  // Remainder of the current line, not yet delivered.
+	err   error
+}
+
+// maxHistoryFile is the maximum number of lines kept in the history file.
+const maxHistoryFile = 1000
+
+// NewHistory returns a History reading from the line editor, or from r
+// if the editor is nil, in which case no history is kept. If file is
+// non-empty, the lines it contains are loaded into the history and new
+// lines are appended to it as they are read. A missing file is not an
+// error.
+func NewHistory(conf *config.Config, r io.Reader, edit *Editor, file string) *History {
+	h := &History{
+		conf: conf,
+		r:    bufio.NewReader(r),
+		edit: edit,
+	}
+	if file == "" || edit == nil {
+		return h
+	}
+	h.file = file
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Fprintf(conf.ErrOutput(), "history: %v\n", err)
+		}
+		return h
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) != "" {
+			conf.AddHistory(line)
+			h.lines++
+		}
+	}
+	if h.lines > maxHistoryFile {
+		h.trim()
+	}
+	return h
+}
+
+// SetOnLine sets a function to be called after each line is read and
+// before it is delivered, such as to reload files that have changed
+// so the line sees their latest contents.
+func (h *History) SetOnLine(f func()) {
+	h.hook = f
+}
+
+// ReadByte implements io.ByteReader.
+func (h *History) ReadByte() (byte, error) {
+	for h.line == "" {
+		if h.err != nil {
+			return 0, h.err
+		}
+		h.readLine()
+	}
+	c := h.line[0]
+	h.line = h.line[1:]
+	return c, nil
+}
+
+// readLine reads the next line of input, expands any history reference,
+// and records the result in the history and the log.
+func (h *History) readLine() {
+	var line string
+	var err error
+	if h.edit != nil {
+		line, err = h.edit.ReadLine()
+		if err == nil {
+			line += "\n"
+		}
+	} else {
+		line, err = h.r.ReadString('\n')
+	}
+	h.err = err
+	if line == "" {
+		return
+	}
+	if h.hook != nil {
+		h.hook()
+	}
+	if h.edit == nil {
+		h.line = line
+		h.conf.LogInput(line)
+		return
+	}
+	text := strings.TrimRight(line, "\r\n")
+	expanded := h.expand(text)
+	if expanded != text {
+		// Show the user what is being executed.
+		fmt.Fprintln(h.conf.UnloggedOutput(), expanded)
+	}
+	h.record(expanded)
+	h.line = expanded + "\n"
+	h.conf.LogInput(h.line)
+}
+
+// expand returns the line with a history reference replaced by the line
+// it refers to. Only a reference that constitutes the entire line and
+// identifies an existing line is expanded; otherwise the text is
+// returned unchanged.
+func (h *History) expand(text string) string {
+	ref := strings.TrimSpace(text)
+	if len(ref) < 2 || ref[0] != '!' {
+		return text
+	}
+	hist := h.conf.History()
+	if ref == "!!" {
+		if len(hist) == 0 {
+			return text
+		}
+		return hist[len(hist)-1]
+	}
+	n, err := strconv.Atoi(ref[1:])
+	if err != nil || n < 1 || len(hist) < n {
+		return text
+	}
+	return hist[n-1]
+}
+
+// record saves the line in the history, ignoring blank lines and
+// appending to the history file if there is one.
+func (h *History) record(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	h.conf.AddHistory(line)
+	if h.file == "" {
+		return
+	}
+	f, err := os.OpenFile(h.file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		h.fileError(err)
+		return
+	}
+	fmt.Fprintln(f, line)
+	f.Close()
+	h.lines++
+	if h.lines >= 2*maxHistoryFile {
+		h.trim()
+	}
+}
+
+// trim cuts the history file down to its last maxHistoryFile lines.
+// It reads the file afresh rather than writing the history held in
+// memory, so it keeps lines appended by other sessions.
+func (h *History) trim() {
+	data, err := os.ReadFile(h.file)
+	if err != nil {
+		h.fileError(err)
+		return
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	lines = lines[max(0, len(lines)-maxHistoryFile):]
+	if err := os.WriteFile(h.file, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		h.fileError(err)
+		return
+	}
+	h.lines = len(lines)
+}
+
+// fileError reports a failure to write the history file and stops
+// further writes, so the error is not reported again.
+func (h *History) fileError(err error) {
+	fmt.Fprintf(h.conf.ErrOutput(), "history: %v\n", err)
+	h.file = ""
+}
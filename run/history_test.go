@@ -0,0 +1,202 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package run
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/exec"
+	"robpike.io/ivy/parse"
+	"robpike.io/ivy/scan"
+)
+
+func readAll(t *testing.T, h *History) string {
+	t.Helper()
+	var buf bytes.Buffer
+	for {
+		c, err := h.ReadByte()
+		if err == io.EOF {
+			return buf.String()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.WriteByte(c)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(file, []byte("1+1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var conf config.Config
+	var stdout, stderr bytes.Buffer
+	conf.SetOutput(&stdout)
+	conf.SetErrOutput(&stderr)
+	keys := "2*3\r\r!!\r!1\r! 5\r!9\r"
+	h := NewHistory(&conf, nil, newEditor(&conf, strings.NewReader(keys), io.Discard), file)
+	got := readAll(t, h)
+	want := "2*3\n\n2*3\n1+1\n! 5\n!9\n"
+	if got != want {
+		t.Errorf("input: got %q; want %q", got, want)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("unexpected error output %q", stderr.String())
+	}
+	hist := strings.Join(conf.History(), "|")
+	if hist != "1+1|2*3|2*3|1+1|! 5|!9" {
+		t.Errorf("history: got %q", hist)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "1+1\n2*3\n2*3\n1+1\n! 5\n!9\n" {
+		t.Errorf("history file: got %q", data)
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history")
+	var old strings.Builder
+	for i := range maxHistoryFile + 10 {
+		fmt.Fprintln(&old, i)
+	}
+	if err := os.WriteFile(file, []byte(old.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var conf config.Config
+	h := NewHistory(&conf, nil, newEditor(&conf, strings.NewReader("x\r"), io.Discard), file)
+	readAll(t, h)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != maxHistoryFile+1 {
+		t.Fatalf("history file has %d lines; want %d", len(lines), maxHistoryFile+1)
+	}
+	if lines[0] != "10" || lines[len(lines)-1] != "x" {
+		t.Errorf("history file holds %q to %q; want \"10\" to \"x\"", lines[0], lines[len(lines)-1])
+	}
+}
+
+// The history file is trimmed only when it has grown to twice the
+// limit, and the trimming keeps lines written by other sessions.
+func TestHistoryTrim(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(file, []byte(strings.Repeat("old\n", maxHistoryFile)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var conf config.Config
+	keys := strings.Repeat("x\r", maxHistoryFile)
+	h := NewHistory(&conf, nil, newEditor(&conf, strings.NewReader(keys), io.Discard), file)
+	n := 0
+	h.SetOnLine(func() {
+		n++
+		if n == maxHistoryFile {
+			// Another session appends a line.
+			f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fmt.Fprintln(f, "other")
+			f.Close()
+		}
+	})
+	readAll(t, h)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != maxHistoryFile {
+		t.Fatalf("history file has %d lines; want %d", len(lines), maxHistoryFile)
+	}
+	if lines[0] != "x" || lines[len(lines)-2] != "other" || lines[len(lines)-1] != "x" {
+		t.Errorf("history file ends %q; want \"other\", \"x\"", lines[len(lines)-2:])
+	}
+}
+
+// Input that is not from a terminal keeps no history, so !3 is
+// the factorial of 3 even when there is a line 3 in the history file.
+func TestHistoryNotTerminal(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(file, []byte("1\n2\n3\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var conf config.Config
+	var stdout, stderr bytes.Buffer
+	conf.SetOutput(&stdout)
+	conf.SetErrOutput(&stderr)
+	context := exec.NewContext(&conf)
+	h := NewHistory(&conf, strings.NewReader("!3\n"), nil, file)
+	scanner := scan.New(context, "<stdin>", h)
+	parser := parse.NewParser("<stdin>", scanner, context)
+	for !Run(parser, context, false) {
+	}
+	if stdout.String() != "6\n" || stderr.Len() > 0 {
+		t.Errorf("!3: got %q, error %q; want \"6\\n\"", stdout.String(), stderr.String())
+	}
+	if len(conf.History()) != 0 {
+		t.Errorf("history: got %q; want none", conf.History())
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "1\n2\n3\n" {
+		t.Errorf("history file: got %q", data)
+	}
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// The log holds the interactive input and its output, but not the lines
+// of files read by )get, nor the prompts.
+func TestHistoryLog(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lib.ivy")
+	if err := os.WriteFile(file, []byte("x = 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var conf config.Config
+	var stdout, stderr, log bytes.Buffer
+	conf.SetOutput(&stdout)
+	conf.SetErrOutput(&stderr)
+	conf.SetPrompt("ivy> ")
+	conf.SetLog("log", nopCloser{&log})
+	context := exec.NewContext(&conf)
+	h := NewHistory(&conf, strings.NewReader(fmt.Sprintf(")get %q\nx+1\n", file)), nil, "")
+	scanner := scan.New(context, "<stdin>", h)
+	parser := parse.NewParser("<stdin>", scanner, context)
+	for !Run(parser, context, true) {
+	}
+	var lines []string
+	for _, line := range strings.Split(log.String(), "\n") {
+		if !strings.HasPrefix(line, "# ") && line != "" {
+			lines = append(lines, line)
+		}
+	}
+	got := strings.Join(lines, "|")
+	want := fmt.Sprintf(")get %q|x+1|\t2", file)
+	if got != want {
+		t.Errorf("log: got %q; want %q", got, want)
+	}
+	if !strings.Contains(stdout.String(), "ivy> ") {
+		t.Errorf("no prompt in output %q", stdout.String())
+	}
+}
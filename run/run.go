@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io"
 	"math/big"
+	"runtime"
 	"strings"
 	"time"
 
@@ -48,7 +49,6 @@ var cpuTime = func() (user, sys time.Duration) { return 0, 0 }
 // Error details are reported to the configured error output stream.
 func Run(p *parse.Parser, context value.Context, interactive bool) (success bool) {
 	conf := context.Config()
-	writer := conf.Output()
 	defer func() {
 		if conf.Debug("panic") > 0 {
 			return
@@ -57,14 +57,20 @@ func Run(p *parse.Parser, context value.Context, interactive bool) (success bool
 		if err == nil {
 			return
 		}
-		_, ok := err.(value.Error)
-		if !ok {
-			_, ok = err.(big.ErrNaN) // Floating point error from math/big.
+		msg := ""
+		switch e := err.(type) {
+		case value.Error:
+			msg = p.ErrorMessage(e)
+		case big.ErrNaN: // Floating point error from math/big.
+			msg = p.Loc() + e.Error()
 		}
-		if ok {
-			fmt.Fprintf(conf.ErrOutput(), "%s%s\n", p.Loc(), err)
+		if msg != "" {
+			if conf.Color() {
+				msg = colorError + msg + colorReset
+			}
+			fmt.Fprintln(conf.ErrOutput(), msg)
 			if interactive {
-				fmt.Fprintln(writer)
+				fmt.Fprintln(conf.Output())
 			}
 			success = false
 			return
@@ -73,21 +79,26 @@ func Run(p *parse.Parser, context value.Context, interactive bool) (success bool
 	}()
 	for {
 		if interactive {
-			fmt.Fprint(writer, conf.Prompt())
+			fmt.Fprint(conf.UnloggedOutput(), conf.Prompt())
 		}
 		exprs, ok := p.Line()
 		var values []value.Value
+		var mem *memUsage
 		if exprs != nil {
 			if interactive {
 				start := time.Now()
 				user, sys := cpuTime()
+				mem = startMem(conf)
 				values = context.Eval(exprs)
+				mem.stop()
 				user2, sys2 := cpuTime()
 				conf.SetCPUTime(time.Since(start), user2-user, sys2-sys)
 			} else {
 				values = context.Eval(exprs)
 			}
 		}
+		// Fetch the writer only now, as a special command such as )log may change it.
+		writer := conf.Output()
 		if printValues(conf, writer, values) {
 			context.AssignGlobal("_", values[len(values)-1])
 		}
@@ -97,14 +108,52 @@ func Run(p *parse.Parser, context value.Context, interactive bool) (success bool
 		if interactive {
 			if exprs != nil && conf.Debug("cpu") > 0 {
 				if real, _, _ := conf.CPUTime(); real != 0 {
-					fmt.Printf("(%s)\n", conf.PrintCPUTime())
+					fmt.Fprintf(writer, "(%s)\n", conf.PrintCPUTime())
 				}
 			}
+			if mem != nil {
+				fmt.Fprintf(writer, "(%s)\n", mem)
+			}
 			fmt.Fprintln(writer)
 		}
 	}
 }
 
+// memUsage records the allocations made by a calculation, for )debug mem.
+type memUsage struct {
+	conf       *config.Config
+	start, end runtime.MemStats
+	// The big.Int and big.Float temporaries taken from the pools,
+	// and how many of those were reused, at start and end.
+	taken, reused [2]uint64
+}
+
+// startMem starts recording allocations, or returns nil if )debug mem is off.
+func startMem(conf *config.Config) *memUsage {
+	if conf.Debug("mem") == 0 {
+		return nil
+	}
+	m := &memUsage{conf: conf}
+	runtime.ReadMemStats(&m.start)
+	m.taken[0], m.reused[0] = conf.TempStats()
+	return m
+}
+
+// stop ends the recording.
+func (m *memUsage) stop() {
+	if m == nil {
+		return
+	}
+	runtime.ReadMemStats(&m.end)
+	m.taken[1], m.reused[1] = m.conf.TempStats()
+}
+
+func (m *memUsage) String() string {
+	return fmt.Sprintf("%d allocs, %d bytes, %d GCs; %d temporaries, %d reused",
+		m.end.Mallocs-m.start.Mallocs, m.end.TotalAlloc-m.start.TotalAlloc, m.end.NumGC-m.start.NumGC,
+		m.taken[1]-m.taken[0], m.reused[1]-m.reused[0])
+}
+
 // eval runs until EOF or error. It prints every value but the last, and returns the last.
 // By last we mean the last expression of the last evaluation.
 // (Expressions are separated by ; in the input.)
@@ -0,0 +1,40 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package run
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/exec"
+	"robpike.io/ivy/parse"
+	"robpike.io/ivy/scan"
+)
+
+// The report of )debug mem goes to the configured output, and counts
+// the temporaries of a float reduction.
+func TestDebugMem(t *testing.T) {
+	var conf config.Config
+	var stdout, stderr bytes.Buffer
+	conf.SetOutput(&stdout)
+	conf.SetErrOutput(&stderr)
+	context := exec.NewContext(&conf)
+	input := ")debug mem\nx = float (iota 10) / 3\n+/x\n"
+	scanner := scan.New(context, "<test>", strings.NewReader(input))
+	Run(parse.NewParser("<test>", scanner, context), context, true)
+	if stderr.Len() > 0 {
+		t.Fatal(stderr.String())
+	}
+	pattern := regexp.MustCompile(`\n18.3333333333\n\([0-9]+ allocs, [0-9]+ bytes, [0-9]+ GCs; 1 temporaries, [01] reused\)\n`)
+	if got := stdout.String(); !pattern.MatchString(got) {
+		t.Errorf("got %q; want match for %s", got, pattern)
+	}
+	if taken, _ := conf.TempStats(); taken != 1 {
+		t.Errorf("taken %d temporaries; want 1", taken)
+	}
+}
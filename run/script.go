@@ -0,0 +1,65 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package run
+
+import (
+	"bytes"
+	"strings"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/exec"
+	"robpike.io/ivy/parse"
+	"robpike.io/ivy/scan"
+)
+
+// ScriptConfig returns a configuration whose settings are fixed, not
+// taken from the environment, so that a program run with it produces
+// the same output every time: the random seed is 0, floating-point
+// precision is 256 bits, the time zone is UTC, and the limits are
+// those of the ivy command. External commands and network access are
+// disabled. Only values that depend on the time of day, such as those
+// of sys "now", still vary.
+func ScriptConfig() *config.Config {
+	conf := new(config.Config)
+	conf.SetFormat("")
+	conf.SetFloatPrec(256)
+	conf.SetMaxBits(1e9)
+	conf.SetMaxDigits(1e4)
+	conf.SetMaxElems(1e8)
+	conf.SetMaxStack(100000)
+	conf.SetOrigin(1)
+	conf.SetPrompt("")
+	conf.SetBase(0, 0)
+	conf.SetRandomSeed(0)
+	conf.SetLocation("UTC")
+	return conf
+}
+
+// Script runs the ivy program in a new Context using conf, or
+// ScriptConfig() if conf is nil, and returns separately what it
+// printed as output and as errors. Like a file given to the ivy
+// command, the program continues after an error; ok reports whether
+// there were none. The name identifies the program in error messages.
+// Script is intended for tests that pin the behavior of ivy programs,
+// for instance by comparing the output with a golden file.
+func Script(conf *config.Config, name, program string) (stdout, stderr string, ok bool) {
+	if conf == nil {
+		conf = ScriptConfig()
+	}
+	var out, errs bytes.Buffer
+	conf.SetOutput(&out)
+	conf.SetErrOutput(&errs)
+	context := exec.NewContext(conf)
+	if !strings.HasSuffix(program, "\n") {
+		program += "\n"
+	}
+	scanner := scan.New(context, name, strings.NewReader(program))
+	parser := parse.NewParser(name, scanner, context)
+	ok = true
+	for !Run(parser, context, false) {
+		ok = false
+	}
+	return out.String(), errs.String(), ok
+}
@@ -0,0 +1,30 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package run
+
+import "testing"
+
+func TestScript(t *testing.T) {
+	const program = "?100 100 100\nsqrt 2\n1/0\n2**3\n"
+	stdout, stderr, ok := Script(nil, "prog.ivy", program)
+	if ok {
+		t.Error("script with an error reported success")
+	}
+	if stderr != "prog.ivy:3: zero denominator in rational\n" {
+		t.Errorf("stderr: got %q", stderr)
+	}
+	// The output is the same every time.
+	stdout2, _, _ := Script(nil, "prog.ivy", program)
+	if stdout != stdout2 {
+		t.Errorf("output differs between runs:\n%s\n%s", stdout, stdout2)
+	}
+	const want = "1.41421356237\n8\n"
+	if len(stdout) < len(want) || stdout[len(stdout)-len(want):] != want {
+		t.Errorf("stdout: got %q; want suffix %q", stdout, want)
+	}
+	if _, _, ok := Script(nil, "ok.ivy", "1+1"); !ok {
+		t.Error("script without errors reported failure")
+	}
+}
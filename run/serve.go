@@ -0,0 +1,187 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package run
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/exec"
+	"robpike.io/ivy/parse"
+	"robpike.io/ivy/scan"
+)
+
+// The server protocol is line by line. The client sends lines of ivy
+// input and the server replies to each with zero or more lines of
+// output, each prefixed with "=", then zero or more lines of error
+// messages, each prefixed with "!", and finally a line holding only ".".
+// A statement that spans lines, such as a multiline op definition,
+// produces its output in the reply to its last line.
+
+// ServerLimits bounds the resources a client of the server may use.
+// Limits on the values themselves, such as maxbits, maxelems and maxstack, are
+// set in the configuration of each connection; the sandbox keeps clients
+// from changing them.
+type ServerLimits struct {
+	MaxConns    int           // Maximum simultaneous connections; 0 means no limit.
+	IdleTimeout time.Duration // Close a connection idle this long; 0 means never.
+	MaxLine     int           // Maximum length of an input line in bytes; 0 means 64K.
+	MaxOutput   int           // Maximum bytes of output in a reply; 0 means no limit.
+}
+
+// Serve accepts connections on l and serves each with ServeConn in a
+// Context of its own. Each connection's configuration is prepared by
+// setup, which may be nil. Serve returns when Accept fails.
+func Serve(l net.Listener, setup func(*config.Config), limits ServerLimits) error {
+	var slots chan struct{}
+	if limits.MaxConns > 0 {
+		slots = make(chan struct{}, limits.MaxConns)
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		if slots != nil {
+			select {
+			case slots <- struct{}{}:
+			default:
+				io.WriteString(conn, "!too many connections\n.\n")
+				conn.Close()
+				continue
+			}
+		}
+		go func() {
+			defer func() {
+				// A panic in one connection must not bring down the server.
+				if err := recover(); err != nil {
+					log.Printf("ivy: connection from %s: panic: %v", conn.RemoteAddr(), err)
+				}
+				conn.Close()
+				if slots != nil {
+					<-slots
+				}
+			}()
+			conf := new(config.Config)
+			if setup != nil {
+				setup(conf)
+			}
+			ServeConn(conn, conf, limits)
+		}()
+	}
+}
+
+// ServeConn runs the server protocol on rw, evaluating the input in a new
+// Context using conf, until the input ends. If rw is a net.Conn, the idle
+// timeout applies. The configuration is sandboxed, so the client cannot
+// reach the server's files, environment, or programs.
+func ServeConn(rw io.ReadWriter, conf *config.Config, limits ServerLimits) {
+	conf.SetSandbox(true)
+	var out, errs bytes.Buffer
+	conf.SetOutput(&out)
+	conf.SetErrOutput(&errs)
+	context := exec.NewContext(conf)
+	lines := bufio.NewScanner(rw)
+	maxLine := limits.MaxLine
+	if maxLine <= 0 {
+		maxLine = bufio.MaxScanTokenSize
+	}
+	lines.Buffer(nil, maxLine)
+	r := &serverReader{
+		lines:  lines,
+		w:      bufio.NewWriter(rw),
+		out:    &out,
+		errs:   &errs,
+		limits: limits,
+	}
+	r.conn, _ = rw.(net.Conn)
+	scanner := scan.New(context, "<conn>", r)
+	parser := parse.NewParser("<conn>", scanner, context)
+	for !Run(parser, context, false) {
+	}
+	r.reply()
+}
+
+// serverReader feeds the scanner one line of client input at a time.
+// Before reading a line, it replies to the previous one, whose
+// evaluation is complete once the scanner asks for more input.
+type serverReader struct {
+	lines   *bufio.Scanner
+	w       *bufio.Writer
+	out     *bytes.Buffer
+	errs    *bytes.Buffer
+	conn    net.Conn
+	limits  ServerLimits
+	line    string
+	pos     int
+	started bool
+	done    bool
+}
+
+func (r *serverReader) ReadByte() (byte, error) {
+	if r.pos == len(r.line) {
+		if r.done {
+			return 0, io.EOF
+		}
+		if r.started {
+			r.reply()
+		}
+		r.started = true
+		if r.conn != nil && r.limits.IdleTimeout > 0 {
+			r.conn.SetReadDeadline(time.Now().Add(r.limits.IdleTimeout))
+		}
+		if !r.lines.Scan() {
+			r.done = true
+			r.started = false
+			return 0, io.EOF
+		}
+		r.line = r.lines.Text() + "\n"
+		r.pos = 0
+	}
+	c := r.line[r.pos]
+	r.pos++
+	return c, nil
+}
+
+// reply sends the output and errors for the last line, if not yet sent.
+func (r *serverReader) reply() {
+	if !r.started {
+		return
+	}
+	r.started = false
+	out := r.out.String()
+	truncated := r.limits.MaxOutput > 0 && len(out) > r.limits.MaxOutput
+	if truncated {
+		out = out[:r.limits.MaxOutput]
+	}
+	writeLines(r.w, "=", out)
+	if truncated {
+		r.w.WriteString("!output truncated\n")
+	}
+	writeLines(r.w, "!", r.errs.String())
+	r.w.WriteString(".\n")
+	r.w.Flush()
+	r.out.Reset()
+	r.errs.Reset()
+}
+
+// writeLines writes each line of text to w with the prefix.
+func writeLines(w *bufio.Writer, prefix, text string) {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		w.WriteString(prefix)
+		w.WriteString(line)
+		w.WriteByte('\n')
+	}
+}
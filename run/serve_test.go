@@ -0,0 +1,182 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package run
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"robpike.io/ivy/config"
+)
+
+func TestServeConn(t *testing.T) {
+	in := strings.Join([]string{
+		"1+2",
+		"x = 3",
+		"1/0",
+		"2 2 rho x",
+		"",
+	}, "\n")
+	var out bytes.Buffer
+	rw := struct {
+		io.Reader
+		io.Writer
+	}{strings.NewReader(in), &out}
+	ServeConn(rw, new(config.Config), ServerLimits{})
+	want := strings.Join([]string{
+		"=3",
+		".",
+		".",
+		"!<conn>:3: zero denominator in rational",
+		".",
+		"=3 3",
+		"=3 3",
+		".",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestServeLimits(t *testing.T) {
+	in := "iota 100\n"
+	var out bytes.Buffer
+	rw := struct {
+		io.Reader
+		io.Writer
+	}{strings.NewReader(in), &out}
+	ServeConn(rw, new(config.Config), ServerLimits{MaxOutput: 10})
+	want := "=1 2 3 4 5 \n!output truncated\n.\n"
+	if out.String() != want {
+		t.Errorf("got %q; want %q", out.String(), want)
+	}
+}
+
+func TestServe(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer l.Close()
+	go Serve(l, func(c *config.Config) { c.SetOrigin(0) }, ServerLimits{})
+	// Each connection has its own variables.
+	tests := []struct {
+		in, want string
+	}{
+		{"y = 7\niota 3\ny\n", ".,=0 1 2,.,=7,."},
+		{"iota 3\ny\n", "=0 1 2,.,!<conn>:2: undefined global variable \"y\",."},
+	}
+	for i, test := range tests {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, test.in)
+		r := bufio.NewReader(conn)
+		var got []string
+		for len(got) < strings.Count(test.want, ",")+1 {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, strings.TrimSuffix(line, "\n"))
+		}
+		if strings.Join(got, ",") != test.want {
+			t.Errorf("connection %d: got %q; want %q", i, strings.Join(got, ","), test.want)
+		}
+	}
+}
+
+func TestServeSandbox(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "saved")
+	in := strings.Join([]string{
+		fmt.Sprintf(")save %q", file),
+		`sys "env" "HOME"`,
+		`sys "read" "/etc/passwd"`,
+		")maxelems 0",
+		")prec 1e6",
+		"",
+	}, "\n")
+	var out bytes.Buffer
+	rw := struct {
+		io.Reader
+		io.Writer
+	}{strings.NewReader(in), &out}
+	conf := new(config.Config)
+	conf.SetExec(true)
+	ServeConn(rw, conf, ServerLimits{})
+	want := strings.Join([]string{
+		"!<conn>:1: )save not allowed in sandbox",
+		".",
+		"!<conn>:2: sys \"env\" not allowed in sandbox",
+		".",
+		"!<conn>:3: sys \"read\" not allowed in sandbox",
+		".",
+		"!<conn>:4: )maxelems not allowed in sandbox",
+		".",
+		"!<conn>:5: )prec not allowed in sandbox",
+		".",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if _, err := os.Stat(file); err == nil {
+		t.Errorf("%s was written", file)
+	}
+}
+
+// A panic while serving one connection closes it but not the server.
+func TestServePanic(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer l.Close()
+	go Serve(l, func(c *config.Config) { c.SetDebug("panic", 1) }, ServerLimits{})
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprint(conn, "1/0\n")
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	conn, err = net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprint(conn, "1+2\n")
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "=3\n" {
+		t.Errorf("after panic: got %q; want %q", line, "=3\n")
+	}
+}
+
+func TestServeSandboxDebug(t *testing.T) {
+	var out bytes.Buffer
+	rw := struct {
+		io.Reader
+		io.Writer
+	}{strings.NewReader(")debug panic\n"), &out}
+	ServeConn(rw, new(config.Config), ServerLimits{})
+	if want := "!<conn>:1: )debug not allowed in sandbox\n.\n"; out.String() != want {
+		t.Errorf("got %q; want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package run
+
+import (
+	"errors"
+	"os"
+)
+
+// Line editing is not supported on this system.
+
+func isTerminal(f *os.File) bool {
+	return false
+}
+
+func makeRaw(f *os.File) (restore func(), err error) {
+	return nil, errors.New("terminal raw mode not supported")
+}
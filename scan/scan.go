@@ -64,6 +64,13 @@ func (i Token) String() string {
 
 const eof = -1
 
+// highMinus is APL's sign for negative numbers, as in ¯3. Unlike '-', it
+// is never an operator, so 1 ¯2 3 is always a vector.
+const (
+	highMinus       = '¯'
+	highMinusString = string(highMinus)
+)
+
 // stateFn represents the state of the scanner as a function that returns the next state.
 type stateFn func(*Scanner) stateFn
 
@@ -172,10 +179,15 @@ func (l *Scanner) backup() {
 
 // emit passes an item back to the client.
 func (l *Scanner) emit(t Type) stateFn {
+	return l.emitText(t, l.input[l.start:l.pos])
+}
+
+// emitText passes an item with the given text, which may differ from
+// the input scanned, back to the client.
+func (l *Scanner) emitText(t Type, text string) stateFn {
 	if t == Newline {
 		l.line++
 	}
-	text := l.input[l.start:l.pos]
 	config := l.context.Config()
 	if config.Debug("tokens") > 0 {
 		fmt.Fprintf(config.Output(), "%s:%d: emit %s\n", l.name, l.line, Token{t, l.line, text})
@@ -236,6 +248,13 @@ func (l *Scanner) Next() Token {
 	}
 }
 
+// Offset returns the byte offset in the input, counted from the start
+// of the line on which the token began, just past the last token
+// returned by Next. After an error token it is zero.
+func (l *Scanner) Offset() int {
+	return l.pos
+}
+
 // state functions
 
 // lexComment scans a comment. The comment marker has been consumed.
@@ -268,6 +287,8 @@ func lexAny(l *Scanner) stateFn {
 		return lexQuote
 	case r == '`':
 		return lexRawQuote
+	case r == '\\' && l.atContinuation():
+		return lexContinuation
 	case r == '-' || r == '+':
 		// It's an operator if it's preceded immediately (no spaces) by an operand, which is
 		// an identifier, an indexed expression, or a parenthesized expression.
@@ -283,7 +304,7 @@ func lexAny(l *Scanner) stateFn {
 			}
 		}
 		fallthrough
-	case r == '.' || '0' <= r && r <= '9':
+	case r == '.' || '0' <= r && r <= '9' || r == highMinus:
 		l.backup()
 		return lexComplex
 	case r == '=':
@@ -319,6 +340,33 @@ func lexAny(l *Scanner) stateFn {
 	}
 }
 
+// atContinuation reports whether the backslash just scanned continues
+// the line: it follows a space, so it is not part of an operator such as
+// +\, and nothing but spaces and a comment follow it on the line.
+func (l *Scanner) atContinuation() bool {
+	if l.start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(l.input[:l.start]); !isSpace(r) {
+			return false
+		}
+	}
+	rest, ok := strings.CutSuffix(l.input[l.pos:], "\n")
+	if !ok {
+		return false
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	return strings.TrimSpace(rest) == ""
+}
+
+// lexContinuation skips the rest of the line after a continuation
+// backslash, so the next line continues the current one.
+func lexContinuation(l *Scanner) stateFn {
+	for l.next() != '\n' {
+	}
+	l.line++
+	l.start = l.pos
+	return lexAny
+}
+
 // lexSpace scans a run of space characters.
 // One space has already been seen.
 func lexSpace(l *Scanner) stateFn {
@@ -420,12 +468,31 @@ func lexOperator(l *Scanner) stateFn {
 			}
 		}
 	}
+	if l.input[l.start:l.pos] == w && (value.BinaryOps[w] != nil || l.context.UserDefined(w, true)) && l.eachIndex() {
+		// Each with index: f #@ x. The # must be recognized here, before it starts a comment.
+		return l.emitText(Operator, w+"#@")
+	}
 	if isIdentifier(l.input[l.start:l.pos]) {
 		return l.emit(Identifier)
 	}
 	return l.emit(Operator)
 }
 
+// eachIndex reports whether the binary operator just scanned is followed,
+// possibly after spaces, by the each-with-index decorator #@. If so, it
+// consumes the decorator.
+func (l *Scanner) eachIndex() bool {
+	i := l.pos
+	for i < len(l.input) && isSpace(rune(l.input[i])) {
+		i++
+	}
+	if !strings.HasPrefix(l.input[i:], "#@") {
+		return false
+	}
+	l.pos = i + len("#@")
+	return true
+}
+
 // atTerminator reports whether the input is at valid termination character to
 // appear after an identifier or number element.
 func (l *Scanner) atTerminator() bool {
@@ -466,8 +533,12 @@ func lexComplex(l *Scanner) stateFn {
 // false, we've just seen a 'j' and we need another number.
 // It returns the next lex function to run.
 func acceptNumber(l *Scanner, realPart bool) (bool, stateFn) {
-	// Optional leading sign.
-	if l.accept("+-") && realPart {
+	// Optional leading sign. The APL high minus is always a sign.
+	if l.accept(highMinusString) {
+		if r := l.peek(); r != '.' && !l.isNumeral(r) {
+			return false, l.errorf("bad number syntax: %s", l.input[l.start:l.pos])
+		}
+	} else if l.accept("+-") && realPart {
 		// Might not be a number.
 		r := l.peek()
 		// Might be a scan or reduction.
@@ -513,10 +584,16 @@ func acceptNumber(l *Scanner, realPart bool) (bool, stateFn) {
 func (l *Scanner) scanNumber(followingSlashOK, followingJOK bool) bool {
 	base := l.context.Config().InputBase()
 	digits := digitsForBase(base)
-	// If base 0, accept octal for 0 or hex for 0x or 0X.
-	if base == 0 {
-		if l.accept("0") && l.accept("xX") {
+	// If base 0, accept octal for 0, hex for 0x or 0X, binary for
+	// 0b or 0B, and octal for 0o or 0O.
+	if base == 0 && l.accept("0") {
+		switch {
+		case l.accept("xX"):
 			digits = digitsForBase(16)
+		case l.accept("bB"):
+			digits = digitsForBase(2)
+		case l.accept("oO"):
+			digits = digitsForBase(8)
 		}
 		// Otherwise leave it decimal (0); strconv.ParseInt will take care of it.
 		// We can't set it to 8 in case it's a leading-0 float like 0.69 or 09e4.
@@ -524,9 +601,17 @@ func (l *Scanner) scanNumber(followingSlashOK, followingJOK bool) bool {
 	l.acceptRun(digits)
 	if l.accept(".") {
 		l.acceptRun(digits)
+		if l.peek() == '.' && (base == 0 || base == 10) {
+			return l.scanTime()
+		}
 	}
 	if l.accept("eE") {
-		l.accept("+-")
+		l.accept("+-" + highMinusString)
+		l.acceptRun("0123456789")
+	}
+	// A binary exponent, as in 0x1.8p3, unless p is a digit.
+	if !strings.ContainsRune(digits, 'p') && l.accept("pP") {
+		l.accept("+-" + highMinusString)
 		l.acceptRun("0123456789")
 	}
 	r := l.peek()
@@ -548,6 +633,22 @@ func (l *Scanner) scanNumber(followingSlashOK, followingJOK bool) bool {
 	return true
 }
 
+// scanTime scans the rest of a time literal such as 2024.01.15T10:30:00Z,
+// having seen the year and month. The value package checks the details.
+func (l *Scanner) scanTime() bool {
+	l.accept(".")
+	l.acceptRun("0123456789")
+	if l.accept("T") {
+		l.acceptRun("0123456789:.")
+	}
+	l.accept("Z")
+	if r := l.peek(); isAlphaNumeric(r) || !l.atTerminator() {
+		l.next()
+		return false
+	}
+	return true
+}
+
 var digits [36 + 1]string // base 36 is OK.
 
 const (
@@ -716,8 +817,12 @@ func (l *Scanner) isOperator(r rune) bool {
 
 func (l *Scanner) isOperatorToken(r rune) bool {
 	switch r {
-	case '?', '+', '-', '/', '&', '|', '^':
+	case '?', '+', '-', '/', '^':
 		// No follow-on possible.
+	case '&', '|':
+		if l.peek() == r { // For && and ||.
+			l.next()
+		}
 	case ',':
 		if l.peek() == '%' {
 			l.next()
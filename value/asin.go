@@ -136,7 +136,9 @@ func floatAtan(c Context, x *big.Float) *big.Float {
 	z := newFloat(c)
 
 	// n goes up by two each loop.
-	for loop := newLoop(c.Config(), "atan", x, 4); ; {
+	loop := newLoop(c.Config(), "atan", x, 4)
+	defer loop.release()
+	for {
 		term.Set(xN)
 		term.Quo(term, n.SetUint64(2*loop.i+1))
 		z.Add(z, term)
@@ -168,7 +170,9 @@ func floatAtanLarge(c Context, x *big.Float) *big.Float {
 	z.Quo(z, floatTwo)
 
 	// n goes up by two each loop.
-	for loop := newLoop(c.Config(), "atan", x, 4); ; {
+	loop := newLoop(c.Config(), "atan", x, 4)
+	defer loop.release()
+	for {
 		xN.Neg(xN)
 		term.Set(xN)
 		term.Mul(term, n.SetUint64(2*loop.i+1))
@@ -4,6 +4,8 @@
 
 package value
 
+import "slices"
+
 // Code for assignment, a little intricate as there are many cases and many
 // validity checks.
 
@@ -40,6 +42,12 @@ func Assign(context Context, left, right Expr, rhs Value) {
 			IndexAssign(context, lhs, lhs.Left, lv, lhs.Right, right, rhs)
 			return
 		}
+	case *BinaryExpr:
+		// (mask sel x) = rhs.
+		if lv, ok := lhs.Right.(*VarExpr); ok && lhs.Op == "sel" {
+			selAssign(context, lhs, lv, rhs)
+			return
+		}
 	case VectorExpr:
 		// Simultaneous assignment requires evaluation of RHS before assignment.
 		rhs, ok := rhs.(*Vector)
@@ -57,3 +65,71 @@ func Assign(context Context, left, right Expr, rhs Value) {
 	// unexpected: parser should have caught this
 	Errorf("internal error: cannot assign to %s", left.ProgString())
 }
+
+// selAssign implements (mask sel x) = rhs, assigning the elements of rhs,
+// or rhs itself if it is a scalar, to the elements of x selected by the
+// 0s and 1s of mask, as if by mask sel x.
+func selAssign(context Context, lhs *BinaryExpr, lv *VarExpr, rhs Value) {
+	mask := lhs.Left.Eval(context).Inner()
+	for _, m := range maskData(mask).All() {
+		if m != zero && m != one {
+			Errorf("assignment to %s: mask must be 0s and 1s", lhs.ProgString())
+		}
+	}
+	x := lv.Eval(context)
+	// Select the positions of the elements of x as mask sel x would select them.
+	var data *Vector
+	var positions Value
+	switch x := x.(type) {
+	case *Vector:
+		data = x
+		positions = newIota(0, x.Len())
+	case *Matrix:
+		data = x.data
+		positions = NewMatrix(x.shape, newIota(0, x.data.Len()))
+	default:
+		Errorf("assignment to %s: %s is not a vector or matrix", lhs.ProgString(), lv.Name)
+	}
+	var selected *Vector
+	mm, maskIsMatrix := mask.(*Matrix)
+	if xm, ok := x.(*Matrix); ok && maskIsMatrix && slices.Equal(mm.shape, xm.shape) {
+		// Elementwise, without the requirement that rows select equal counts.
+		selected = maskData(positions).sel(context, mm.data, mm.data.Len())
+	} else {
+		selected = maskData(context.EvalBinary(mask, "sel", positions))
+	}
+	var values *Vector
+	switch r := rhs.(type) {
+	case *Vector:
+		values = r
+	case *Matrix:
+		values = r.data
+	}
+	if values != nil && values.Len() != selected.Len() {
+		Errorf("assignment to %s: length mismatch: %d selected, %d values", lhs.ProgString(), selected.Len(), values.Len())
+	}
+	edit := data.edit()
+	for i, pos := range selected.All() {
+		v := rhs
+		if values != nil {
+			v = values.At(i)
+		}
+		edit.Set(int(pos.(Int)), v)
+	}
+	result := Value(edit.Publish())
+	if m, ok := x.(*Matrix); ok {
+		result = NewMatrix(m.shape, result.(*Vector))
+	}
+	Assign(context, lv, nil, result)
+}
+
+// maskData returns the elements of v as a vector.
+func maskData(v Value) *Vector {
+	switch v := v.(type) {
+	case *Vector:
+		return v
+	case *Matrix:
+		return v.data
+	}
+	return NewVector(v)
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import "strings"
+
+// Axis operators: +/[2] x, x ,[1] y, k rot[2] x, k sel[1] x, and so on.
+// Each operator supported works on the last axis. To apply it along
+// another axis, we transpose the operands so that axis is last,
+// apply the operator, and transpose the result back.
+
+// axisUnary reports whether op supports an axis specification.
+func axisUnary(op string) bool {
+	switch op {
+	case "rot", "flip", "raze", "cummax", "cummin", "diff":
+		return true
+	}
+	// Reductions and scans along the last axis.
+	return len(op) > 1 && (strings.HasSuffix(op, "/") || strings.HasSuffix(op, `\`))
+}
+
+// axisBinary reports whether op supports an axis specification.
+func axisBinary(op string) bool {
+	switch op {
+	case ",", "rot", "flip", "sel", "fill", "part":
+		return true
+	}
+	// N-wise reductions along the last axis.
+	return len(op) > 1 && strings.HasSuffix(op, "/")
+}
+
+// axisIndex returns the zero-based axis of a value of the given rank
+// specified by the origin-based axis value.
+func axisIndex(c Context, op string, axis Value, rank int) int {
+	origin := c.Config().Origin()
+	i, ok := axis.Inner().(Int)
+	if !ok {
+		if v, isVec := axis.(*Vector); isVec && v.Len() == 1 {
+			i, ok = v.At(0).(Int)
+		}
+	}
+	if !ok {
+		Errorf("%s: axis must be a small integer: %s", op, axis)
+	}
+	k := int(i) - origin
+	if k < 0 || k >= rank {
+		Errorf("%s: axis %d out of range for rank %d", op, i, rank)
+	}
+	return k
+}
+
+// moveAxis returns m transposed so that axis from becomes axis to,
+// with the other axes retaining their relative order.
+func moveAxis(c Context, m *Matrix, from, to int) *Matrix {
+	if from == to {
+		return m
+	}
+	order := make([]int, 0, m.Rank())
+	for i := range m.Rank() {
+		if i != from {
+			order = append(order, i)
+		}
+	}
+	order = append(order[:to], append([]int{from}, order[to:]...)...)
+	origin := c.Config().Origin()
+	oldToNew := make([]int, m.Rank())
+	for newAxis, oldAxis := range order {
+		oldToNew[oldAxis] = newAxis + origin
+	}
+	return m.binaryTranspose(c, NewIntVector(oldToNew...))
+}
+
+// EvalUnaryAxis evaluates op v along the specified axis of v.
+func EvalUnaryAxis(c Context, op string, axis, v Value) Value {
+	if !axisUnary(op) {
+		Errorf("%s: axis not supported", op)
+	}
+	if op == "flip" {
+		op = "rot" // With an axis, they are the same.
+	}
+	m, ok := v.(*Matrix)
+	if !ok {
+		// A vector or scalar has only one axis.
+		axisIndex(c, op, axis, 1)
+		return c.EvalUnary(op, v)
+	}
+	last := m.Rank() - 1
+	k := axisIndex(c, op, axis, m.Rank())
+	result := c.EvalUnary(op, moveAxis(c, m, k, last))
+	if r, ok := result.(*Matrix); ok && r.Rank() == m.Rank() {
+		return moveAxis(c, r, last, k)
+	}
+	return result // A reduction has removed the axis.
+}
+
+// EvalBinaryAxis evaluates u op v along the specified axis.
+func EvalBinaryAxis(c Context, u Value, op string, axis, v Value) Value {
+	if !axisBinary(op) {
+		Errorf("%s: axis not supported", op)
+	}
+	if op == "flip" {
+		op = "rot"
+	}
+	// The axis refers to the operand of higher rank.
+	rank := max(u.Rank(), v.Rank())
+	if rank < 2 {
+		axisIndex(c, op, axis, 1)
+		return c.EvalBinary(u, op, v)
+	}
+	last := rank - 1
+	k := axisIndex(c, op, axis, rank)
+	move := func(x Value) Value {
+		// Operands of lower rank, such as counts, lack the axis;
+		// they apply to the others, in order.
+		if m, ok := x.(*Matrix); ok && m.Rank() == rank {
+			return moveAxis(c, m, k, last)
+		}
+		return x
+	}
+	result := c.EvalBinary(move(u), op, move(v))
+	if r, ok := result.(*Matrix); ok && r.Rank() == rank {
+		return moveAxis(c, r, last, k)
+	}
+	return result
+}
@@ -27,6 +27,12 @@ func (f BigFloat) Rank() int {
 	return 0
 }
 
+// Hash reports that a float has no hash, as it may equal a rational
+// that it only approximates.
+func (f BigFloat) Hash() (uint64, bool) {
+	return 0, false
+}
+
 const fastFloatPrint = true
 
 func (f BigFloat) String() string {
@@ -34,6 +40,9 @@ func (f BigFloat) String() string {
 }
 
 func (f BigFloat) Sprint(conf *config.Config) string {
+	if f.Float == nanFloat {
+		return "NaN"
+	}
 	var mant big.Float
 	exp := f.Float.MantExp(&mant)
 	positive := 1
@@ -165,10 +174,18 @@ func (f BigFloat) toType(op string, conf *config.Config, which valueType) Value
 
 // shrink shrinks, if possible, a BigFloat down to an integer type.
 func (f BigFloat) shrink() Value {
-	exp := f.MantExp(nil)
-	if exp <= 100 && f.IsInt() { // Huge integers are not pretty. (Exp here is power of two.)
+	if f.Float == nanFloat {
+		return f
+	}
+	if isSmallInt(f.Float) {
 		i, _ := f.Int(nil) // Result guaranteed exact.
 		return BigInt{i}.shrink()
 	}
 	return f
 }
+
+// isSmallInt reports whether shrink turns f into an integer.
+func isSmallInt(f *big.Float) bool {
+	exp := f.MantExp(nil)
+	return exp <= 100 && f.IsInt() // Huge integers are not pretty. (Exp here is power of two.)
+}
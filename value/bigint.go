@@ -29,6 +29,19 @@ func (i BigInt) Rank() int {
 	return 0
 }
 
+// Hash returns the hash of the integer, the same as that of an Int
+// with the same value.
+func (i BigInt) Hash() (uint64, bool) {
+	if i.IsInt64() {
+		return Int(i.Int64()).Hash()
+	}
+	h := hashCombine(hashTagBigInt, uint64(i.Sign()))
+	for _, w := range i.Bits() {
+		h = hashCombine(h, uint64(w))
+	}
+	return h, true
+}
+
 func setBigIntString(conf *config.Config, s string) (BigInt, error) {
 	i, ok := big.NewInt(0).SetString(s, conf.InputBase())
 	if !ok {
@@ -60,18 +73,11 @@ func (i BigInt) Sprint(conf *config.Config) string {
 	if i.BitLen() < intBits {
 		return Int(i.Int64()).Sprint(conf)
 	}
-	switch conf.OutputBase() {
-	case 0, 10:
-		return fmt.Sprintf("%d", i.Int)
-	case 2:
-		return fmt.Sprintf("%b", i.Int)
-	case 8:
-		return fmt.Sprintf("%o", i.Int)
-	case 16:
-		return fmt.Sprintf("%x", i.Int)
-	}
-	Errorf("can't print number in base %d (yet)", conf.OutputBase())
-	return ""
+	base := conf.OutputBase()
+	if base == 0 {
+		base = 10
+	}
+	return i.Text(base)
 }
 
 func (i BigInt) ProgString() string {
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 
 	"robpike.io/ivy/config"
@@ -22,7 +23,7 @@ type BigRat struct {
 func setBigRatFromFloatString(s string) (br BigRat, err error) {
 	// Be safe: Verify that it is floating-point, because otherwise
 	// we need to honor ibase.
-	if !strings.ContainsAny(s, ".eE") {
+	if !strings.ContainsAny(s, ".eEpP") {
 		// Most likely a number like "08".
 		Errorf("bad number syntax: %s", s)
 	}
@@ -34,6 +35,66 @@ func setBigRatFromFloatString(s string) (br BigRat, err error) {
 	return BigRat{r}, nil
 }
 
+// setBigRatFromBaseString parses s, a floating-point number in a
+// non-decimal input base. All the digits, including those after the point,
+// are in that base. A 'p' exponent scales by a power of two, as in
+// hexadecimal floats, and an 'e' exponent, possible only when e is not a
+// digit, by a power of the base. Exponents are always decimal.
+func setBigRatFromBaseString(conf *config.Config, s string) (BigRat, error) {
+	base := conf.InputBase()
+	mant, exp := s, ""
+	scale := big.NewInt(2)
+	if i := strings.IndexAny(s, "pP"); i >= 0 && base <= 'p'-'a'+10 {
+		mant, exp = s[:i], s[i+1:]
+	} else if i := strings.IndexAny(s, "eE"); i >= 0 && base <= 'e'-'a'+10 {
+		mant, exp, scale = s[:i], s[i+1:], big.NewInt(int64(base))
+	}
+	intPart, frac, _ := strings.Cut(mant, ".")
+	digits := intPart + frac
+	for _, r := range strings.TrimLeft(digits, "+-") {
+		if d := digitVal(r); d < 0 || d >= base {
+			return BigRat{}, fmt.Errorf("bad digit %q for base %d", r, base)
+		}
+	}
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return BigRat{}, errors.New("floating-point number syntax")
+	}
+	den := new(big.Int).Exp(big.NewInt(int64(base)), big.NewInt(int64(len(frac))), nil)
+	r := new(big.Rat).SetFrac(n, den)
+	if exp != "" {
+		e, err := strconv.ParseInt(strings.Replace(exp, "¯", "-", 1), 10, 64)
+		if err != nil {
+			return BigRat{}, fmt.Errorf("bad exponent %q", exp)
+		}
+		neg := e < 0
+		if neg {
+			e = -e
+		}
+		mustFit(conf, e*int64(scale.BitLen()))
+		pow := new(big.Rat).SetInt(scale.Exp(scale, big.NewInt(e), nil))
+		if neg {
+			r.Quo(r, pow)
+		} else {
+			r.Mul(r, pow)
+		}
+	}
+	return BigRat{r}, nil
+}
+
+// digitVal returns the value of the digit r in bases up to 36, or -1.
+func digitVal(r rune) int {
+	switch {
+	case '0' <= r && r <= '9':
+		return int(r - '0')
+	case 'a' <= r && r <= 'z':
+		return int(r-'a') + 10
+	case 'A' <= r && r <= 'Z':
+		return int(r-'A') + 10
+	}
+	return -1
+}
+
 func (r BigRat) String() string {
 	return "(" + r.Sprint(debugConf) + ")"
 }
@@ -42,6 +103,11 @@ func (r BigRat) Rank() int {
 	return 0
 }
 
+// Hash reports that a rational has no hash, as it may equal a float.
+func (r BigRat) Hash() (uint64, bool) {
+	return 0, false
+}
+
 func (r BigRat) Sprint(conf *config.Config) string {
 	format := conf.Format()
 	if format != "" {
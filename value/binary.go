@@ -7,6 +7,7 @@ package value
 import (
 	"math"
 	"math/big"
+	"slices"
 	"sort"
 )
 
@@ -54,6 +55,15 @@ func atLeastVectorType(t1, t2 valueType) (valueType, valueType) {
 	return matrixType, matrixType
 }
 
+// compareType promotes both arguments to the larger type only if one is
+// a vector or matrix; scalars of any types may be compared as they are.
+func compareType(t1, t2 valueType) (valueType, valueType) {
+	if t1 < vectorType && t2 < vectorType {
+		return t1, t2
+	}
+	return binaryArithType(t1, t2)
+}
+
 // vectorAndMatrixType promotes the left arg to vector and the right arg to matrix.
 func vectorAndMatrixType(t1, t2 valueType) (valueType, valueType) {
 	return vectorType, matrixType
@@ -83,6 +93,17 @@ func shiftCount(x Value) uint {
 		if _, ok := reduced.(Int); ok {
 			return shiftCount(reduced)
 		}
+	case BigRat:
+		// Lifted to match a rational LHS.
+		if count.IsInt() {
+			return shiftCount(BigInt{count.Num()})
+		}
+	case BigFloat:
+		// Lifted to match a floating-point LHS.
+		if count.IsInt() {
+			i, _ := count.Int(nil)
+			return shiftCount(BigInt{i})
+		}
 	}
 	Errorf("illegal shift count type")
 	panic("not reached")
@@ -189,6 +210,19 @@ func allEqual(c Context, x *Vector, xlo int, y *Vector, ylo, n int) bool {
 	return true
 }
 
+// encodeDigit returns the quotient and the residue of b in radix a, as
+// for APL's encode: the residue has the sign of a, and a zero radix
+// takes all of b, leaving nothing for the radices before it.
+func encodeDigit(c Context, b, a Value) (quo, rem Value) {
+	quo, rem = QuoRem("encode", c, b, a)
+	// QuoRem's residue is never negative.
+	if sgn(c, a) < 0 && sgn(c, rem) != 0 {
+		rem = c.EvalBinary(rem, "+", a)
+		quo = c.EvalBinary(quo, "-", one)
+	}
+	return quo, rem
+}
+
 var BinaryOps = make(map[string]BinaryOp)
 
 func init() {
@@ -213,6 +247,31 @@ func init() {
 			},
 		},
 
+		{
+			name:        "root",
+			elementwise: true,
+			whichType:   binaryArithType,
+			fn: [numType]binaryFn{
+				intType:      root,
+				bigIntType:   root,
+				bigRatType:   root,
+				bigFloatType: root,
+				complexType:  root,
+			},
+		},
+
+		{
+			name:        "polar",
+			elementwise: true,
+			whichType:   binaryArithType,
+			fn: [numType]binaryFn{
+				intType:      polar,
+				bigIntType:   polar,
+				bigRatType:   polar,
+				bigFloatType: polar,
+			},
+		},
+
 		{
 			name:        "+",
 			elementwise: true,
@@ -429,6 +488,19 @@ func init() {
 			},
 		},
 
+		{
+			name:      "divmod",
+			whichType: binaryArithType,
+			fn: [numType]binaryFn{
+				intType:      divmod,
+				bigIntType:   divmod,
+				bigRatType:   divmod,
+				bigFloatType: divmod,
+				vectorType:   divmod,
+				matrixType:   divmod,
+			},
+		},
+
 		{
 			name:        "**",
 			elementwise: true,
@@ -525,6 +597,9 @@ func init() {
 					bFac.Div(bFac, bMinusAFac)
 					return BigInt{bFac}.shrink()
 				},
+				bigRatType:   binomial,
+				bigFloatType: binomial,
+				complexType:  binomial,
 			},
 		},
 
@@ -605,7 +680,16 @@ func init() {
 					z.Lsh(i.Int, shiftCount(j))
 					return z.shrink()
 				},
-				// TODO: lsh for bigfloat
+				bigRatType: func(c Context, u, v Value) Value {
+					r := u.(BigRat)
+					num := bigInt64(0)
+					num.Lsh(r.Num(), shiftCount(v))
+					return BigRat{bigRatInt64(0).SetFrac(num.Int, r.Denom())}.shrink()
+				},
+				bigFloatType: func(c Context, u, v Value) Value {
+					f := u.(BigFloat)
+					return BigFloat{newFloat(c).SetMantExp(f.Float, int(shiftCount(v)))}.shrink()
+				},
 			},
 		},
 
@@ -620,7 +704,74 @@ func init() {
 					z.Rsh(i.Int, shiftCount(j))
 					return z.shrink()
 				},
-				// TODO: rsh for bigfloat
+				// Unlike integers, rationals and floats shift exactly,
+				// by division by a power of two.
+				bigRatType: func(c Context, u, v Value) Value {
+					r := u.(BigRat)
+					den := bigInt64(0)
+					den.Lsh(r.Denom(), shiftCount(v))
+					return BigRat{bigRatInt64(0).SetFrac(r.Num(), den.Int)}.shrink()
+				},
+				bigFloatType: func(c Context, u, v Value) Value {
+					f := u.(BigFloat)
+					return BigFloat{newFloat(c).SetMantExp(f.Float, -int(shiftCount(v)))}.shrink()
+				},
+			},
+		},
+
+		{
+			name:        "getbit",
+			elementwise: true,
+			whichType:   divType, // Like shifts, let BigInt do the work.
+			fn: [numType]binaryFn{
+				bigIntType: getbit,
+			},
+		},
+
+		{
+			name:        "setbit",
+			elementwise: true,
+			whichType:   divType, // Like shifts, let BigInt do the work.
+			fn: [numType]binaryFn{
+				bigIntType: setbit,
+			},
+		},
+
+		{
+			name:        "invmod",
+			elementwise: true,
+			whichType:   divType, // Let BigInt do the work.
+			fn: [numType]binaryFn{
+				bigIntType: invmod,
+			},
+		},
+
+		{
+			name:      "powmod",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				vectorType: powmod,
+			},
+		},
+
+		{
+			name:      "rotbits",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:    rotbits,
+				bigIntType: rotbits,
+				vectorType: rotbits,
+				matrixType: rotbits,
+			},
+		},
+
+		{
+			name:      "radix",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:    radix,
+				bigIntType: radix,
+				vectorType: radix,
 			},
 		},
 
@@ -938,6 +1089,7 @@ func init() {
 					if A > B {
 						Errorf("left operand larger than right in %d?%d", A, B)
 					}
+					checkElems(c, int64(B))
 					ints := c.Config().Random().Perm(int(B))
 					origin := c.Config().Origin()
 					res := newVectorEditor(int(A), nil)
@@ -1039,9 +1191,7 @@ func init() {
 					// and 2 2 encode 1 2 3 has 3 columns encoding 1 2 3 downwards:
 					// 0 1 1
 					// 1 0 1
-					// If they are negative the answers disagree with APL because
-					// of how modulo arithmetic works.
-					const op = "encode"
+					// A zero radix takes all that remains; see encodeDigit.
 					A, B := u.(*Vector), v.(*Vector)
 					if A.AllChars() {
 						// Special case for times.
@@ -1049,7 +1199,7 @@ func init() {
 					}
 					// Scalar.
 					if A.Len() == 1 && B.Len() == 1 {
-						_, rem := QuoRem(op, c, B.At(0), A.At(0))
+						_, rem := encodeDigit(c, B.At(0), A.At(0))
 						return rem
 					}
 					// Vector.
@@ -1058,7 +1208,7 @@ func init() {
 						elems := newVectorEditor(A.Len(), nil)
 						b := B.At(0)
 						for i := A.Len() - 1; i >= 0; i-- {
-							quo, rem := QuoRem(op, c, b, A.At(i))
+							quo, rem := encodeDigit(c, b, A.At(i))
 							elems.Set(i, rem)
 							b = quo
 						}
@@ -1069,7 +1219,7 @@ func init() {
 						elems := newVectorEditor(B.Len(), nil)
 						a := A.At(0)
 						for i := range B.All() {
-							_, rem := QuoRem(op, c, B.At(i), a)
+							_, rem := encodeDigit(c, B.At(i), a)
 							elems.Set(i, rem)
 						}
 						return elems.Publish()
@@ -1078,13 +1228,14 @@ func init() {
 					// 2 2 encode 1 2 3 has 3 columns encoding 1 2 3 downwards:
 					// 0 1 1
 					// 1 0 1
+					checkElems(c, int64(A.Len())*int64(B.Len()))
 					elems := newVectorEditor(A.Len()*B.Len(), nil)
 					shape := []int{A.Len(), B.Len()}
 					pfor(true, A.Len(), B.Len(), func(lo, hi int) {
 						for j := lo; j < hi; j++ {
 							b := B.At(j)
 							for i := A.Len() - 1; i >= 0; i-- {
-								quo, rem := QuoRem(op, c, b, A.At(i))
+								quo, rem := encodeDigit(c, b, A.At(i))
 								elems.Set(j+i*B.Len(), rem)
 								b = quo
 							}
@@ -1094,14 +1245,14 @@ func init() {
 				},
 				matrixType: func(c Context, u, v Value) Value {
 					A, B := u.(*Vector), v.(*Matrix)
+					checkElems(c, int64(A.Len())*int64(B.data.Len()))
 					elems := newVectorEditor(A.Len()*B.data.Len(), nil)
 					shape := append([]int{A.Len()}, B.Shape()...)
-					const op = "encode"
 					pfor(true, A.Len(), B.data.Len(), func(lo, hi int) {
 						for j := lo; j < hi; j++ {
 							b := B.data.At(j)
 							for i := A.Len() - 1; i >= 0; i-- {
-								quo, rem := QuoRem(op, c, b, A.At(i))
+								quo, rem := encodeDigit(c, b, A.At(i))
 								elems.Set(j+i*B.data.Len(), rem)
 								b = quo
 							}
@@ -1131,6 +1282,35 @@ func init() {
 			},
 		},
 
+		{
+			name: "countin",
+			// A countin B: the number of times each element of A appears in B.
+			whichType: atLeastVectorType,
+			fn: [numType]binaryFn{
+				vectorType: func(c Context, u, v Value) Value {
+					return countIn(c, u.(*Vector), v.(*Vector)).shrink()
+				},
+				matrixType: func(c Context, u, v Value) Value {
+					m := u.(*Matrix)
+					data := countIn(c, m.data, v.(*Matrix).data)
+					if m.Rank() <= 1 {
+						return data.shrink()
+					}
+					return NewMatrix(m.shape, data)
+				},
+			},
+		},
+
+		{
+			name:      "pio",
+			whichType: atLeastVectorType,
+			fn: [numType]binaryFn{
+				vectorType: func(c Context, u, v Value) Value {
+					return progressiveIndex(c, u.(*Vector), v.(*Vector))
+				},
+			},
+		},
+
 		{
 			name:      "iota",
 			whichType: atLeastVectorType,
@@ -1138,11 +1318,14 @@ func init() {
 				vectorType: func(c Context, u, v Value) Value {
 					// A⍳B: The location (index) of B in A; 0 if not found. (APL does 1+⌈/⍳⍴A)
 					A, B := u.(*Vector), v.(*Vector)
+					origin := c.Config().Origin()
+					if pos, ok := hashFind(c, A, B, 1); ok {
+						return positions(pos, origin)
+					}
 					type indexed struct {
 						v     Value
 						index int
 					}
-					origin := c.Config().Origin()
 					sortedA := make([]indexed, A.Len())
 					for i, a := range A.All() {
 						sortedA[i] = indexed{a, i + origin}
@@ -1172,29 +1355,35 @@ func init() {
 					if A.Rank()-1 > B.Rank() || !sameShape(A.shape[1:], B.shape[B.Rank()-(A.Rank()-1):]) {
 						Errorf("iota: mismatched shapes %s and %s", NewIntVector(A.shape...), NewIntVector(B.shape...))
 					}
-					// TODO: This is n^2. Use an algorithm similar to the Vector case, or perhaps use hashing.
-					// However, one of the n's is the dimension of a matrix, so it is likely to be small.
 					shape := B.shape[:B.Rank()-(A.Rank()-1)]
 					if len(shape) == 0 {
 						shape = []int{1}
 					}
 					n := A.data.Len() / A.shape[0] // elements in each comparison
-					indices := newVectorEditor(B.data.Len()/n, nil)
-					pfor(true, n, B.data.Len()/n, func(lo, hi int) {
-						for i := lo; i < hi; i++ {
-							indices.Set(i, Int(origin-1))
-							for j := 0; j < A.data.Len(); j += n {
-								if allEqual(c, A.data, j, B.data, i*n, n) {
-									indices.Set(i, Int(j/n+origin))
-									break
+					var indices *Vector
+					if pos, ok := hashFind(c, A.data, B.data, n); ok {
+						indices = positions(pos, origin)
+					} else {
+						// This is n^2, but one of the n's is the dimension
+						// of a matrix, so it is likely to be small.
+						edit := newVectorEditor(B.data.Len()/n, nil)
+						pfor(true, n, B.data.Len()/n, func(lo, hi int) {
+							for i := lo; i < hi; i++ {
+								edit.Set(i, Int(origin-1))
+								for j := 0; j < A.data.Len(); j += n {
+									if allEqual(c, A.data, j, B.data, i*n, n) {
+										edit.Set(i, Int(j/n+origin))
+										break
+									}
 								}
 							}
-						}
-					})
+						})
+						indices = edit.Publish()
+					}
 					if len(shape) == 1 {
-						return indices.Publish()
+						return indices
 					}
-					return NewMatrix(shape, indices.Publish())
+					return NewMatrix(shape, indices)
 				},
 			},
 		},
@@ -1286,7 +1475,7 @@ func init() {
 			whichType: atLeastVectorType,
 			fn: [numType]binaryFn{
 				vectorType: func(c Context, u, v Value) Value {
-					return reshape(u.(*Vector), v.(*Vector))
+					return reshape(c, u.(*Vector), v.(*Vector))
 				},
 				matrixType: func(c Context, u, v Value) Value {
 					// LHS must be a vector underneath.
@@ -1294,7 +1483,7 @@ func init() {
 					if A.Rank() != 1 {
 						Errorf("lhs of rho cannot be matrix")
 					}
-					return reshape(A.data, B.data)
+					return reshape(c, A.data, B.data)
 				},
 			},
 		},
@@ -1304,11 +1493,14 @@ func init() {
 			whichType: atLeastVectorType,
 			fn: [numType]binaryFn{
 				vectorType: func(c Context, u, v Value) Value {
-					uu := u.(*Vector)
-					return NewVectorSeq(uu.All(), v.(*Vector).All())
+					uu, vv := u.(*Vector), v.(*Vector)
+					checkElems(c, int64(uu.Len())+int64(vv.Len()))
+					return NewVectorSeq(uu.All(), vv.All())
 				},
 				matrixType: func(c Context, u, v Value) Value {
-					return u.(*Matrix).catenate(v.(*Matrix))
+					uu, vv := u.(*Matrix), v.(*Matrix)
+					checkElems(c, int64(uu.data.Len())+int64(vv.data.Len()))
+					return uu.catenate(vv)
 				},
 			},
 		},
@@ -1318,15 +1510,34 @@ func init() {
 			whichType: atLeastVectorType,
 			fn: [numType]binaryFn{
 				vectorType: func(c Context, u, v Value) Value {
-					uu := u.(*Vector)
-					return NewVectorSeq(uu.All(), v.(*Vector).All())
+					uu, vv := u.(*Vector), v.(*Vector)
+					checkElems(c, int64(uu.Len())+int64(vv.Len()))
+					return NewVectorSeq(uu.All(), vv.All())
 				},
 				matrixType: func(c Context, u, v Value) Value {
-					return u.(*Matrix).catenateFirst(v.(*Matrix))
+					uu, vv := u.(*Matrix), v.(*Matrix)
+					checkElems(c, int64(uu.data.Len())+int64(vv.data.Len()))
+					return uu.catenateFirst(vv)
 				},
 			},
 		},
 
+		{
+			name:      "lam",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      laminate,
+				charType:     laminate,
+				bigIntType:   laminate,
+				bigRatType:   laminate,
+				bigFloatType: laminate,
+				complexType:  laminate,
+				timeType:     laminate,
+				vectorType:   laminate,
+				matrixType:   laminate,
+			},
+		},
+
 		{
 			name:      "take",
 			whichType: vectorAndAtLeastVectorType,
@@ -1344,6 +1555,7 @@ func init() {
 					if n < 0 {
 						nElems = -nElems
 					}
+					checkElems(c, int64(nElems))
 					fill := vv.fillValue()
 					switch {
 					case n < 0:
@@ -1447,60 +1659,14 @@ func init() {
 			whichType: atLeastVectorType,
 			fn: [numType]binaryFn{
 				vectorType: func(c Context, u, v Value) Value {
-					i := u.(*Vector)
-					j := v.(*Vector)
-					if i.Len() == 0 {
-						return empty
-					}
-					// All lhs values must be small integers.
-					var count int64
-					numLeft := 0
-					for _, x := range i.All() {
-						y, ok := x.(Int)
-						if !ok {
-							Errorf("fill: left operand must be small integers")
-						}
-						switch {
-						case y == 0:
-							count++
-						case y < 0:
-							count -= int64(y)
-						default:
-							numLeft++
-							count += int64(y)
-						}
-					}
-					if numLeft != j.Len() {
-						Errorf("fill: count > 0 on left (%d) must equal length of right (%d)", numLeft, j.Len())
-					}
-					if count > 1e8 {
-						Errorf("fill: result too large: %d elements", count)
-					}
-					result := newVectorEditor(0, nil)
-					jx := 0
-					var zeroVal Value
-					if j.AllChars() {
-						zeroVal = Char(' ')
-					} else {
-						zeroVal = zero
-					}
-					for _, x := range i.All() {
-						y := x.(Int)
-						switch {
-						case y == 0:
-							result.Append(zeroVal)
-						case y < 0:
-							for y = -y; y > 0; y-- {
-								result.Append(zeroVal)
-							}
-						default:
-							for ; y > 0; y-- {
-								result.Append(j.At(jx))
-							}
-							jx++
-						}
+					return v.(*Vector).fill(c, u.(*Vector))
+				},
+				matrixType: func(c Context, u, v Value) Value {
+					count, m := u.(*Matrix), v.(*Matrix)
+					if len(count.shape) != 1 {
+						Errorf("fill count cannot be matrix")
 					}
-					return result.Publish()
+					return m.fill(c, count.data)
 				},
 			},
 		},
@@ -1511,14 +1677,17 @@ func init() {
 			fn: [numType]binaryFn{
 				vectorType: func(c Context, u, v Value) Value {
 					countV, data := u.(*Vector), v.(*Vector)
-					return data.sel(countV, data.Len())
+					return data.sel(c, countV, data.Len())
 				},
 				matrixType: func(c Context, u, v Value) Value {
 					count, m := u.(*Matrix), v.(*Matrix)
 					if len(count.shape) != 1 {
-						Errorf("sel count cannot be matrix")
+						if slices.Equal(count.shape, m.shape) {
+							return m.selEach(c, count)
+						}
+						Errorf("sel: count matrix shape %s does not match %s", NewIntVector(count.shape...), NewIntVector(m.shape...))
 					}
-					result := m.data.sel(count.data, m.shape[len(m.shape)-1])
+					result := m.data.sel(c, count.data, m.shape[len(m.shape)-1])
 					newShape := make([]int, len(m.shape))
 					copy(newShape, m.shape)
 					newShape[len(m.shape)-1] = result.Len() / size(m.shape[:len(m.shape)-1])
@@ -1527,6 +1696,27 @@ func init() {
 			},
 		},
 
+		{
+			name:      "where",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				vectorType: func(c Context, u, v Value) Value {
+					data, ok := u.(*Vector)
+					if !ok {
+						Errorf("where: left operand must be a vector")
+					}
+					return data.where(c, v.(*Vector))
+				},
+				matrixType: func(c Context, u, v Value) Value {
+					m, ok := u.(*Matrix)
+					if !ok {
+						Errorf("where: left operand must be a matrix")
+					}
+					return m.where(c, v.(*Matrix))
+				},
+			},
+		},
+
 		{
 			name:      "part",
 			whichType: atLeastVectorType,
@@ -1565,6 +1755,7 @@ func init() {
 				bigRatType:   equal,
 				bigFloatType: equal,
 				complexType:  equal,
+				timeType:     equal,
 				vectorType:   equal,
 				matrixType:   equal,
 			},
@@ -1580,11 +1771,55 @@ func init() {
 				bigRatType:   notEqual,
 				bigFloatType: notEqual,
 				complexType:  notEqual,
+				timeType:     notEqual,
 				vectorType:   notEqual,
 				matrixType:   notEqual,
 			},
 		},
 
+		{
+			name:        "cmp",
+			elementwise: true,
+			whichType:   compareType,
+			fn: [numType]binaryFn{
+				intType:      threeWay,
+				charType:     threeWay,
+				bigIntType:   threeWay,
+				bigRatType:   threeWay,
+				bigFloatType: threeWay,
+				complexType:  threeWay,
+				timeType:     threeWay,
+			},
+		},
+
+		{
+			name:      "roll",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:    roll,
+				charType:   roll,
+				bigIntType: roll,
+				vectorType: roll,
+				matrixType: roll,
+			},
+		},
+
+		{
+			name:      "expect",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      expect,
+				charType:     expect,
+				bigIntType:   expect,
+				bigRatType:   expect,
+				bigFloatType: expect,
+				complexType:  expect,
+				timeType:     expect,
+				vectorType:   expect,
+				matrixType:   expect,
+			},
+		},
+
 		{
 			name:      "intersect",
 			whichType: noPromoteType,
@@ -1595,6 +1830,7 @@ func init() {
 				bigRatType:   intersect,
 				bigFloatType: intersect,
 				complexType:  intersect,
+				timeType:     intersect,
 				vectorType:   intersect,
 			},
 		},
@@ -1609,10 +1845,303 @@ func init() {
 				bigRatType:   union,
 				bigFloatType: union,
 				complexType:  union,
+				timeType:     union,
 				vectorType:   union,
 			},
 		},
 
+		{
+			name:      "unique",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      uniqueWithin,
+				bigIntType:   uniqueWithin,
+				bigRatType:   uniqueWithin,
+				bigFloatType: uniqueWithin,
+				vectorType:   uniqueWithin,
+			},
+		},
+
+		{
+			name:      "hist",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      hist,
+				bigIntType:   hist,
+				bigRatType:   hist,
+				bigFloatType: hist,
+				vectorType:   hist,
+				matrixType:   hist,
+			},
+		},
+
+		{
+			name:      "interp",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      interp,
+				bigIntType:   interp,
+				bigRatType:   interp,
+				bigFloatType: interp,
+				vectorType:   interp,
+				matrixType:   interp,
+			},
+		},
+
+		{
+			name:      "findroot",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				vectorType: findRoot,
+			},
+		},
+
+		{
+			name:      "minimize",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				vectorType: minimize,
+			},
+		},
+
+		{
+			name:      "integrate",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				vectorType: integrate,
+			},
+		},
+
+		{
+			name:      "deriv",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      deriv,
+				bigIntType:   deriv,
+				bigRatType:   deriv,
+				bigFloatType: deriv,
+			},
+		},
+
+		{
+			name:      "recurrence",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:    recurrence,
+				vectorType: recurrence,
+			},
+		},
+
+		{
+			name:      "mpow",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType: mpow,
+			},
+		},
+
+		{
+			name:      "cross",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      cross,
+				charType:     cross,
+				bigIntType:   cross,
+				bigRatType:   cross,
+				bigFloatType: cross,
+				complexType:  cross,
+				timeType:     cross,
+				vectorType:   cross,
+				matrixType:   cross,
+			},
+		},
+
+		{
+			name:      "without",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      without,
+				charType:     without,
+				bigIntType:   without,
+				bigRatType:   without,
+				bigFloatType: without,
+				complexType:  without,
+				timeType:     without,
+				vectorType:   without,
+			},
+		},
+
+		{
+			name:      "interval",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      interval,
+				charType:     interval,
+				bigIntType:   interval,
+				bigRatType:   interval,
+				bigFloatType: interval,
+				complexType:  interval,
+				timeType:     interval,
+				vectorType:   interval,
+				matrixType:   interval,
+			},
+		},
+
+		{
+			name:      "sort",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      sortKeyUp,
+				charType:     sortKeyUp,
+				bigIntType:   sortKeyUp,
+				bigRatType:   sortKeyUp,
+				bigFloatType: sortKeyUp,
+				complexType:  sortKeyUp,
+				timeType:     sortKeyUp,
+				vectorType:   sortKeyUp,
+				matrixType:   sortKeyUp,
+			},
+		},
+
+		{
+			name:      "rsort",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      sortKeyDown,
+				charType:     sortKeyDown,
+				bigIntType:   sortKeyDown,
+				bigRatType:   sortKeyDown,
+				bigFloatType: sortKeyDown,
+				complexType:  sortKeyDown,
+				timeType:     sortKeyDown,
+				vectorType:   sortKeyDown,
+				matrixType:   sortKeyDown,
+			},
+		},
+
+		{
+			name:      "topk",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      topk,
+				charType:     topk,
+				bigIntType:   topk,
+				bigRatType:   topk,
+				bigFloatType: topk,
+				complexType:  topk,
+				timeType:     topk,
+				vectorType:   topk,
+				matrixType:   topk,
+			},
+		},
+
+		{
+			name:      "group",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      group,
+				charType:     group,
+				bigIntType:   group,
+				bigRatType:   group,
+				bigFloatType: group,
+				complexType:  group,
+				timeType:     group,
+				vectorType:   group,
+				matrixType:   group,
+			},
+		},
+
+		{
+			name:      "first",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      firstN,
+				charType:     firstN,
+				bigIntType:   firstN,
+				bigRatType:   firstN,
+				bigFloatType: firstN,
+				complexType:  firstN,
+				timeType:     firstN,
+				vectorType:   firstN,
+				matrixType:   firstN,
+			},
+		},
+
+		{
+			name:      "split",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      splitN,
+				charType:     splitN,
+				bigIntType:   splitN,
+				bigRatType:   splitN,
+				bigFloatType: splitN,
+				complexType:  splitN,
+				timeType:     splitN,
+				vectorType:   splitN,
+				matrixType:   splitN,
+			},
+		},
+
+		{
+			name:        "addmonths",
+			elementwise: true,
+			whichType:   binaryArithType,
+			// Scalar times are handled by calendarOps.
+		},
+
+		{
+			name:        "addyears",
+			elementwise: true,
+			whichType:   binaryArithType,
+		},
+
+		{
+			name:        "busdays",
+			elementwise: true,
+			whichType:   binaryArithType,
+		},
+
+		{
+			name:      "todates",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				timeType: todates,
+			},
+		},
+
+		{
+			name:      "find",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      find,
+				charType:     find,
+				bigIntType:   find,
+				bigRatType:   find,
+				bigFloatType: find,
+				complexType:  find,
+				timeType:     find,
+				vectorType:   find,
+				matrixType:   find,
+			},
+		},
+
+		{
+			name:      "fields",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      fields,
+				charType:     fields,
+				bigIntType:   fields,
+				bigRatType:   fields,
+				bigFloatType: fields,
+				complexType:  fields,
+				timeType:     fields,
+				vectorType:   fields,
+				matrixType:   fields,
+			},
+		},
+
 		{
 			name:      "text",
 			whichType: noPromoteType,
@@ -1623,10 +2152,74 @@ func init() {
 				bigRatType:   fmtText,
 				bigFloatType: fmtText,
 				complexType:  fmtText,
+				timeType:     fmtText,
 				vectorType:   fmtText,
 				matrixType:   fmtText,
 			},
 		},
+
+		{
+			name:      "export",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      export,
+				charType:     export,
+				bigIntType:   export,
+				bigRatType:   export,
+				bigFloatType: export,
+				complexType:  export,
+				timeType:     export,
+				vectorType:   export,
+				matrixType:   export,
+			},
+		},
+
+		{
+			name:      "ifelse",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				vectorType: ifElse,
+			},
+		},
+
+		{
+			name:      "&&",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      condAnd,
+				charType:     condAnd,
+				bigIntType:   condAnd,
+				bigRatType:   condAnd,
+				bigFloatType: condAnd,
+				complexType:  condAnd,
+				vectorType:   condAnd,
+				matrixType:   condAnd,
+			},
+		},
+
+		{
+			name:      "||",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      condOr,
+				charType:     condOr,
+				bigIntType:   condOr,
+				bigRatType:   condOr,
+				bigFloatType: condOr,
+				complexType:  condOr,
+				vectorType:   condOr,
+				matrixType:   condOr,
+			},
+		},
+
+		{
+			name:      "plot",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				vectorType: plotXY,
+				matrixType: plotXY,
+			},
+		},
 	}
 
 	for _, op := range ops {
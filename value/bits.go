@@ -0,0 +1,130 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// Bit-level operations on integers of any size. Negative integers
+// behave as if in two's complement with infinitely many leading ones,
+// as for the other bitwise operators.
+
+// popcount returns the number of 1 bits in the non-negative integer v.
+func popcount(c Context, v Value) Value {
+	switch v := v.(type) {
+	case Int:
+		if v < 0 {
+			Errorf("popcount: negative value %d", v)
+		}
+		return Int(bits.OnesCount64(uint64(v)))
+	case BigInt:
+		if v.Sign() < 0 {
+			Errorf("popcount: negative value %s", v.Int)
+		}
+		n := 0
+		for _, w := range v.Bits() {
+			n += bits.OnesCount(uint(w))
+		}
+		return Int(n)
+	}
+	Errorf("popcount: non-integer value")
+	panic("not reached")
+}
+
+// bitlen returns the number of bits needed to represent the absolute value of v.
+func bitlen(c Context, v Value) Value {
+	switch v := v.(type) {
+	case Int:
+		if v < 0 {
+			v = -v
+		}
+		return Int(bits.Len64(uint64(v)))
+	case BigInt:
+		return Int(v.BitLen())
+	}
+	Errorf("bitlen: non-integer value")
+	panic("not reached")
+}
+
+// bitIndex returns the bit number held in v, which has been promoted to BigInt.
+func bitIndex(op string, v Value) int {
+	i := v.(BigInt)
+	if !i.IsInt64() || i.Sign() < 0 || i.Int64() >= maxInt {
+		Errorf("%s: illegal bit number %s", op, i.Int)
+	}
+	return int(i.Int64())
+}
+
+// getbit implements A getbit B, bit number A of B, counting from 0 for the
+// least significant bit.
+func getbit(c Context, u, v Value) Value {
+	return Int(v.(BigInt).Bit(bitIndex("getbit", u)))
+}
+
+// setbit implements A setbit B, which is B with bit number A set to 1.
+func setbit(c Context, u, v Value) Value {
+	i := bitIndex("setbit", u)
+	mustFit(c.Config(), int64(i)+1)
+	z := new(big.Int).SetBit(v.(BigInt).Int, i, 1)
+	return BigInt{z}.shrink()
+}
+
+// rotbits implements (N W) rotbits B, which rotates the low W bits of each
+// element of B left by N bits, or right if N is negative. The elements of B
+// must be non-negative integers that fit in W bits.
+func rotbits(c Context, u, v Value) Value {
+	n, w := rotbitsArgs(u)
+	mustFit(c.Config(), int64(w))
+	var rot func(Value) Value
+	rot = func(x Value) Value {
+		var b *big.Int
+		switch x := x.(type) {
+		case Int:
+			b = big.NewInt(int64(x))
+		case BigInt:
+			b = x.Int
+		case *Vector:
+			result := newVectorEditor(x.Len(), nil)
+			for i, e := range x.All() {
+				result.Set(i, rot(e))
+			}
+			return result.Publish()
+		case *Matrix:
+			return NewMatrix(x.shape, rot(x.data).(*Vector))
+		default:
+			Errorf("rotbits: non-integer value %s", x)
+		}
+		if b.Sign() < 0 || b.BitLen() > w {
+			Errorf("rotbits: %s does not fit in %d bits", b, w)
+		}
+		if w == 0 {
+			return x
+		}
+		k := uint(((n % w) + w) % w)
+		mask := new(big.Int).Lsh(big.NewInt(1), uint(w))
+		mask.Sub(mask, big.NewInt(1))
+		hi := new(big.Int).Lsh(b, k)
+		hi.And(hi, mask)
+		lo := new(big.Int).Rsh(b, uint(w)-k)
+		return BigInt{hi.Or(hi, lo)}.shrink()
+	}
+	return rot(v)
+}
+
+// rotbitsArgs returns the rotation count and width from the left operand of rotbits.
+func rotbitsArgs(u Value) (n, w int) {
+	vec, ok := u.(*Vector)
+	if !ok || vec.Len() != 2 {
+		Errorf("rotbits: left operand must be count and width")
+	}
+	count, ok1 := vec.At(0).(Int)
+	width, ok2 := vec.At(1).(Int)
+	if !ok1 || !ok2 || width < 0 {
+		Errorf("rotbits: left operand must be count and width")
+	}
+	return int(count), int(width)
+}
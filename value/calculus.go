@@ -0,0 +1,246 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math"
+	"math/big"
+)
+
+// Integration and differentiation of a unary op, named by text, in
+// floating point at the configured precision. Each refines its estimate
+// until successive estimates agree, and fails if they do not.
+
+const (
+	maxQuadLevel = 7 // Finest step of the quadrature is 2**-maxQuadLevel.
+	maxQuadDepth = 5 // Number of times an interval may be halved.
+)
+
+// A quadNode is a node of tanh-sinh quadrature on the interval -1 to 1,
+// for abscissa t. The node is at ±(1-r), with weight w.
+type quadNode struct {
+	w, r *big.Float
+}
+
+// A quadrature holds the state of an integration.
+type quadrature struct {
+	c      Context
+	op     string
+	tol    *big.Float
+	levels [][]quadNode // The nodes added at each level; computed as needed.
+}
+
+// integrate implements the binary integrate operator, integrating the
+// op u between the bounds v by tanh-sinh quadrature, halving the
+// interval where that does not converge.
+func integrate(c Context, u, v Value) Value {
+	op := opName("integrate", u)
+	lo, hi := bounds(c, "integrate", v)
+	sign := lo.Cmp(hi)
+	if sign == 0 {
+		return zero
+	}
+	if sign > 0 {
+		lo, hi = hi, lo
+	}
+	q := &quadrature{
+		c:   c,
+		op:  op,
+		tol: newFloat(c).SetMantExp(floatOne, -int(c.Config().FloatPrec()/2)),
+	}
+	z := q.integral(lo, hi, 0)
+	if sign > 0 {
+		z.Neg(z)
+	}
+	return BigFloat{z}.shrink()
+}
+
+// integral returns the integral of the op from a to b.
+func (q *quadrature) integral(a, b *big.Float, depth int) *big.Float {
+	if z, ok := q.estimate(a, b); ok {
+		return z
+	}
+	if depth == maxQuadDepth {
+		Errorf("integrate: %s does not converge between %s and %s", q.op, BigFloat{a}, BigFloat{b})
+	}
+	mid := newFloat(q.c).Add(a, b)
+	mid.Quo(mid, floatTwo)
+	z := q.integral(a, mid, depth+1)
+	return z.Add(z, q.integral(mid, b, depth+1))
+}
+
+// estimate returns the integral of the op from a to b, refining the
+// step until successive estimates agree. The boolean reports whether
+// they did.
+func (q *quadrature) estimate(a, b *big.Float) (*big.Float, bool) {
+	c := q.c
+	d := newFloat(c).Sub(b, a)
+	d.Quo(d, floatTwo)
+	mid := newFloat(c).Add(a, d)
+	f := func(x *big.Float) *big.Float {
+		return newFloat(c).Set(floatSelf(c, apply(c, "integrate", q.op, x)).Float)
+	}
+	// The sum of the weighted values of the op; the estimate is d*h*sum.
+	sum := newFloat(c).Mul(floatPiBy2, f(mid))
+	var prev *big.Float
+	for level := 0; level <= maxQuadLevel; level++ {
+		for _, n := range q.nodes(level) {
+			off := newFloat(c).Mul(d, n.r)
+			left := newFloat(c).Add(a, off)
+			right := newFloat(c).Sub(b, off)
+			// Points that round to the bounds are too close to them to evaluate.
+			y := newFloat(c)
+			if left.Cmp(a) != 0 {
+				y.Add(y, f(left))
+			}
+			if right.Cmp(b) != 0 {
+				y.Add(y, f(right))
+			}
+			sum.Add(sum, y.Mul(y, n.w))
+		}
+		z := newFloat(c).Mul(d, sum)
+		z.SetMantExp(z, -level)
+		if prev != nil && level >= 2 {
+			diff := newFloat(c).Sub(z, prev)
+			scale := newFloat(c).Abs(z)
+			if scale.Cmp(floatOne) < 0 {
+				scale.Set(floatOne)
+			}
+			if diff.Abs(diff).Cmp(scale.Mul(scale, q.tol)) <= 0 {
+				return z, true
+			}
+		}
+		prev = z
+	}
+	return prev, false
+}
+
+// nodes returns the nodes added at the level, those whose abscissas
+// are the odd multiples of 2**-level, or at level 0 the positive integers.
+// Nodes too close to ±1 to matter are omitted.
+func (q *quadrature) nodes(level int) []quadNode {
+	for len(q.levels) <= level {
+		q.levels = append(q.levels, q.newNodes(len(q.levels)))
+	}
+	return q.levels[level]
+}
+
+// newNodes computes the nodes for the level.
+func (q *quadrature) newNodes(level int) []quadNode {
+	c := q.c
+	prec := c.Config().FloatPrec()
+	// Beyond tMax, r is below 2**-2prec.
+	tMax := math.Asinh(2 * float64(prec) * math.Ln2 / math.Pi)
+	step := 1
+	if level > 0 {
+		step = 2
+	}
+	var nodes []quadNode
+	for k := 1; ; k += step {
+		t := newFloat(c).SetMantExp(newFloat(c).SetInt64(int64(k)), -level)
+		if tf, _ := t.Float64(); tf > tMax {
+			break
+		}
+		// u = π/2 sinh t; the node is at tanh u, that is 1-r where
+		// r = 2/(e**2u+1), with weight π/2 cosh t / cosh**2 u.
+		et := bigExp(c, t)
+		inv := newFloat(c).Quo(floatOne, et)
+		sh := newFloat(c).Sub(et, inv)
+		ch := newFloat(c).Add(et, inv)
+		sh.Quo(sh, floatTwo)
+		ch.Quo(ch, floatTwo)
+		twoU := sh.Mul(sh, floatPiBy2)
+		twoU.Mul(twoU, floatTwo)
+		e2u := bigExp(c, twoU)
+		denom := newFloat(c).Add(e2u, floatOne)
+		r := newFloat(c).Quo(floatTwo, denom)
+		// w = 2π cosh t e**2u / (e**2u+1)**2.
+		w := ch.Mul(ch, floatPiBy2)
+		w.Mul(w, r)
+		w.Mul(w, r)
+		w.Mul(w, e2u)
+		nodes = append(nodes, quadNode{w: w, r: r})
+	}
+	return nodes
+}
+
+// bigExp returns e**x, halving x until it is small enough for the
+// Taylor series to converge quickly and squaring the result back.
+func bigExp(c Context, x *big.Float) *big.Float {
+	n := max(0, x.MantExp(nil))
+	z := exponential(c.Config(), newFloat(c).SetMantExp(x, -n))
+	for range n {
+		z.Mul(z, z)
+	}
+	return z
+}
+
+// deriv implements the binary deriv operator, differentiating the op u
+// at v by Ridders' method: central differences at shrinking steps,
+// extrapolated to a step of zero, stopping when the estimated error
+// no longer falls.
+func deriv(c Context, u, v Value) Value {
+	op := opName("deriv", u)
+	x := floatSelf(c, v).Float
+	prec := c.Config().FloatPrec()
+	// The initial step is x/16, or 1/16 if x is zero.
+	h := newFloat(c).Abs(x)
+	if h.Sign() == 0 {
+		h.Set(floatOne)
+	}
+	h.SetMantExp(h, -4)
+	central := func(h *big.Float) *big.Float {
+		y := newFloat(c).Set(floatSelf(c, apply(c, "deriv", op, newFloat(c).Add(x, h))).Float)
+		y.Sub(y, floatSelf(c, apply(c, "deriv", op, newFloat(c).Sub(x, h))).Float)
+		y.Quo(y, h)
+		return y.Quo(y, floatTwo)
+	}
+	// Each row of the tableau starts with the central difference at
+	// half the step of the row before. Each further entry combines
+	// the entries to its left and above to remove the next term of
+	// the error, in h**2, then h**4, and so on.
+	levels := 10 + int(prec)/8
+	var best, err *big.Float
+	var prev []*big.Float
+	for i := range levels {
+		row := []*big.Float{central(h)}
+		fac := newFloat(c).SetInt64(4)
+		for j := 1; j <= i; j++ {
+			// (row[j-1]*fac - prev[j-1]) / (fac-1).
+			z := newFloat(c).Mul(row[j-1], fac)
+			z.Sub(z, prev[j-1])
+			z.Quo(z, newFloat(c).Sub(fac, floatOne))
+			row = append(row, z)
+			fac.Mul(fac, big.NewFloat(4))
+			e1 := newFloat(c).Sub(z, row[j-1])
+			e2 := newFloat(c).Sub(z, prev[j-1])
+			e := e1.Abs(e1)
+			if e2.Abs(e2).Cmp(e) > 0 {
+				e = e2
+			}
+			if err == nil || e.Cmp(err) <= 0 {
+				best, err = z, e
+			}
+		}
+		if i > 0 {
+			// Stop if the error of the extrapolation has grown.
+			e := newFloat(c).Sub(row[i], prev[i-1])
+			if err != nil && e.Abs(e).Cmp(newFloat(c).Mul(err, floatTwo)) >= 0 {
+				break
+			}
+		}
+		prev = row
+		h = newFloat(c).SetMantExp(h, -1)
+	}
+	// The error must be small compared to the result.
+	scale := newFloat(c).Abs(best)
+	if scale.Cmp(floatOne) < 0 {
+		scale.Set(floatOne)
+	}
+	if err.Cmp(scale.SetMantExp(scale, -int(prec)/4)) > 0 {
+		Errorf("deriv: %s does not converge at %s", op, v)
+	}
+	return BigFloat{best}.shrink()
+}
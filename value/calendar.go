@@ -0,0 +1,100 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import "time"
+
+// Calendar operations on times. They work on dates in the time zone
+// set by )timezone, so for instance the weekday of a time is the
+// weekday where the user is.
+
+// calendarOps holds the elementwise binary operators on a time and
+// another scalar, apart from arithmetic and comparison.
+var calendarOps = map[string]binaryFn{
+	"addmonths": func(c Context, u, v Value) Value { return addMonths(c, "addmonths", u, v, 1) },
+	"addyears":  func(c Context, u, v Value) Value { return addMonths(c, "addyears", u, v, 12) },
+	"busdays":   busdays,
+}
+
+// localTime returns the time value v in the configured time zone.
+func localTime(c Context, op string, v Value) time.Time {
+	t, ok := v.(Time)
+	if !ok {
+		Errorf("%s: %s is not a time", op, v.Sprint(c.Config()))
+	}
+	return t.In(c.Config().LocationAt(t.Time))
+}
+
+// weekday returns the day of the week of v: 0 for Sunday through 6 for Saturday.
+func weekday(c Context, v Value) Value {
+	return Int(localTime(c, "weekday", v).Weekday())
+}
+
+// addMonths implements T addmonths N and T addyears N, which advance the date
+// of T by N calendar months or years, keeping the time of day. If the day of the
+// month does not exist in the new month, the result is the last day of that
+// month, so 2024.01.31 addmonths 1 is 2024.02.29.
+func addMonths(c Context, op string, u, v Value, scale int) Value {
+	t := localTime(c, op, u)
+	n, ok := v.(Int)
+	if !ok {
+		Errorf("%s: count must be small integer", op)
+	}
+	y, m, d := t.Date()
+	m += time.Month(int(n) * scale)
+	// Day 0 of the following month is the last day of month m.
+	last := time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	t = time.Date(y, m, min(d, last), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	return Time{t.In(c.Config().LocationAt(t))}
+}
+
+// dayNumber returns the number of days from 1970.01.01 to the date of t.
+func dayNumber(t time.Time) int {
+	y, m, d := t.Date()
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// busdays implements A busdays B, the number of business days, Monday through
+// Friday, from the date of A up to but not including the date of B. If B is
+// before A, the count is negative.
+func busdays(c Context, u, v Value) Value {
+	from := dayNumber(localTime(c, "busdays", u))
+	to := dayNumber(localTime(c, "busdays", v))
+	sign := 1
+	if to < from {
+		from, to = to, from
+		sign = -1
+	}
+	// Day 0, 1970.01.01, was a Thursday. Count whole weeks, then the rest.
+	n := (to - from) / 7 * 5
+	for day := from + (to-from)/7*7; day < to; day++ {
+		if wd := (day%7 + 7 + 4) % 7; wd != 0 && wd != 6 {
+			n++
+		}
+	}
+	return Int(sign * n)
+}
+
+// todates implements A todates B, the vector of times one day apart from A
+// through B, or down to B if B is before A.
+func todates(c Context, u, v Value) Value {
+	start := localTime(c, "todates", u)
+	end := localTime(c, "todates", v)
+	step := 1
+	if end.Before(start) {
+		step = -1
+	}
+	n := step*(dayNumber(end)-dayNumber(start)) + 1
+	if t := start.AddDate(0, 0, step*(n-1)); step*t.Compare(end) > 0 {
+		n-- // The time of day of B is before that of A.
+	}
+	checkElems(c, int64(n))
+	result := newVectorEditor(n, nil)
+	for i := range n {
+		t := start.AddDate(0, 0, step*i)
+		result.Set(i, Time{t.In(c.Config().LocationAt(t))})
+	}
+	return result.Publish()
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// String transformations: upper, lower, nfc, nfd. Each converts the text
+// as a whole, not char by char, so the result may differ in length from
+// the argument: upper 'ß' is 'SS', and nfd 'é' is 'e' followed by a
+// combining acute accent.
+
+func upper(c Context, v Value) Value {
+	return transformText("upper", v, cases.Upper(language.Und).String)
+}
+
+func lower(c Context, v Value) Value {
+	return transformText("lower", v, cases.Lower(language.Und).String)
+}
+
+func nfc(c Context, v Value) Value {
+	return transformText("nfc", v, norm.NFC.String)
+}
+
+func nfd(c Context, v Value) Value {
+	return transformText("nfd", v, norm.NFD.String)
+}
+
+// transformText applies fn to the text of v, which must be a char or a
+// vector of chars, or a vector whose elements are such values.
+func transformText(op string, v Value, fn func(string) string) Value {
+	switch v := v.(type) {
+	case Char:
+		return shrinkText(fn(string(rune(v))))
+	case *Vector:
+		if v.AllChars() {
+			return newCharVector(fn(vecText(v)))
+		}
+		result := newVectorEditor(v.Len(), nil)
+		for i, x := range v.All() {
+			result.Set(i, transformText(op, x, fn))
+		}
+		return result.Publish()
+	}
+	Errorf("%s: argument must be text", op)
+	panic("not reached")
+}
+
+// shrinkText returns s as a char if it is a single char, or as a char vector.
+func shrinkText(s string) Value {
+	text := newCharVector(s).(*Vector)
+	if text.Len() == 1 {
+		return text.At(0)
+	}
+	return text
+}
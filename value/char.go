@@ -22,6 +22,11 @@ func (c Char) Rank() int {
 	return 0
 }
 
+// Hash returns the hash of the char.
+func (c Char) Hash() (uint64, bool) {
+	return hashCombine(hashTagChar, uint64(c)), true
+}
+
 func (c Char) shrink() Value {
 	return c
 }
@@ -0,0 +1,89 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// Text encodings of byte data: hex and base64 turn bytes into text, and
+// unhex and unbase64 turn the text back into bytes. Bytes are integers
+// from 0 to 255; text given where bytes are expected stands for its UTF-8
+// encoding.
+
+func hexEncode(c Context, v Value) Value {
+	return newCharVector(hex.EncodeToString(toBytes("hex", v)))
+}
+
+func hexDecode(c Context, v Value) Value {
+	b, err := hex.DecodeString(textOf("unhex", v))
+	if err != nil {
+		Errorf("unhex: %v", err)
+	}
+	return fromBytes(b)
+}
+
+func base64Encode(c Context, v Value) Value {
+	return newCharVector(base64.StdEncoding.EncodeToString(toBytes("base64", v)))
+}
+
+func base64Decode(c Context, v Value) Value {
+	b, err := base64.StdEncoding.DecodeString(textOf("unbase64", v))
+	if err != nil {
+		Errorf("unbase64: %v", err)
+	}
+	return fromBytes(b)
+}
+
+// toBytes returns the bytes represented by v, a byte value or char
+// or a vector of them.
+func toBytes(op string, v Value) []byte {
+	var b []byte
+	switch v := v.(type) {
+	case Int:
+		if v < 0 || v > 255 {
+			Errorf("%s: %d is not a byte", op, v)
+		}
+		return append(b, byte(v))
+	case Char:
+		return append(b, string(rune(v))...)
+	case *Vector:
+		for _, x := range v.All() {
+			switch x.(type) {
+			case Int, Char:
+				b = append(b, toBytes(op, x)...)
+			default:
+				Errorf("%s: %s is not a byte", op, x)
+			}
+		}
+		return b
+	}
+	Errorf("%s: argument must be bytes or text", op)
+	panic("not reached")
+}
+
+// textOf returns the text held in v, a char or vector of chars.
+func textOf(op string, v Value) string {
+	switch v := v.(type) {
+	case Char:
+		return string(rune(v))
+	case *Vector:
+		if v.AllChars() {
+			return vecText(v)
+		}
+	}
+	Errorf("%s: argument must be text", op)
+	panic("not reached")
+}
+
+// fromBytes returns a vector of the byte values of b.
+func fromBytes(b []byte) Value {
+	result := newVectorEditor(len(b), nil)
+	for i, x := range b {
+		result.Set(i, Int(x))
+	}
+	return result.Publish()
+}
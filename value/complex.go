@@ -42,7 +42,17 @@ func (c Complex) Rank() int {
 	return 0
 }
 
+// Hash reports that a complex number has no hash, as its real part
+// may be a float.
+func (c Complex) Hash() (uint64, bool) {
+	return 0, false
+}
+
 func (c Complex) Sprint(conf *config.Config) string {
+	if conf.Polar() {
+		mag, phase := c.polar(conf)
+		return mag.Sprint(conf) + "∠" + phase.Sprint(conf)
+	}
 	return fmt.Sprintf("%sj%s", c.real.Sprint(conf), c.imag.Sprint(conf))
 }
 
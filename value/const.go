@@ -16,6 +16,7 @@ package value
 import (
 	"fmt"
 	"math/big"
+	"sync"
 
 	"robpike.io/ivy/config"
 )
@@ -85,11 +86,27 @@ func newFloat(c Context) *big.Float {
 	return newF(c.Config())
 }
 
+// constsLock guards the setting of the float constants, as a server
+// creates contexts concurrently.
+var constsLock sync.Mutex
+
 func Consts(c Context) (e, pi BigFloat) {
 	conf := c.Config()
 	if conf.FloatPrec() > constPrecisionInBits {
 		fmt.Fprintf(c.Config().ErrOutput(), "warning: precision too high; only have %d digits (%d bits) of precision for e and pi", constPrecisionInDigits, constPrecisionInBits)
 	}
+	constsLock.Lock()
+	defer constsLock.Unlock()
+	// Contexts in use may be reading the constants, so set them only
+	// if the precision has changed.
+	if floatOne == nil || floatOne.Prec() != conf.FloatPrec() {
+		setConsts(conf)
+	}
+	return BigFloat{newF(conf).Set(floatE)}, BigFloat{newF(conf).Set(floatPi)}
+}
+
+// setConsts sets the float constants to the configured precision.
+func setConsts(conf *config.Config) {
 	floatZero = newF(conf).SetInt64(0)
 	floatOne = newF(conf).SetInt64(1)
 	floatTwo = newF(conf).SetInt64(2)
@@ -118,7 +135,6 @@ func Consts(c Context) (e, pi BigFloat) {
 	if !ok {
 		panic("setting log(10)")
 	}
-	return BigFloat{newF(conf).Set(floatE)}, BigFloat{newF(conf).Set(floatPi)}
 }
 
 // -1/2i is remarkably hard to build.
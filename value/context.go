@@ -121,6 +121,18 @@ type Context interface {
 	// UserDefined reports whether the specified op is user-defined.
 	UserDefined(op string, isBinary bool) bool
 
+	// Symbols returns the sorted names of the global variables
+	// and user-defined ops.
+	Symbols() []string
+
+	// Pure reports whether the specified op, builtin or user-defined,
+	// has no side effects, so it may be evaluated in parallel.
+	Pure(op string, isBinary bool) bool
+
+	// Fork returns a context sharing the variables and ops of this one
+	// but with its own stack, for evaluating pure ops in another goroutine.
+	Fork() Context
+
 	// TraceIndent returns an indentation marker showing the depth of the stack.
 	TraceIndent() string
 }
@@ -0,0 +1,78 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+// cross implements the binary cross operator, the cartesian product of
+// u and v as a matrix whose rows are each tuple of u followed by each
+// tuple of v, the last changing fastest. A matrix holds a tuple in each
+// row; each element of a vector, or a scalar, is a tuple of one. Thus
+// a cross b cross c enumerates the triples of elements of a, b, and c.
+func cross(c Context, u, v Value) Value {
+	left, right := tuples(u), tuples(v)
+	width := left.width + right.width
+	checkElems(c, int64(left.n)*int64(right.n)*int64(width))
+	data := newVectorEditor(left.n*right.n*width, nil)
+	k := 0
+	for i := range left.n {
+		for j := range right.n {
+			for _, x := range left.tuple(i) {
+				data.Set(k, x)
+				k++
+			}
+			for _, x := range right.tuple(j) {
+				data.Set(k, x)
+				k++
+			}
+		}
+	}
+	return NewMatrix([]int{left.n * right.n, width}, data.Publish())
+}
+
+// crossAll implements the unary cross operator, the cartesian product
+// of the elements of v, which are combined as by binary cross.
+func crossAll(c Context, v Value) Value {
+	vec := v.(*Vector)
+	if vec.Len() == 0 {
+		Errorf("cross: empty argument")
+	}
+	z := vec.At(vec.Len() - 1)
+	if vec.Len() == 1 {
+		t := tuples(z)
+		return NewMatrix([]int{t.n, t.width}, t.data)
+	}
+	for i := vec.Len() - 2; i >= 0; i-- {
+		z = cross(c, vec.At(i), z)
+	}
+	return z
+}
+
+// A tupleList is the tuples of a value, one per row of data.
+type tupleList struct {
+	data     *Vector
+	n, width int
+}
+
+// tuples returns the tuples of v for cross.
+func tuples(v Value) tupleList {
+	switch v := v.(type) {
+	case *Vector:
+		return tupleList{v, v.Len(), 1}
+	case *Matrix:
+		if v.Rank() != 2 {
+			Errorf("cross: rank %d matrix", v.Rank())
+		}
+		return tupleList{v.data, v.shape[0], v.shape[1]}
+	}
+	return tupleList{oneElemVector(v), 1, 1}
+}
+
+// tuple returns the ith tuple.
+func (t tupleList) tuple(i int) []Value {
+	elems := make([]Value, t.width)
+	for j := range elems {
+		elems[j] = t.data.At(i*t.width + j)
+	}
+	return elems
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math/big"
+	"strings"
+)
+
+// decimal returns the exact decimal representation of the rational v as
+// text, with the repeating part of the fraction, if any, in parentheses:
+// decimal 1/7 is 0.(142857) and decimal 1/6 is 0.1(6). Integers and
+// fractions whose denominators have no prime factors other than 2 and 5
+// have no repeating part. If the fraction needs more than maxdigits digits,
+// it is truncated and ends in "...". A vector yields a vector of texts.
+func decimal(c Context, v Value) Value {
+	var r *big.Rat
+	switch v := v.(type) {
+	case Int:
+		r = big.NewRat(int64(v), 1)
+	case BigInt:
+		r = new(big.Rat).SetInt(v.Int)
+	case BigRat:
+		r = v.Rat
+	case *Vector:
+		result := newVectorEditor(v.Len(), nil)
+		for i, x := range v.All() {
+			result.Set(i, decimal(c, x))
+		}
+		return result.Publish()
+	default:
+		Errorf("decimal: value must be exact")
+	}
+	var b strings.Builder
+	if r.Sign() < 0 {
+		b.WriteByte('-')
+	}
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	b.WriteString(quo.String())
+	if rem.Sign() == 0 {
+		return newCharVector(b.String())
+	}
+	// Long division, remembering where each remainder first appeared.
+	// When one recurs, the digits since then repeat.
+	limit := int(c.Config().MaxDigits())
+	seen := make(map[string]int)
+	var digits []byte
+	ten := big.NewInt(10)
+	digit := new(big.Int)
+	for rem.Sign() != 0 {
+		key := rem.String()
+		if start, ok := seen[key]; ok {
+			b.WriteByte('.')
+			b.Write(digits[:start])
+			b.WriteByte('(')
+			b.Write(digits[start:])
+			b.WriteByte(')')
+			return newCharVector(b.String())
+		}
+		if len(digits) == limit {
+			b.WriteByte('.')
+			b.Write(digits)
+			b.WriteString("...")
+			return newCharVector(b.String())
+		}
+		seen[key] = len(digits)
+		rem.Mul(rem, ten)
+		digit.QuoRem(rem, den, rem)
+		digits = append(digits, byte('0'+digit.Int64()))
+	}
+	b.WriteByte('.')
+	b.Write(digits)
+	return newCharVector(b.String())
+}
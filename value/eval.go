@@ -9,6 +9,7 @@ import (
 	"iter"
 	"math/big"
 	"runtime"
+	"slices"
 	"strings"
 )
 
@@ -21,12 +22,13 @@ const (
 	bigRatType
 	bigFloatType
 	complexType
+	timeType
 	vectorType
 	matrixType
 	numType
 )
 
-var typeName = [...]string{"int", "char", "big int", "rational", "float", "complex", "vector", "matrix"}
+var typeName = [...]string{"int", "char", "big int", "rational", "float", "complex", "time", "vector", "matrix"}
 
 func (t valueType) String() string {
 	return typeName[t]
@@ -68,10 +70,17 @@ func (op *unaryOp) EvalUnary(c Context, v Value) Value {
 		}
 		Errorf("unary %s not implemented on type %s", op.name, which)
 	}
-	if c.Config().Tracing(2) {
+	conf := c.Config()
+	if conf.Tracing(2) {
 		fmt.Printf("\t%s> %s %s\n", c.TraceIndent(), op.name, v)
 	}
-	return fn(c, v)
+	if !op.elementwise || which >= vectorType {
+		return fn(c, v)
+	}
+	if conf.IEEE() {
+		return ieeeUnary(c, op, fn, v)
+	}
+	return checkOverflow(fn(c, v))
 }
 
 type binaryFn func(Context, Value, Value) Value
@@ -97,6 +106,8 @@ func whichType(v Value) valueType {
 		return bigFloatType
 	case Complex:
 		return complexType
+	case Time:
+		return timeType
 	case *Vector:
 		return vectorType
 	case *Matrix:
@@ -107,6 +118,9 @@ func whichType(v Value) valueType {
 }
 
 func (op *binaryOp) EvalBinary(c Context, u, v Value) Value {
+	if op.elementwise && isTimeScalars(u, v) {
+		return timeBinary(c, u, op.name, v)
+	}
 	whichU, whichV := op.whichType(whichType(u), whichType(v))
 	conf := c.Config()
 	u = u.toType(op.name, conf, whichU)
@@ -126,7 +140,19 @@ func (op *binaryOp) EvalBinary(c Context, u, v Value) Value {
 	if conf.Tracing(2) {
 		fmt.Printf("\t%s> %s %s %s\n", c.TraceIndent(), u, op.name, v)
 	}
-	return fn(c, u, v)
+	if !op.elementwise || whichV >= vectorType {
+		return fn(c, u, v)
+	}
+	if conf.IEEE() {
+		return ieeeBinary(c, op, fn, u, v)
+	}
+	return checkOverflow(fn(c, u, v))
+}
+
+// isTimeScalars reports whether u and v are scalars, at least one of which is a Time.
+func isTimeScalars(u, v Value) bool {
+	uType, vType := whichType(u), whichType(v)
+	return (uType == timeType || vType == timeType) && uType < vectorType && vType < vectorType
 }
 
 // EvalCharEqual handles == and != in a special case:
@@ -166,17 +192,64 @@ func Product(c Context, u Value, op string, v Value) Value {
 }
 
 // safeBinary reports whether the binary operator op is safe to parallelize.
-func safeBinary(op string) bool {
+// A user-defined op is safe if it is pure; see Context.Pure.
+func safeBinary(c Context, op string) bool {
+	if BinaryOps[op] == nil {
+		return c.Pure(op, true)
+	}
 	// ? uses the random number generator,
 	// which maintains global state.
-	return BinaryOps[op] != nil && op != "?"
+	return op != "?"
 }
 
 // safeUnary reports whether the unary operator op is safe to parallelize.
-func safeUnary(op string) bool {
+// A user-defined op is safe if it is pure; see Context.Pure.
+func safeUnary(c Context, op string) bool {
+	if UnaryOps[op] == nil {
+		return c.Pure(op, false)
+	}
 	// ? uses the random number generator,
 	// which maintains global state.
-	return UnaryOps[op] != nil && op != "?"
+	// On vectors and matrices it parallelizes itself; see rollVector.
+	return op != "?"
+}
+
+// impure lists the builtin operators with side effects: they use the
+// random number generator, which maintains global state, do I/O,
+// record or evaluate ivy state, or call an op given by name.
+// Ifelse is not listed: the branches it evaluates lazily are
+// expressions in the op's body, so the purity check sees them.
+var impure = map[string]bool{
+	"?":         true,
+	"rand":      true,
+	"roll":      true,
+	"sys":       true,
+	"print":     true,
+	"plot":      true,
+	"export":    true,
+	"expect":    true,
+	"ivy":       true,
+	"findroot":  true,
+	"minimize":  true,
+	"integrate": true,
+	"deriv":     true,
+}
+
+// Impure reports whether the builtin operator op has side effects,
+// which make a user-defined op that uses it impure.
+func Impure(op string) bool {
+	return impure[op]
+}
+
+// Elementwise reports whether the builtin op applies elementwise to
+// the elements of vectors and matrices.
+func Elementwise(op string, isBinary bool) bool {
+	if isBinary {
+		bop, ok := BinaryOps[op].(*binaryOp)
+		return ok && bop.elementwise
+	}
+	uop, ok := UnaryOps[op].(*unaryOp)
+	return ok && uop.elementwise
 }
 
 // knownAssoc reports whether the binary op is known to be associative.
@@ -234,6 +307,24 @@ func pfor(ok bool, size, n int, f func(lo, hi int)) {
 	}
 }
 
+// pforContext is pfor for loops that evaluate operators in c. Each range
+// run in its own goroutine is evaluated in a fork of c, as the stack
+// of a Context may not be shared. The first fork is made here, before
+// the goroutines start, so it can settle the state they share.
+func pforContext(c Context, ok bool, size, n int, f func(c Context, lo, hi int)) {
+	shared := c
+	if ok {
+		shared = c.Fork()
+	}
+	pfor(ok, size, n, func(lo, hi int) {
+		if lo == 0 && hi == n {
+			f(c, lo, hi)
+			return
+		}
+		f(shared.Fork(), lo, hi)
+	})
+}
+
 func sendRecover(c chan<- interface{}) {
 	c <- recover()
 }
@@ -249,6 +340,11 @@ func innerProduct(c Context, u Value, left, right string, v Value) Value {
 		if n == 0 {
 			Errorf("empty inner product")
 		}
+		if left == "+" && right == "*" && n > 1 && foldFloats(c, left) && foldFloats(c, right) {
+			if x := floatDot(c, n, u.At, v.At); x != nil {
+				return x
+			}
+		}
 		x := c.EvalBinary(u.At(n-1), right, v.At(n-1))
 		for k := n - 2; k >= 0; k-- {
 			x = c.EvalBinary(c.EvalBinary(u.At(k), right, v.At(k)), left, x)
@@ -265,11 +361,20 @@ func innerProduct(c Context, u Value, left, right string, v Value) Value {
 		}
 		n := v.shape[0]
 		vstride := v.data.Len() / n
+		checkElems(c, int64(u.data.Len()/n)*int64(vstride))
 		data := newVectorEditor(u.data.Len()/n*vstride, nil)
-		pfor(safeBinary(left) && safeBinary(right), 1, data.Len(), func(lo, hi int) {
+		dot := left == "+" && right == "*" && n > 1 && foldFloats(c, left) && foldFloats(c, right)
+		pforContext(c, safeBinary(c, left) && safeBinary(c, right), 1, data.Len(), func(c Context, lo, hi int) {
 			for x := lo; x < hi; x++ {
 				i := x / vstride * n
 				j := x % vstride
+				if dot {
+					acc := floatDot(c, n, func(k int) Value { return u.data.At(i + k) }, func(k int) Value { return v.data.At(j + k*vstride) })
+					if acc != nil {
+						data.Set(x, acc)
+						continue
+					}
+				}
 				acc := c.EvalBinary(u.data.At(i+n-1), right, v.data.At(j+(n-1)*vstride))
 				for k := n - 2; k >= 0; k-- {
 					acc = c.EvalBinary(c.EvalBinary(u.data.At(i+k), right, v.data.At(j+k*vstride)), left, acc)
@@ -296,8 +401,9 @@ func outerProduct(c Context, u Value, op string, v Value) Value {
 	switch u := u.(type) {
 	case *Vector:
 		v := v.(*Vector)
+		checkElems(c, int64(u.Len())*int64(v.Len()))
 		data := newVectorEditor(u.Len()*v.Len(), nil)
-		pfor(safeBinary(op), 1, data.Len(), func(lo, hi int) {
+		pforContext(c, safeBinary(c, op), 1, data.Len(), func(c Context, lo, hi int) {
 			for x := lo; x < hi; x++ {
 				data.Set(x, c.EvalBinary(u.At(x/v.Len()), op, v.At(x%v.Len())))
 			}
@@ -307,8 +413,9 @@ func outerProduct(c Context, u Value, op string, v Value) Value {
 		v := v.(*Matrix)
 		udata := u.Data()
 		vdata := v.Data()
+		checkElems(c, int64(udata.Len())*int64(vdata.Len()))
 		data := newVectorEditor(udata.Len()*vdata.Len(), nil)
-		pfor(safeBinary(op), 1, data.Len(), func(lo, hi int) {
+		pforContext(c, safeBinary(c, op), 1, data.Len(), func(c Context, lo, hi int) {
 			for x := lo; x < hi; x++ {
 				data.Set(x, c.EvalBinary(udata.At(x/vdata.Len()), op, vdata.At(x%vdata.Len())))
 			}
@@ -319,6 +426,69 @@ func outerProduct(c Context, u Value, op string, v Value) Value {
 	panic("not reached")
 }
 
+// foldFloats reports whether a reduction or inner product may
+// accumulate with the builtin op in a pooled big.Float rather than
+// allocate a value for every step. It may for + and * if nothing
+// observes the steps: the op is not user-defined, nothing is traced
+// or profiled (see Context.Pure), and )ieee mode is off.
+func foldFloats(c Context, op string) bool {
+	return (op == "+" || op == "*") && !c.UserDefined(op, true) && c.Pure(op, true) && !c.Config().IEEE()
+}
+
+// floatFold returns the reduction op/x of the n > 1 values x(0)...x(n-1),
+// accumulated in a pooled temporary. Op must satisfy foldFloats.
+// It returns nil if a value is not a BigFloat or is NaN.
+func floatFold(c Context, op string, n int, x func(i int) Value) Value {
+	for i := range n {
+		if f, ok := x(i).(BigFloat); !ok || f.Float == nanFloat {
+			return nil
+		}
+	}
+	fn := (*big.Float).Add
+	if op == "*" {
+		fn = (*big.Float).Mul
+	}
+	conf := c.Config()
+	acc := tempF(conf)
+	defer releaseF(acc)
+	fn(acc, x(n-2).(BigFloat).Float, x(n-1).(BigFloat).Float)
+	checkOverflow(BigFloat{acc})
+	for i := n - 3; i >= 0; i-- {
+		fn(acc, x(i).(BigFloat).Float, acc)
+		checkOverflow(BigFloat{acc})
+	}
+	return BigFloat{newF(conf).Set(acc)}.shrink()
+}
+
+// floatDot returns the inner product +.* of the n values u(0)...u(n-1)
+// and v(0)...v(n-1), accumulated in pooled temporaries. It returns nil
+// if a value is not a BigFloat or is NaN, or if a step would add two integers,
+// which evaluating the ops one at a time does exactly.
+func floatDot(c Context, n int, u, v func(i int) Value) Value {
+	for i := range n {
+		f, ok := u(i).(BigFloat)
+		g, ok2 := v(i).(BigFloat)
+		if !ok || !ok2 || f.Float == nanFloat || g.Float == nanFloat {
+			return nil
+		}
+	}
+	conf := c.Config()
+	acc, prod := tempF(conf), tempF(conf)
+	defer releaseF(acc, prod)
+	acc.Mul(u(n-1).(BigFloat).Float, v(n-1).(BigFloat).Float)
+	checkOverflow(BigFloat{acc})
+	for i := n - 2; i >= 0; i-- {
+		prod.Mul(u(i).(BigFloat).Float, v(i).(BigFloat).Float)
+		checkOverflow(BigFloat{prod})
+		if isSmallInt(prod) && isSmallInt(acc) {
+			return nil
+		}
+		acc.Add(prod, acc)
+		checkOverflow(BigFloat{acc})
+	}
+	return BigFloat{newF(conf).Set(acc)}.shrink()
+}
+
 // Reduce computes a reduction such as +/. The slash has been removed.
 func Reduce(c Context, op string, v Value) Value {
 	// We must be right associative; that is the grammar.
@@ -330,6 +500,11 @@ func Reduce(c Context, op string, v Value) Value {
 		if v.Len() == 0 {
 			return v
 		}
+		if v.Len() > 1 && foldFloats(c, op) {
+			if acc := floatFold(c, op, v.Len(), v.At); acc != nil {
+				return acc
+			}
+		}
 		acc := v.At(v.Len() - 1)
 		for i := v.Len() - 2; i >= 0; i-- {
 			acc = c.EvalBinary(v.At(i), op, acc)
@@ -345,9 +520,17 @@ func Reduce(c Context, op string, v Value) Value {
 		}
 		shape := v.shape[:v.Rank()-1]
 		data := newVectorEditor(size(shape), nil)
-		pfor(safeBinary(op), stride, data.Len(), func(lo, hi int) {
+		fold := stride > 1 && foldFloats(c, op)
+		pforContext(c, safeBinary(c, op), stride, data.Len(), func(c Context, lo, hi int) {
 			for i := lo; i < hi; i++ {
 				index := stride * i
+				if fold {
+					acc := floatFold(c, op, stride, func(j int) Value { return v.data.At(index + j) })
+					if acc != nil {
+						data.Set(i, acc)
+						continue
+					}
+				}
 				pos := index + stride - 1
 				acc := v.data.At(pos)
 				pos--
@@ -389,8 +572,16 @@ func ReduceFirst(c Context, op string, v Value) Value {
 	}
 	shape := m.shape[1:m.Rank()]
 	data := newVectorEditor(size(shape), nil)
-	pfor(safeBinary(op), stride, data.Len(), func(lo, hi int) {
+	fold := m.shape[0] > 1 && foldFloats(c, op)
+	pforContext(c, safeBinary(c, op), stride, data.Len(), func(c Context, lo, hi int) {
 		for i := lo; i < hi; i++ {
+			if fold {
+				acc := floatFold(c, op, m.shape[0], func(j int) Value { return m.data.At(i + j*stride) })
+				if acc != nil {
+					data.Set(i, acc)
+					continue
+				}
+			}
 			pos := i + m.data.Len() - stride
 			acc := m.data.At(pos)
 			for j := pos - stride; j >= 0; j -= stride {
@@ -405,6 +596,87 @@ func ReduceFirst(c Context, op string, v Value) Value {
 	return NewMatrix(shape, data.Publish())
 }
 
+// NwiseReduce computes an n-wise reduction such as 2 -/ v, the reductions
+// of the successive windows of n elements along the last axis of v. If n
+// is negative, each window is reversed. The slash has been removed.
+func NwiseReduce(c Context, n Value, op string, v Value) Value {
+	width := nwiseWidth(op, n)
+	switch v := v.(type) {
+	case Int, BigInt, BigRat, BigFloat, Complex, Char:
+		return NwiseReduce(c, n, op, NewVector(v))
+	case *Vector:
+		return nwiseVector(c, width, op, v)
+	case *Matrix:
+		if v.Rank() < 2 {
+			Errorf("shape for matrix is degenerate: %s", NewIntVector(v.shape...))
+		}
+		stride := v.shape[v.Rank()-1]
+		nrows := size(v.shape[:v.Rank()-1])
+		shape := slices.Clone(v.shape)
+		shape[len(shape)-1] = nwiseLen(width, stride)
+		data := newVectorEditor(0, nil)
+		for i := range nrows {
+			row := NewVectorSeq(v.data.Slice(i*stride, (i+1)*stride))
+			data.Append(nwiseVector(c, width, op, row).ro...)
+		}
+		return NewMatrix(shape, data.Publish())
+	}
+	Errorf("can't do reduce on %s", whichType(v))
+	panic("not reached")
+}
+
+// NwiseReduceFirst computes an n-wise reduction such as 2 -/% v along
+// the first axis. The slash-percent has been removed.
+func NwiseReduceFirst(c Context, n Value, op string, v Value) Value {
+	m, ok := v.(*Matrix)
+	if !ok {
+		// Same as regular n-wise reduce.
+		return NwiseReduce(c, n, op, v)
+	}
+	last := m.Rank() - 1
+	result := NwiseReduce(c, n, op, moveAxis(c, m, 0, last)).(*Matrix)
+	return moveAxis(c, result, last, 0)
+}
+
+// nwiseWidth returns the window width for an n-wise reduction.
+func nwiseWidth(op string, n Value) int {
+	i, ok := n.(Int)
+	if !ok {
+		if v, isVec := n.(*Vector); isVec && v.Len() == 1 {
+			i, ok = v.At(0).(Int)
+		}
+	}
+	if !ok || i == 0 {
+		Errorf("%s/: left operand must be a non-zero small integer", op)
+	}
+	return int(i)
+}
+
+// nwiseLen returns the number of windows of the given width in n elements.
+func nwiseLen(width, n int) int {
+	if width < 0 {
+		width = -width
+	}
+	if width > n+1 {
+		Errorf("n-wise reduce: window %d too large for length %d", width, n)
+	}
+	return n - width + 1
+}
+
+// nwiseVector computes the n-wise reduction of a vector.
+func nwiseVector(c Context, width int, op string, v *Vector) *Vector {
+	result := newVectorEditor(nwiseLen(width, v.Len()), nil)
+	w := max(width, -width)
+	for i := range result.Len() {
+		window := NewVectorSeq(v.Slice(i, i+w))
+		if width < 0 {
+			window = window.reverse()
+		}
+		result.Set(i, Reduce(c, op, window))
+	}
+	return result.Publish()
+}
+
 // Scan computes a scan of the op; the \ has been removed.
 // It gives the successive values of reducing op through v.
 // We must be right associative; that is the grammar.
@@ -440,7 +712,7 @@ func Scan(c Context, op string, v Value) Value {
 		}
 		data := newVectorEditor(v.data.Len(), nil)
 		nrows := size(v.shape[:len(v.shape)-1])
-		pfor(safeBinary(op), stride, nrows, func(lo, hi int) {
+		pforContext(c, safeBinary(c, op), stride, nrows, func(c Context, lo, hi int) {
 			for i := lo; i < hi; i++ {
 				index := i * stride
 				// This is fundamentally O(n²) in the general case.
@@ -645,11 +917,43 @@ func Each(c Context, op string, v Value) Value {
 	return NewMatrix(m.shape[:d], data.Publish())
 }
 
+// EachIndex computes f #@ v, which evaluates i f v[i] for each index i
+// of v. For a matrix, i is the vector of coordinates of the element.
+func EachIndex(c Context, op string, v Value) Value {
+	origin := c.Config().Origin()
+	switch v := v.(type) {
+	case *Vector:
+		data := newVectorEditor(v.Len(), nil)
+		for i, x := range v.All() {
+			data.Set(i, c.EvalBinary(Int(i+origin), op, x))
+		}
+		return data.Publish()
+	case *Matrix:
+		data := newVectorEditor(v.data.Len(), nil)
+		coords := make([]int, len(v.shape))
+		for i := range coords {
+			coords[i] = origin
+		}
+		for i, x := range v.data.All() {
+			data.Set(i, c.EvalBinary(NewIntVector(coords...), op, x))
+			for j := len(coords) - 1; j >= 0; j-- {
+				if coords[j]++; coords[j]-origin < v.shape[j] {
+					break
+				}
+				coords[j] = origin
+			}
+		}
+		return NewMatrix(v.shape, data.Publish())
+	}
+	Errorf("%s#@: arg is scalar", op)
+	panic("not reached")
+}
+
 // unaryVectorOp applies op elementwise to i.
 func unaryVectorOp(c Context, op string, i Value) Value {
 	u := i.(*Vector)
 	n := newVectorEditor(u.Len(), nil)
-	pfor(safeUnary(op), 1, n.Len(), func(lo, hi int) {
+	pforContext(c, safeUnary(c, op), 1, n.Len(), func(c Context, lo, hi int) {
 		for k := lo; k < hi; k++ {
 			n.Set(k, c.EvalUnary(op, u.At(k)))
 		}
@@ -661,7 +965,7 @@ func unaryVectorOp(c Context, op string, i Value) Value {
 func unaryMatrixOp(c Context, op string, i Value) Value {
 	u := i.(*Matrix)
 	n := newVectorEditor(u.data.Len(), nil)
-	pfor(safeUnary(op), 1, n.Len(), func(lo, hi int) {
+	pforContext(c, safeUnary(c, op), 1, n.Len(), func(c Context, lo, hi int) {
 		for k := lo; k < hi; k++ {
 			n.Set(k, c.EvalUnary(op, u.data.At(k)))
 		}
@@ -669,12 +973,76 @@ func unaryMatrixOp(c Context, op string, i Value) Value {
 	return NewMatrix(u.shape, n.Publish())
 }
 
+// kernel returns the implementation of the builtin elementwise binary
+// op for the elements of u and v if they are all Ints or all BigFloats,
+// so it may be applied to them directly, without dispatching on each
+// element. Otherwise, or if their lengths do not conform, it returns nil.
+func kernel(c Context, op string, u, v *Vector) binaryFn {
+	if u.Len() != v.Len() && u.Len() != 1 && v.Len() != 1 {
+		return nil
+	}
+	bop, ok := BinaryOps[op].(*binaryOp)
+	if !ok || !bop.elementwise || c.UserDefined(op, true) {
+		return nil
+	}
+	if conf := c.Config(); conf.Tracing(2) || conf.IEEE() {
+		return nil
+	}
+	which := elemType(u)
+	if which != intType && which != bigFloatType || elemType(v) != which {
+		return nil
+	}
+	if whichU, whichV := bop.whichType(which, which); whichU != which || whichV != which {
+		return nil
+	}
+	return bop.fn[which]
+}
+
+// elemType returns the type of the elements of v if they are all the
+// same type, and numType if they are not or v is empty.
+func elemType(v *Vector) valueType {
+	if v.Len() == 0 {
+		return numType
+	}
+	which := whichType(v.At(0))
+	for _, x := range v.All() {
+		if whichType(x) != which {
+			return numType
+		}
+	}
+	return which
+}
+
+// kernelOp applies fn, as returned by kernel, elementwise to u and v.
+// Either may be a single element, which is paired with each element
+// of the other.
+func kernelOp(c Context, fn binaryFn, u, v *Vector) *Vector {
+	n := max(u.Len(), v.Len())
+	ustep, vstep := 1, 1
+	if u.Len() == 1 {
+		ustep = 0
+	}
+	if v.Len() == 1 {
+		vstep = 0
+	}
+	out := newVectorEditor(n, nil)
+	pfor(true, 1, n, func(lo, hi int) {
+		for k := lo; k < hi; k++ {
+			out.Set(k, checkOverflow(fn(c, u.At(k*ustep), v.At(k*vstep))))
+		}
+	})
+	return out.Publish()
+}
+
 // binaryVectorOp applies op elementwise to i and j.
 func binaryVectorOp(c Context, i Value, op string, j Value) Value {
 	u, v := i.(*Vector), j.(*Vector)
+	if fn := kernel(c, op, u, v); fn != nil {
+		return kernelOp(c, fn, u, v)
+	}
 	if u.Len() == 1 {
 		n := newVectorEditor(v.Len(), nil)
-		pfor(safeBinary(op), 1, n.Len(), func(lo, hi int) {
+		pforContext(c, safeBinary(c, op), 1, n.Len(), func(c Context, lo, hi int) {
 			for k := lo; k < hi; k++ {
 				n.Set(k, c.EvalBinary(u.At(0), op, v.At(k)))
 			}
@@ -683,7 +1051,7 @@ func binaryVectorOp(c Context, i Value, op string, j Value) Value {
 	}
 	if v.Len() == 1 {
 		n := newVectorEditor(u.Len(), nil)
-		pfor(safeBinary(op), 1, n.Len(), func(lo, hi int) {
+		pforContext(c, safeBinary(c, op), 1, n.Len(), func(c Context, lo, hi int) {
 			for k := lo; k < hi; k++ {
 				n.Set(k, c.EvalBinary(u.At(k), op, v.At(0)))
 			}
@@ -692,7 +1060,7 @@ func binaryVectorOp(c Context, i Value, op string, j Value) Value {
 	}
 	u.sameLength(v)
 	n := newVectorEditor(u.Len(), nil)
-	pfor(safeBinary(op), 1, n.Len(), func(lo, hi int) {
+	pforContext(c, safeBinary(c, op), 1, n.Len(), func(c Context, lo, hi int) {
 		for k := lo; k < hi; k++ {
 			n.Set(k, c.EvalBinary(u.At(k), op, v.At(k)))
 		}
@@ -712,7 +1080,7 @@ func binaryMatrixOp(c Context, i Value, op string, j Value) Value {
 		// Scalar op Matrix.
 		shape = v.shape
 		n = newVectorEditor(v.data.Len(), nil)
-		pfor(safeBinary(op), 1, n.Len(), func(lo, hi int) {
+		pforContext(c, safeBinary(c, op), 1, n.Len(), func(c Context, lo, hi int) {
 			for k := lo; k < hi; k++ {
 				n.Set(k, c.EvalBinary(u.data.At(0), op, v.data.At(k)))
 			}
@@ -720,7 +1088,7 @@ func binaryMatrixOp(c Context, i Value, op string, j Value) Value {
 	case isScalar(v):
 		// Matrix op Scalar.
 		n = newVectorEditor(u.data.Len(), nil)
-		pfor(safeBinary(op), 1, n.Len(), func(lo, hi int) {
+		pforContext(c, safeBinary(c, op), 1, n.Len(), func(c Context, lo, hi int) {
 			for k := lo; k < hi; k++ {
 				n.Set(k, c.EvalBinary(u.data.At(k), op, v.data.At(0)))
 			}
@@ -730,7 +1098,7 @@ func binaryMatrixOp(c Context, i Value, op string, j Value) Value {
 		shape = v.shape
 		n = newVectorEditor(v.data.Len(), nil)
 		dim := u.shape[0]
-		pfor(safeBinary(op), 1, n.Len(), func(lo, hi int) {
+		pforContext(c, safeBinary(c, op), 1, n.Len(), func(c Context, lo, hi int) {
 			for k := lo; k < hi; k++ {
 				n.Set(k, c.EvalBinary(u.data.At(k%dim), op, v.data.At(k)))
 			}
@@ -739,16 +1107,29 @@ func binaryMatrixOp(c Context, i Value, op string, j Value) Value {
 		// Matrix op Vector.
 		n = newVectorEditor(u.data.Len(), nil)
 		dim := v.shape[0]
-		pfor(safeBinary(op), 1, n.Len(), func(lo, hi int) {
+		pforContext(c, safeBinary(c, op), 1, n.Len(), func(c Context, lo, hi int) {
 			for k := lo; k < hi; k++ {
 				n.Set(k, c.EvalBinary(u.data.At(k), op, v.data.At(k%dim)))
 			}
 		})
+	case !sameShape(u.shape, v.shape):
+		// Matrix op Matrix, stretching axes of length 1.
+		shape = broadcastShape(u.shape, v.shape)
+		checkElems(c, int64(size(shape)))
+		n = newVectorEditor(size(shape), nil)
+		ui, vi := broadcastIndex(u.shape, shape), broadcastIndex(v.shape, shape)
+		pforContext(c, safeBinary(c, op), 1, n.Len(), func(c Context, lo, hi int) {
+			for k := lo; k < hi; k++ {
+				n.Set(k, c.EvalBinary(u.data.At(ui(k)), op, v.data.At(vi(k))))
+			}
+		})
 	default:
 		// Matrix op Matrix.
-		u.sameShape(v)
+		if fn := kernel(c, op, u.data, v.data); fn != nil {
+			return NewMatrix(shape, kernelOp(c, fn, u.data, v.data))
+		}
 		n = newVectorEditor(u.data.Len(), nil)
-		pfor(safeBinary(op), 1, n.Len(), func(lo, hi int) {
+		pforContext(c, safeBinary(c, op), 1, n.Len(), func(c Context, lo, hi int) {
 			for k := lo; k < hi; k++ {
 				n.Set(k, c.EvalBinary(u.data.At(k), op, v.data.At(k)))
 			}
@@ -757,6 +1138,58 @@ func binaryMatrixOp(c Context, i Value, op string, j Value) Value {
 	return NewMatrix(shape, n.Publish())
 }
 
+// broadcastShape returns the shape of the result of an elementwise
+// operation on arrays of shapes x and y. With the shapes aligned at
+// their last axes, and missing leading axes taken to be 1, each pair of
+// axes must have the same length or one of them must be 1, in which
+// case it is stretched to the length of the other.
+func broadcastShape(x, y []int) []int {
+	shape := make([]int, max(len(x), len(y)))
+	for i := range shape {
+		a, b := axisFromEnd(x, len(shape)-i), axisFromEnd(y, len(shape)-i)
+		switch {
+		case a == b || b == 1:
+			shape[i] = a
+		case a == 1:
+			shape[i] = b
+		default:
+			Errorf("shape mismatch: %s and %s do not conform", NewIntVector(x...), NewIntVector(y...))
+		}
+	}
+	return shape
+}
+
+// axisFromEnd returns the length of the i-th axis of shape counting
+// from the end, starting at 1, or 1 if there are fewer than i axes.
+func axisFromEnd(shape []int, i int) int {
+	if i > len(shape) {
+		return 1
+	}
+	return shape[len(shape)-i]
+}
+
+// broadcastIndex returns a function that maps the index of an element
+// in the data of an array of the broadcast shape to that of the
+// element it comes from in an array of shape from.
+func broadcastIndex(from, shape []int) func(int) int {
+	strides := make([]int, len(shape))
+	stride := 1
+	for i := 1; i <= len(from); i++ {
+		if from[len(from)-i] != 1 {
+			strides[len(shape)-i] = stride
+		}
+		stride *= from[len(from)-i]
+	}
+	return func(k int) int {
+		index := 0
+		for i := len(shape) - 1; i >= 0; i-- {
+			index += k % shape[i] * strides[i]
+			k /= shape[i]
+		}
+		return index
+	}
+}
+
 // IsScalarType reports whether u is an actual scalar, an int or float etc.
 func IsScalarType(v Value) bool {
 	return whichType(v) < vectorType
@@ -796,7 +1229,7 @@ func isZero(v Value) bool {
 	case BigRat:
 		return v.Sign() == 0
 	case BigFloat:
-		return v.Sign() == 0
+		return v.Sign() == 0 && v.Float != nanFloat
 	case Complex:
 		return isZero(v.real) && isZero(v.imag)
 	}
@@ -848,6 +1281,12 @@ func compare(v Value, i int) int {
 	return -1
 }
 
+// IsTrue reports whether v, the condition of a conditional statement
+// in the op fnName, is true.
+func IsTrue(fnName string, v Value) bool {
+	return isTrue(fnName, v)
+}
+
 // isTrue reports whether v represents boolean truth. If v is not
 // ultimately a scalar, an error results.
 func isTrue(fnName string, v Value) bool {
@@ -873,10 +1312,59 @@ func isTrue(fnName string, v Value) bool {
 			return isTrue(fnName, i.data.At(0))
 		}
 	}
+	if fnName == "" { // Top level.
+		Errorf("invalid expression %s for conditional", v)
+	}
 	Errorf("invalid expression %s for conditional inside %q", v, fnName)
 	return false
 }
 
+// ifElse implements the binary ifelse operator when its right operand
+// has already been evaluated: it returns the first element of v if u is
+// true, the second otherwise.
+func ifElse(c Context, u, v Value) Value {
+	branches := v.(*Vector)
+	if branches.Len() != 2 {
+		Errorf("ifelse: right operand must have two elements")
+	}
+	if isTrue("", u) {
+		return branches.At(0)
+	}
+	return branches.At(1)
+}
+
+// condAnd implements the binary && operator when both operands have
+// already been evaluated, as in a reduction.
+func condAnd(c Context, u, v Value) Value {
+	return toInt(isTrue("", u) && isTrue("", v))
+}
+
+// condOr implements the binary || operator when both operands have
+// already been evaluated.
+func condOr(c Context, u, v Value) Value {
+	return toInt(isTrue("", u) || isTrue("", v))
+}
+
+// shortCircuit evaluates left && right or left || right, evaluating
+// the left operand first and the right only if the result depends on it.
+func shortCircuit(c Context, left Expr, op string, right Expr) Value {
+	l := isTrue("", left.Eval(c).Inner())
+	if l == (op == "||") {
+		return toInt(l)
+	}
+	return toInt(isTrue("", right.Eval(c).Inner()))
+}
+
+// lazyIfElse implements ifelse when the right operand is written as
+// a vector of two expressions. It evaluates the condition and then
+// only the chosen branch.
+func lazyIfElse(c Context, cond Expr, branches VectorExpr) Value {
+	if isTrue("", cond.Eval(c).Inner()) {
+		return branches[0].Eval(c)
+	}
+	return branches[1].Eval(c)
+}
+
 // sgn is a wrapper for calling "sgn v".
 func sgn(c Context, v Value) int {
 	return int(c.EvalUnary("sgn", v).(Int))
@@ -957,12 +1445,12 @@ func QuoRem(op string, c Context, a, b Value) (div, rem Value) {
 		x := a.toType(op, c.Config(), bigRatType).(BigRat).Rat
 		y := b.toType(op, c.Config(), bigRatType).(BigRat).Rat
 		if x.Sign() < 0 {
-			x = x.Set(x) // Copy x.
+			x = new(big.Rat).Set(x) // Copy x.
 			x.Neg(x)
 			negX = true
 		}
 		if y.Sign() < 0 {
-			y = y.Set(y) // Copy y.
+			y = new(big.Rat).Set(y) // Copy y.
 			y.Neg(y)
 			negY = true
 		}
@@ -984,12 +1472,12 @@ func QuoRem(op string, c Context, a, b Value) (div, rem Value) {
 		x := a.toType(op, c.Config(), bigFloatType).(BigFloat).Float
 		y := b.toType(op, c.Config(), bigFloatType).(BigFloat).Float
 		if x.Sign() < 0 {
-			x = x.Copy(x)
+			x = new(big.Float).Copy(x)
 			x.Neg(x)
 			negX = true
 		}
 		if y.Sign() < 0 {
-			y = y.Copy(y)
+			y = new(big.Float).Copy(y)
 			y.Neg(y)
 			negY = true
 		}
@@ -1065,3 +1553,101 @@ func flattenTo(off int, v Value, yield func(int, Value) bool) (newOff int, cont
 func box(c Context, v Value) Value {
 	return NewVector(v)
 }
+
+// depth returns the depth of nesting of v: 0 for a scalar, 1 for a
+// vector or matrix of scalars, and one more than the deepest element
+// for nested values.
+func depth(c Context, v Value) Value {
+	return Int(nestingDepth(v))
+}
+
+func nestingDepth(v Value) int {
+	var elems *Vector
+	switch v := v.(type) {
+	case *Vector:
+		elems = v
+	case *Matrix:
+		elems = v.data
+	default:
+		return 0
+	}
+	d := 0
+	for _, e := range elems.All() {
+		d = max(d, nestingDepth(e))
+	}
+	return d + 1
+}
+
+// level returns the level count, a non-negative small integer, for op.
+func level(op string, v Value) int {
+	if vec, ok := v.(*Vector); ok && vec.Len() == 1 {
+		v = vec.At(0)
+	}
+	n, ok := v.(Int)
+	if !ok || n < 0 {
+		Errorf("%s: level must be a non-negative small integer", op)
+	}
+	return int(n)
+}
+
+// firstN implements n first v, which applies first n times to
+// descend n levels of nesting.
+func firstN(c Context, u, v Value) Value {
+	first := UnaryOps["first"]
+	for range level("first", u) {
+		v = first.EvalUnary(c, v)
+	}
+	return v
+}
+
+// splitN implements n split v, which splits off the last n axes of v
+// as the elements of the result.
+func splitN(c Context, u, v Value) Value {
+	n := level("split", u)
+	switch v := v.(type) {
+	case *Matrix:
+		return v.splitN(n)
+	case *Vector:
+		return NewMatrix([]int{v.Len()}, v).splitN(n)
+	}
+	if n != 0 {
+		Errorf("split: bad level %d for scalar", n)
+	}
+	return v
+}
+
+// divmod implements A divmod B, the pair (A div B) (A mod B) computed by
+// a single Euclidean division. For arrays, each half of the pair has the
+// shape of the elementwise result.
+func divmod(c Context, u, v Value) Value {
+	switch u.(type) {
+	case *Vector:
+		return unzipPairs(binaryVectorOp(c, u, "divmod", v))
+	case *Matrix:
+		return unzipPairs(binaryMatrixOp(c, u, "divmod", v))
+	}
+	quo, rem := QuoRem("divmod", c, u, v)
+	return NewVector(quo, rem)
+}
+
+// unzipPairs turns a vector or matrix of pairs into a pair of vectors
+// or matrices.
+func unzipPairs(v Value) Value {
+	data := v
+	m, isMatrix := v.(*Matrix)
+	if isMatrix {
+		data = m.data
+	}
+	pairs := data.(*Vector)
+	first := newVectorEditor(pairs.Len(), nil)
+	second := newVectorEditor(pairs.Len(), nil)
+	for i, p := range pairs.All() {
+		pair := p.(*Vector)
+		first.Set(i, pair.At(0))
+		second.Set(i, pair.At(1))
+	}
+	if isMatrix {
+		return NewVector(NewMatrix(m.shape, first.Publish()), NewMatrix(m.shape, second.Publish()))
+	}
+	return NewVector(first.Publish(), second.Publish())
+}
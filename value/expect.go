@@ -0,0 +1,48 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"fmt"
+	"slices"
+
+	"robpike.io/ivy/config"
+)
+
+// expect implements the binary expect operator, for testing ivy code.
+// It records in the configuration whether v, the actual value, matches
+// u, the expected one, in shape and in every element, as === does.
+// If not, it is an error, so the failure is reported with its position
+// in the input. The result is v, but it is not printed.
+func expect(c Context, u, v Value) Value {
+	conf := c.Config()
+	ok := slices.Equal(shapeOf(u), shapeOf(v)) && OrderedCompare(c, u, v) == 0
+	conf.RecordExpectation(ok)
+	if !ok {
+		Errorf("expect: got %s; want %s", oneLine(conf, v), oneLine(conf, u))
+	}
+	return QuietValue{v}
+}
+
+// shapeOf returns the shape of v; a scalar has none.
+func shapeOf(v Value) []int {
+	switch v := v.(type) {
+	case *Vector:
+		return []int{v.Len()}
+	case *Matrix:
+		return v.Shape()
+	}
+	return nil
+}
+
+// oneLine returns the text of v on a single line, for an error message.
+// A matrix is shown as its shape and elements: 2 2 rho 1 2 3 4.
+func oneLine(conf *config.Config, v Value) string {
+	m, ok := v.(*Matrix)
+	if !ok {
+		return v.Sprint(conf)
+	}
+	return fmt.Sprintf("%s rho %s", NewIntVector(m.Shape()...).Sprint(conf), m.Data().Sprint(conf))
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"html"
+	"strings"
+)
+
+// export implements A export B, which renders the scalar, vector or
+// matrix B as text to paste into a document: a LaTeX bmatrix, a Markdown
+// table, or an HTML table, as A is "latex", "markdown", or "html".
+// A vector is a single row.
+func export(c Context, u, v Value) Value {
+	kind := textOf("export", u)
+	rows := exportCells(c, v)
+	var b strings.Builder
+	switch kind {
+	case "latex":
+		b.WriteString("\\begin{bmatrix}\n")
+		for i, row := range rows {
+			for j, cell := range row {
+				if j > 0 {
+					b.WriteString(" & ")
+				}
+				b.WriteString(latexCell(cell))
+			}
+			if i < len(rows)-1 {
+				b.WriteString(" \\\\")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\\end{bmatrix}")
+	case "markdown":
+		// Markdown tables need a header, so use an empty one.
+		ncols := len(rows[0])
+		b.WriteString("|" + strings.Repeat("   |", ncols) + "\n")
+		b.WriteString("|" + strings.Repeat("--:|", ncols))
+		for _, row := range rows {
+			b.WriteString("\n|")
+			for _, cell := range row {
+				b.WriteString(" " + strings.ReplaceAll(cell.text, "|", "\\|") + " |")
+			}
+		}
+	case "html":
+		b.WriteString("<table>\n")
+		for _, row := range rows {
+			b.WriteString("<tr>")
+			for _, cell := range row {
+				b.WriteString("<td>" + html.EscapeString(cell.text) + "</td>")
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</table>")
+	default:
+		Errorf("export: unknown format %q; must be latex, markdown, or html", kind)
+	}
+	return newCharVector(b.String())
+}
+
+// exportCell is an element of a value being exported.
+type exportCell struct {
+	text string
+	val  Value
+}
+
+// exportCells returns the elements of v, which must have rank 2 or less,
+// as rows of cells.
+func exportCells(c Context, v Value) [][]exportCell {
+	conf := c.Config()
+	cell := func(x Value) exportCell {
+		text := strings.ReplaceAll(x.Sprint(conf), "\n", " ")
+		return exportCell{text, x}
+	}
+	switch v := v.(type) {
+	case *Vector:
+		if v.Len() == 0 {
+			Errorf("export: empty value")
+		}
+		row := make([]exportCell, v.Len())
+		for i, x := range v.All() {
+			row[i] = cell(x)
+		}
+		return [][]exportCell{row}
+	case *Matrix:
+		if v.Rank() != 2 {
+			Errorf("export: rank %d value", v.Rank())
+		}
+		nrows, ncols := v.shape[0], v.shape[1]
+		if nrows == 0 || ncols == 0 {
+			Errorf("export: empty value")
+		}
+		rows := make([][]exportCell, nrows)
+		for i := range rows {
+			rows[i] = make([]exportCell, ncols)
+			for j := range ncols {
+				rows[i][j] = cell(v.data.At(i*ncols + j))
+			}
+		}
+		return rows
+	}
+	return [][]exportCell{{cell(v)}}
+}
+
+// latexCell returns the LaTeX text for a cell, writing rationals as fractions.
+func latexCell(cell exportCell) string {
+	if r, ok := cell.val.(BigRat); ok {
+		num, den, _ := strings.Cut(cell.text, "/")
+		sign := ""
+		if r.Sign() < 0 {
+			sign = "-"
+			num = strings.TrimPrefix(num, "-")
+		}
+		return sign + "\\frac{" + num + "}{" + den + "}"
+	}
+	var b strings.Builder
+	for _, r := range cell.text {
+		switch r {
+		case '&', '%', '$', '#', '_', '{', '}':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '~':
+			b.WriteString("\\textasciitilde{}")
+		case '^':
+			b.WriteString("\\textasciicircum{}")
+		case '\\':
+			b.WriteString("\\textbackslash{}")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
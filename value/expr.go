@@ -7,6 +7,7 @@ package value
 import (
 	"bytes"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -23,23 +24,37 @@ type Expr interface {
 
 type UnaryExpr struct {
 	Op    string
+	Axis  Expr // Optional axis specifier, as in +/[1] x.
 	Right Expr
 }
 
 func (u *UnaryExpr) ProgString() string {
-	return fmt.Sprintf("%s %s", u.Op, u.Right.ProgString())
+	return fmt.Sprintf("%s %s", axisOp(u.Op, u.Axis), u.Right.ProgString())
 }
 
 func (u *UnaryExpr) Eval(context Context) Value {
+	if u.Axis != nil {
+		v := u.Right.Eval(context).Inner()
+		return EvalUnaryAxis(context, u.Op, u.Axis.Eval(context).Inner(), v)
+	}
 	return context.EvalUnary(u.Op, u.Right.Eval(context).Inner())
 }
 
 type BinaryExpr struct {
 	Op    string
+	Axis  Expr // Optional axis specifier, as in x ,[1] y.
 	Left  Expr
 	Right Expr
 }
 
+// axisOp returns the text of op with its axis specifier, if any.
+func axisOp(op string, axis Expr) string {
+	if axis == nil {
+		return op
+	}
+	return fmt.Sprintf("%s[%s]", op, axis.ProgString())
+}
+
 func (b *BinaryExpr) ProgString() string {
 	var left string
 	if IsCompound(b.Left) {
@@ -47,14 +62,27 @@ func (b *BinaryExpr) ProgString() string {
 	} else {
 		left = b.Left.ProgString()
 	}
-	return fmt.Sprintf("%s %s %s", left, b.Op, b.Right.ProgString())
+	return fmt.Sprintf("%s %s %s", left, axisOp(b.Op, b.Axis), b.Right.ProgString())
 }
 
 func (b *BinaryExpr) Eval(context Context) Value {
 	if b.Op == "=" {
 		return assign(context, b)
 	}
+	if b.Op == "ifelse" && b.Axis == nil && !context.UserDefined(b.Op, true) {
+		if branches, ok := b.Right.(VectorExpr); ok && len(branches) == 2 {
+			return lazyIfElse(context, b.Left, branches)
+		}
+	}
+	if (b.Op == "&&" || b.Op == "||") && b.Axis == nil {
+		return shortCircuit(context, b.Left, b.Op, b.Right)
+	}
 	rhs := b.Right.Eval(context).Inner()
+	if b.Axis != nil {
+		axis := b.Axis.Eval(context).Inner()
+		lhs := b.Left.Eval(context).Inner()
+		return EvalBinaryAxis(context, lhs, b.Op, axis, rhs)
+	}
 	lhs := b.Left.Eval(context)
 	return context.EvalBinary(lhs, b.Op, rhs)
 }
@@ -151,6 +179,59 @@ func (e VectorExpr) allChars() bool {
 	return true
 }
 
+// MatrixExpr holds a syntactic matrix, [row; row; ...], to be evaluated.
+// The rows, which may be scalars, vectors or matrices, must all have the
+// same shape; the result stacks them along a new first axis.
+type MatrixExpr []Expr
+
+func (e MatrixExpr) Eval(context Context) Value {
+	rows := make([]Value, len(e))
+	// Evaluate right to left, as for VectorExpr.
+	for i := len(e) - 1; i >= 0; i-- {
+		rows[i] = e[i].Eval(context).Inner()
+	}
+	var shape []int
+	for i, row := range rows {
+		s := []int{1}
+		switch row := row.(type) {
+		case *Vector:
+			s = []int{row.Len()}
+		case *Matrix:
+			s = row.shape
+		}
+		if i == 0 {
+			shape = s
+		} else if !slices.Equal(s, shape) {
+			Errorf("matrix: rows have different shapes %v and %v", shape, s)
+		}
+	}
+	elems := newVectorEditor(0, nil)
+	for _, row := range rows {
+		switch row := row.(type) {
+		case *Vector:
+			elems.Append(row.ro...)
+		case *Matrix:
+			elems.Append(row.data.ro...)
+		default:
+			elems.Append(row)
+		}
+	}
+	return NewMatrix(append([]int{len(rows)}, shape...), elems.Publish())
+}
+
+func (e MatrixExpr) ProgString() string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, row := range e {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(row.ProgString())
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
 type IndexExpr struct {
 	Op    string
 	Left  Expr
@@ -216,6 +297,21 @@ func (e *VarExpr) ProgString() string {
 	return e.Name
 }
 
+// ConstExpr is a constant subexpression of an op body, evaluated once
+// when the op was defined. It prints as the original expression.
+type ConstExpr struct {
+	Expr  Expr
+	Value Value
+}
+
+func (e *ConstExpr) Eval(Context) Value {
+	return e.Value
+}
+
+func (e *ConstExpr) ProgString() string {
+	return e.Expr.ProgString()
+}
+
 // IsCompound reports whether the item is a non-trivial expression tree, one that
 // may require parentheses around it when printed to maintain correct evaluation order.
 func IsCompound(x interface{}) bool {
@@ -228,6 +324,8 @@ func IsCompound(x interface{}) bool {
 		return true
 	case *IndexExpr:
 		return IsCompound(x.Left)
+	case *ConstExpr:
+		return IsCompound(x.Expr)
 	default:
 		return true
 	}
@@ -0,0 +1,80 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"iter"
+	"strings"
+)
+
+// fields implements A fields B: it splits the text B into a vector of the
+// texts separated by the delimiter A, or by runs of white space if A is
+// empty. Given a matrix of characters, it splits each row, ignoring the
+// blanks that pad it, and returns the results with the shape of the rows.
+func fields(c Context, u, v Value) Value {
+	var sep string
+	switch u := u.(type) {
+	case Char:
+		sep = string(u)
+	case *Vector:
+		if !u.AllChars() {
+			Errorf("fields: delimiter must be text")
+		}
+		sep = charsText(u.All())
+	default:
+		Errorf("fields: delimiter must be text")
+	}
+	switch v := v.(type) {
+	case Char:
+		return splitFields(string(v), sep)
+	case *Vector:
+		if !v.AllChars() {
+			Errorf("fields: right operand must be text")
+		}
+		return splitFields(charsText(v.All()), sep)
+	case *Matrix:
+		if !v.data.AllChars() {
+			Errorf("fields: right operand must be text")
+		}
+		shape := v.shape[:len(v.shape)-1]
+		cols := v.shape[len(v.shape)-1]
+		rows := newVectorEditor(size(shape), nil)
+		for i := range rows.Len() {
+			row := charsText(v.data.Slice(i*cols, (i+1)*cols))
+			rows.Set(i, splitFields(strings.TrimRight(row, " "), sep))
+		}
+		if len(shape) == 1 {
+			return rows.Publish()
+		}
+		return NewMatrix(shape, rows.Publish())
+	}
+	Errorf("fields: right operand must be text")
+	panic("not reached")
+}
+
+// charsText returns the text of a sequence of Chars.
+func charsText(seq iter.Seq2[int, Value]) string {
+	var b strings.Builder
+	for _, c := range seq {
+		b.WriteRune(rune(c.Inner().(Char)))
+	}
+	return b.String()
+}
+
+// splitFields returns a vector of the texts in s separated by sep,
+// or by white space if sep is empty.
+func splitFields(s, sep string) *Vector {
+	var f []string
+	if sep == "" {
+		f = strings.Fields(s)
+	} else {
+		f = strings.Split(s, sep)
+	}
+	result := newVectorEditor(len(f), nil)
+	for i, field := range f {
+		result.Set(i, newCharVector(field))
+	}
+	return result.Publish()
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+// find implements p find s: a boolean array with the shape of s
+// marking with 1 each position at which the pattern p begins. A pattern
+// of lower rank than s is treated as having leading axes of length 1, so
+// a vector pattern searches each row of a matrix. A pattern of higher
+// rank than s occurs nowhere.
+func find(c Context, u, v Value) Value {
+	pShape, pData := findShape(u)
+	sShape, sData := findShape(v)
+	result := newVectorEditor(sData.Len(), zero)
+	if len(pShape) <= len(sShape) {
+		// Pad the pattern's shape with leading 1s.
+		pad := make([]int, len(sShape)-len(pShape), len(sShape))
+		for i := range pad {
+			pad[i] = 1
+		}
+		pShape = append(pad, pShape...)
+		pos := make([]int, len(sShape)) // Coordinates of the current element of s.
+		for i := range sData.Len() {
+			if findAt(c, pShape, pData, sShape, sData, pos) {
+				result.Set(i, one)
+			}
+			for j := len(pos) - 1; j >= 0; j-- {
+				if pos[j]++; pos[j] < sShape[j] {
+					break
+				}
+				pos[j] = 0
+			}
+		}
+	}
+	switch len(sShape) {
+	case 0:
+		return result.At(0)
+	case 1:
+		return result.Publish()
+	}
+	return NewMatrix(sShape, result.Publish())
+}
+
+// findShape returns the shape and data of v, treating a scalar as having rank 0.
+func findShape(v Value) ([]int, *Vector) {
+	switch v := v.(type) {
+	case *Vector:
+		return []int{v.Len()}, v
+	case *Matrix:
+		return v.shape, v.data
+	}
+	return nil, NewVector(v)
+}
+
+// findAt reports whether the pattern, whose shape has been padded to the
+// rank of s, matches s starting at the coordinates pos.
+func findAt(c Context, pShape []int, pData *Vector, sShape []int, sData *Vector, pos []int) bool {
+	for k := range pos {
+		if pos[k]+pShape[k] > sShape[k] {
+			return false
+		}
+	}
+	rowLen := 1 // Length of a row of the pattern, compared as a unit.
+	if len(pShape) > 0 {
+		rowLen = pShape[len(pShape)-1]
+	}
+	if rowLen == 0 {
+		return true
+	}
+	// Walk the rows of the pattern, comparing each with the matching run of s.
+	p := make([]int, len(pShape)) // Coordinates within the pattern; last is always 0.
+	for pi := 0; pi < pData.Len(); pi += rowLen {
+		si := 0
+		for k := range p {
+			si = si*sShape[k] + pos[k] + p[k]
+		}
+		if !allEqual(c, pData, pi, sData, si, rowLen) {
+			return false
+		}
+		for j := len(p) - 2; j >= 0; j-- {
+			if p[j]++; p[j] < pShape[j] {
+				break
+			}
+			p[j] = 0
+		}
+	}
+	return true
+}
@@ -32,9 +32,13 @@ func fmtText(c Context, u, v Value) Value {
 	}
 	var b bytes.Buffer
 	switch val := v.(type) {
-	case Int, BigInt, BigRat, BigFloat, Char:
+	case Int, BigInt, BigRat, BigFloat, Char, Time:
 		formatOne(c, &b, format, verb, val)
 	case Complex:
+		if config.Polar() {
+			formatOne(c, &b, format, verb, val)
+			break
+		}
 		formatOne(c, &b, format, verb, val.real)
 		b.WriteByte('j')
 		formatOne(c, &b, format, verb, val.imag)
@@ -148,6 +152,13 @@ Loop:
 // How it does this depends on the format, permitting us to use %d on
 // floats and rationals, for example.
 func formatOne(c Context, w io.Writer, format string, verb byte, v Value) {
+	if z, ok := v.(Complex); ok && c.Config().Polar() {
+		mag, phase := z.polar(c.Config())
+		formatOne(c, w, format, verb, mag)
+		fmt.Fprint(w, "∠")
+		formatOne(c, w, format, verb, phase)
+		return
+	}
 	switch verb {
 	case 'T': // Time.
 		// Maintain flags etc. but turn T into s.
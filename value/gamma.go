@@ -0,0 +1,163 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math"
+	"math/big"
+	"sync"
+)
+
+// Gamma function for non-integer factorials and binomials, computed
+// from Stirling's series for log Γ after shifting the argument far
+// enough to the right that the series converges to the full precision.
+
+// gamma returns Γ(z) for real or complex z. Γ has poles at zero and
+// the negative integers.
+func gamma(c Context, z Value) Value {
+	if isGammaPole(z) {
+		Errorf("gamma: pole at %s", z.Sprint(c.Config()))
+	}
+	if i, ok := z.(Int); ok {
+		return BigInt{factorial(int64(i) - 1)}.shrink()
+	}
+	pi := BigFloat{newFloat(c).Set(floatPi)}
+	if realFloat64(c, z) < 0.5 {
+		// Reflection: Γ(z) = π / (sin(πz) Γ(1-z)).
+		s := c.EvalUnary("sin", c.EvalBinary(pi, "*", z))
+		return c.EvalBinary(pi, "/", c.EvalBinary(s, "*", gamma(c, c.EvalBinary(one, "-", z))))
+	}
+	// Γ(z) = Γ(z+n) / (z(z+1)...(z+n-1)). Stirling's series
+	// converges quickly for large z, so move z well to the right;
+	// multiplications are much cheaper than Bernoulli numbers.
+	// The product is computed in floating point: an exact rational
+	// z can have a huge denominator, and its powers huger ones.
+	z = c.EvalUnary("float", z)
+	prec := float64(c.Config().FloatPrec())
+	min := prec / 2
+	var den Value = one
+	for realFloat64(c, z) < min {
+		den = c.EvalBinary(den, "*", z)
+		z = c.EvalBinary(z, "+", one)
+	}
+	// Term k is bounded by about (2k)!/(2π|z|)²ᵏ.
+	n := 1
+	for ; ; n++ {
+		lg, _ := math.Lgamma(float64(2*n + 1))
+		if lg-float64(2*n)*math.Log(2*math.Pi*min) < -prec*math.Ln2 {
+			break
+		}
+	}
+	return c.EvalBinary(bigExpValue(c, logGamma(c, z, n)), "/", den)
+}
+
+// bigExpValue returns e**v for real or complex v whose real part may be
+// too large for the exponential series to converge. It writes v as
+// r + k log 2, with the real part of r below log 2, and returns e**r * 2**k.
+func bigExpValue(c Context, v Value) Value {
+	k := math.Floor(realFloat64(c, v) / math.Ln2)
+	if k == 0 {
+		return c.EvalUnary("**", v)
+	}
+	kLog2 := newFloat(c).SetInt64(int64(k))
+	kLog2.Mul(kLog2, floatLog2)
+	r := c.EvalBinary(v, "-", BigFloat{kLog2})
+	scale := newFloat(c).SetMantExp(floatOne, int(k))
+	return c.EvalBinary(c.EvalUnary("**", r), "*", BigFloat{scale})
+}
+
+// factorialGamma returns v!, which is Γ(v+1).
+func factorialGamma(c Context, v Value) Value {
+	return gamma(c, c.EvalBinary(v, "+", one))
+}
+
+// recipGamma returns 1/Γ(z), which is zero at the poles of Γ.
+func recipGamma(c Context, z Value) Value {
+	if isGammaPole(z) {
+		return zero
+	}
+	return c.EvalBinary(one, "/", gamma(c, z))
+}
+
+// isGammaPole reports whether z is zero or a negative integer.
+func isGammaPole(z Value) bool {
+	switch z := z.(type) {
+	case Int:
+		return z <= 0
+	case BigInt:
+		return z.Sign() < 0
+	}
+	return false
+}
+
+// realFloat64 returns the real part of v as a float64, for choosing
+// how to evaluate a function of v.
+func realFloat64(c Context, v Value) float64 {
+	if z, ok := v.(Complex); ok {
+		v = z.real
+	}
+	f, _ := v.toType("gamma", c.Config(), bigFloatType).(BigFloat).Float64()
+	return f
+}
+
+// logGamma returns log Γ(z) from n terms of Stirling's series:
+//
+//	(z-1/2)log z - z + log(2π)/2 + Σ B₂ₖ/(2k(2k-1)z²ᵏ⁻¹)
+func logGamma(c Context, z Value, n int) Value {
+	half := BigRat{big.NewRat(1, 2)}
+	twoPi := BigFloat{newFloat(c).Mul(floatPi, floatTwo)}
+	sum := c.EvalBinary(c.EvalBinary(z, "-", half), "*", c.EvalUnary("log", z))
+	sum = c.EvalBinary(sum, "-", z)
+	sum = c.EvalBinary(sum, "+", c.EvalBinary(c.EvalUnary("log", twoPi), "/", Int(2)))
+	b := bernoulli(2 * n)
+	zInv := c.EvalBinary(one, "/", z)
+	zInv2 := c.EvalBinary(zInv, "*", zInv)
+	pow := zInv
+	for k := 1; k <= n; k++ {
+		coef := new(big.Rat).Quo(b[2*k], big.NewRat(int64(2*k*(2*k-1)), 1))
+		sum = c.EvalBinary(sum, "+", c.EvalBinary(BigRat{coef}, "*", pow))
+		pow = c.EvalBinary(pow, "*", zInv2)
+	}
+	return sum
+}
+
+var bernoulliCache struct {
+	sync.Mutex
+	b []*big.Rat
+}
+
+// bernoulli returns the Bernoulli numbers B₀ through Bₙ, computed
+// exactly with the Akiyama-Tanigawa algorithm. The result is shared
+// and must not be modified.
+func bernoulli(n int) []*big.Rat {
+	bernoulliCache.Lock()
+	defer bernoulliCache.Unlock()
+	if len(bernoulliCache.b) > n {
+		return bernoulliCache.b
+	}
+	a := make([]*big.Rat, n+1)
+	b := make([]*big.Rat, n+1)
+	for m := 0; m <= n; m++ {
+		a[m] = big.NewRat(1, int64(m+1))
+		for j := m; j > 0; j-- {
+			a[j-1].Sub(a[j-1], a[j])
+			a[j-1].Mul(a[j-1], big.NewRat(int64(j), 1))
+		}
+		b[m] = new(big.Rat).Set(a[0])
+	}
+	bernoulliCache.b = b
+	return b
+}
+
+// binomial returns u!v, the binomial coefficient generalized through
+// the gamma function: Γ(v+1) / (Γ(u+1) Γ(v-u+1)).
+func binomial(c Context, u, v Value) Value {
+	num := c.EvalBinary(v, "+", one)
+	if isGammaPole(num) {
+		Errorf("binomial: pole at %s", num.Sprint(c.Config()))
+	}
+	den := c.EvalBinary(recipGamma(c, c.EvalBinary(u, "+", one)), "*", recipGamma(c, c.EvalBinary(c.EvalBinary(v, "-", u), "+", one)))
+	return c.EvalBinary(gamma(c, num), "*", den)
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import "sort"
+
+// Hashing of values, so index-of, membership, and unique can find
+// elements in constant time rather than by sorting or by searching
+// linearly. Only values whose equality is exact have hashes: integers,
+// chars, and times. Other values, such as floats, can equal values of
+// other types in ways that no hash can respect, so when any is present
+// the operators fall back to comparison.
+
+// Tags distinguish the hashes of values of different types.
+const (
+	hashTagChar uint64 = iota + 1
+	hashTagBigInt
+	hashTagTime
+)
+
+// hashMix scrambles the bits of x, as in SplitMix64.
+func hashMix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// hashCombine returns the hash of x following a value with hash h.
+func hashCombine(h, x uint64) uint64 {
+	return hashMix(h ^ hashMix(x))
+}
+
+// hashes returns the hashes of the items of v, each n consecutive
+// elements, and reports whether all the elements are hashable.
+func hashes(v *Vector, n int) ([]uint64, bool) {
+	hs := make([]uint64, v.Len()/n)
+	for i := range hs {
+		var h uint64
+		for j := range n {
+			x, ok := v.At(i*n + j).Hash()
+			if !ok {
+				return nil, false
+			}
+			if n == 1 {
+				h = x
+			} else {
+				h = hashCombine(h, x)
+			}
+		}
+		hs[i] = h
+	}
+	return hs, true
+}
+
+// hashFind returns, for each item of b, the position of the first equal
+// item of a, or -1 if there is none. Items are n consecutive elements.
+// It reports whether it could do so, which requires that all the
+// elements be hashable.
+func hashFind(c Context, a, b *Vector, n int) ([]int, bool) {
+	ha, ok := hashes(a, n)
+	if !ok {
+		return nil, false
+	}
+	hb, ok := hashes(b, n)
+	if !ok {
+		return nil, false
+	}
+	// The positions of the items of a with each hash, in order.
+	index := make(map[uint64][]int, len(ha))
+	for i, h := range ha {
+		index[h] = append(index[h], i)
+	}
+	pos := make([]int, len(hb))
+	pfor(true, 2*n, len(hb), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			pos[i] = -1
+			for _, j := range index[hb[i]] {
+				if allEqual(c, a, j*n, b, i*n, n) {
+					pos[i] = j
+					break
+				}
+			}
+		}
+	})
+	return pos, true
+}
+
+// positions returns the positions found by hashFind as indexes from the
+// origin, as for binary iota; an item not found has index origin-1.
+func positions(pos []int, origin int) *Vector {
+	indices := newVectorEditor(len(pos), nil)
+	for i, p := range pos {
+		indices.Set(i, Int(p+origin))
+	}
+	return indices.Publish()
+}
+
+// uniqueHashed implements unary unique for a vector v whose elements
+// have the hashes hs. Of equal elements it keeps the one of lowest type,
+// and of those the first, as does the sorting algorithm.
+func uniqueHashed(c Context, v *Vector, hs []uint64) *Vector {
+	type indexedValue struct {
+		i int
+		v Value
+	}
+	var kept []indexedValue
+	index := make(map[uint64][]int) // Positions in kept of the values with each hash.
+	for i, x := range v.All() {
+		found := -1
+		for _, k := range index[hs[i]] {
+			if OrderedCompare(c, kept[k].v, x) == 0 {
+				found = k
+				break
+			}
+		}
+		switch {
+		case found < 0:
+			index[hs[i]] = append(index[hs[i]], len(kept))
+			kept = append(kept, indexedValue{i, x})
+		case whichType(x) < whichType(kept[found].v):
+			kept[found] = indexedValue{i, x}
+		}
+	}
+	// Replacing a value may have put it out of order.
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].i < kept[j].i
+	})
+	elems := newVectorEditor(len(kept), nil)
+	for i, x := range kept {
+		elems.Set(i, x.v)
+	}
+	return elems.Publish()
+}
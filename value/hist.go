@@ -0,0 +1,118 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// hist implements the binary hist operator. If u is a vector, it holds
+// the increasing edges of the bins; otherwise it is the number of bins
+// of equal width spanning the values of v. The result holds the number
+// of values of v in each bin. Each bin includes its lower edge; the last
+// also includes its upper edge. Values outside the bins are not counted.
+func hist(c Context, u, v Value) Value {
+	data := histData(v)
+	var edges []Value
+	switch u := u.(type) {
+	case Int:
+		edges = evenBins(c, int(u), data)
+	case *Vector:
+		if u.Len() < 2 {
+			Errorf("hist: need at least two bin edges")
+		}
+		for i, x := range u.All() {
+			if !isRealScalar(x) {
+				Errorf("hist: bin edges must be real numbers")
+			}
+			if i > 0 && OrderedCompare(c, edges[i-1], x) >= 0 {
+				Errorf("hist: bin edges must increase")
+			}
+			edges = append(edges, x)
+		}
+	default:
+		Errorf("hist: left operand must be a number of bins or a vector of bin edges")
+	}
+	last := len(edges) - 1
+	counts := make([]int, last)
+	for _, x := range data {
+		// Find the first edge above x in the sorted edges.
+		i := sort.Search(len(edges), func(j int) bool {
+			return OrderedCompare(c, edges[j], x) > 0
+		})
+		switch {
+		case i == 0:
+			// Below the first bin.
+		case i <= last:
+			counts[i-1]++
+		case OrderedCompare(c, x, edges[last]) == 0:
+			counts[last-1]++
+		}
+	}
+	return NewIntVector(counts...)
+}
+
+// autoHist implements unary hist, choosing the number of bins by
+// Sturges' rule, 1+ceil(log2 n) for n values.
+func autoHist(c Context, v Value) Value {
+	n := len(histData(v))
+	bins := 1
+	if n > 1 {
+		bins += bits.Len(uint(n - 1))
+	}
+	return hist(c, Int(bins), v)
+}
+
+// histData returns the elements of v, which must be real numbers.
+func histData(v Value) []Value {
+	var data []Value
+	switch v := v.(type) {
+	case *Vector:
+		for _, x := range v.All() {
+			data = append(data, x)
+		}
+	case *Matrix:
+		for _, x := range v.data.All() {
+			data = append(data, x)
+		}
+	default:
+		data = []Value{v}
+	}
+	for _, x := range data {
+		if !isRealScalar(x) {
+			Errorf("hist: values must be real numbers")
+		}
+	}
+	return data
+}
+
+// evenBins returns the edges of n bins of equal width spanning the
+// values in data. If the values are all the same, the bins span
+// the value to one above it.
+func evenBins(c Context, n int, data []Value) []Value {
+	if n < 1 {
+		Errorf("hist: number of bins must be positive")
+	}
+	var lo, hi Value = zero, one
+	for i, x := range data {
+		if i == 0 || OrderedCompare(c, x, lo) < 0 {
+			lo = x
+		}
+		if i == 0 || OrderedCompare(c, x, hi) > 0 {
+			hi = x
+		}
+	}
+	if OrderedCompare(c, lo, hi) == 0 {
+		hi = c.EvalBinary(lo, "+", one)
+	}
+	width := c.EvalBinary(c.EvalBinary(hi, "-", lo), "/", Int(n))
+	edges := make([]Value, n+1)
+	for i := range n {
+		edges[i] = c.EvalBinary(lo, "+", c.EvalBinary(Int(i), "*", width))
+	}
+	edges[n] = hi // Exactly, whatever the rounding.
+	return edges
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import "math/big"
+
+// nanFloat is the big.Float of the value NaN, which )ieee mode produces
+// in place of an error for operations, such as Inf-Inf, that have no
+// meaningful result. A big.Float cannot represent NaN, so NaN is the
+// BigFloat holding this particular pointer.
+var nanFloat = new(big.Float)
+
+// nan is the value NaN.
+var nan = BigFloat{nanFloat}
+
+// isNaN reports whether v is NaN or a complex number with a NaN part.
+func isNaN(v Value) bool {
+	switch v := v.(type) {
+	case BigFloat:
+		return v.Float == nanFloat
+	case Complex:
+		return isNaN(v.real) || isNaN(v.imag)
+	}
+	return false
+}
+
+// isInf reports whether v is infinite or a complex number with an
+// infinite part.
+func isInf(v Value) bool {
+	switch v := v.(type) {
+	case BigFloat:
+		return v.IsInf()
+	case Complex:
+		return isInf(v.real) || isInf(v.imag)
+	}
+	return false
+}
+
+// ieeeUnary evaluates the elementwise unary op on the scalar v in )ieee
+// mode, in which NaN propagates and an operation with no meaningful
+// result yields NaN.
+func ieeeUnary(c Context, op *unaryOp, fn unaryFn, v Value) (result Value) {
+	if isNaN(v) && op.name != "isnan" && op.name != "isinf" {
+		return nan
+	}
+	defer recoverNaN(&result)
+	return fn(c, v)
+}
+
+// ieeeBinary evaluates the elementwise binary op on the scalars u and v
+// in )ieee mode, in which NaN propagates, except that it compares
+// unequal to everything and may be part of a complex number, and an
+// operation with no meaningful result yields NaN.
+func ieeeBinary(c Context, op *binaryOp, fn binaryFn, u, v Value) (result Value) {
+	if isNaN(u) || isNaN(v) {
+		switch op.name {
+		case "==", "<", "<=", ">", ">=":
+			return zero
+		case "!=":
+			return one
+		case "j":
+			return fn(c, u, v)
+		}
+		return nan
+	}
+	defer recoverNaN(&result)
+	return fn(c, u, v)
+}
+
+// recoverNaN sets *result to NaN if the panic being recovered is
+// the big.ErrNaN of an operation with no meaningful result.
+func recoverNaN(result *Value) {
+	if err := recover(); err != nil {
+		if _, ok := err.(big.ErrNaN); !ok {
+			panic(err)
+		}
+		*result = nan
+	}
+}
+
+// checkOverflow errors out if, outside )ieee mode, the result of an
+// operation is infinite.
+func checkOverflow(v Value) Value {
+	if isInf(v) {
+		Errorf("floating-point overflow")
+	}
+	return v
+}
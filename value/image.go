@@ -0,0 +1,192 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"robpike.io/ivy/config"
+)
+
+// Images are matrices of pixel values from 0 to 255. A grayscale image
+// has shape rows cols; a color image has shape rows cols 3, holding the
+// red, green and blue values of each pixel. Files are PNG, or PGM if the
+// name ends .pgm.
+
+// sysReadImage implements sys "readimage" file.
+func sysReadImage(conf *config.Config, args []Value) Value {
+	if len(args) != 1 {
+		Errorf(`usage: sys "readimage" "filename"`)
+	}
+	file := imageFile(conf, "readimage", args[0])
+	f, err := os.Open(file)
+	if err != nil {
+		Errorf("%v", err)
+	}
+	defer f.Close()
+	var img image.Image
+	if isPGM(file) {
+		img, err = readPGM(bufio.NewReader(f))
+	} else {
+		img, err = png.Decode(f)
+	}
+	if err != nil {
+		Errorf("readimage: %s: %v", file, err)
+	}
+	b := img.Bounds()
+	rows, cols := b.Dy(), b.Dx()
+	switch img.ColorModel() {
+	case color.GrayModel, color.Gray16Model:
+		data := newVectorEditor(rows*cols, nil)
+		for y := range rows {
+			for x := range cols {
+				g := color.GrayModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.Gray)
+				data.Set(y*cols+x, Int(g.Y))
+			}
+		}
+		return NewMatrix([]int{rows, cols}, data.Publish())
+	}
+	data := newVectorEditor(rows*cols*3, nil)
+	for y := range rows {
+		for x := range cols {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			i := 3 * (y*cols + x)
+			data.Set(i, Int(c.R))
+			data.Set(i+1, Int(c.G))
+			data.Set(i+2, Int(c.B))
+		}
+	}
+	return NewMatrix([]int{rows, cols, 3}, data.Publish())
+}
+
+// sysWriteImage implements sys "writeimage" file matrix.
+func sysWriteImage(conf *config.Config, args []Value) Value {
+	if len(args) != 2 {
+		Errorf(`usage: sys "writeimage" "filename" matrix`)
+	}
+	file := imageFile(conf, "writeimage", args[0])
+	m, ok := args[1].(*Matrix)
+	if !ok || !(m.Rank() == 2 || m.Rank() == 3 && m.shape[2] == 3) {
+		Errorf("writeimage: image must have shape rows cols or rows cols 3")
+	}
+	rows, cols := m.shape[0], m.shape[1]
+	pixel := func(i int) uint8 {
+		p, ok := m.data.At(i).(Int)
+		if !ok || p < 0 || p > 255 {
+			Errorf("writeimage: pixel value %s not in range 0 to 255", m.data.At(i).Sprint(conf))
+		}
+		return uint8(p)
+	}
+	var img image.Image
+	if m.Rank() == 2 {
+		gray := image.NewGray(image.Rect(0, 0, cols, rows))
+		for i := range gray.Pix {
+			gray.Pix[i] = pixel(i)
+		}
+		img = gray
+	} else {
+		rgb := image.NewNRGBA(image.Rect(0, 0, cols, rows))
+		for i := range rows * cols {
+			copy(rgb.Pix[4*i:], []uint8{pixel(3 * i), pixel(3*i + 1), pixel(3*i + 2), 255})
+		}
+		img = rgb
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		Errorf("%v", err)
+	}
+	if isPGM(file) {
+		gray, ok := img.(*image.Gray)
+		if !ok {
+			f.Close()
+			Errorf("writeimage: PGM image must be grayscale")
+		}
+		err = writePGM(f, gray)
+	} else {
+		err = png.Encode(f, img)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		Errorf("writeimage: %s: %v", file, err)
+	}
+	return NewIntVector(rows, cols)
+}
+
+// imageFile returns the file name in v, checking that images are available.
+func imageFile(conf *config.Config, op string, v Value) string {
+	if conf.Mobile() {
+		Errorf("sys %q not available on mobile platforms", op)
+	}
+	file, ok := v.(*Vector)
+	if !ok || !file.AllChars() {
+		Errorf("%s: file name must be text", op)
+	}
+	return vecText(file)
+}
+
+func isPGM(file string) bool {
+	return strings.EqualFold(filepath.Ext(file), ".pgm")
+}
+
+// readPGM reads a binary (P5) or text (P2) PGM image with
+// 8-bit pixel values.
+func readPGM(r *bufio.Reader) (image.Image, error) {
+	// The header is four fields, perhaps with comments among them.
+	var fields []string
+	for len(fields) < 4 {
+		var word string
+		if _, err := fmt.Fscan(r, &word); err != nil {
+			return nil, fmt.Errorf("bad PGM header: %v", err)
+		}
+		if strings.HasPrefix(word, "#") {
+			r.ReadString('\n')
+			continue
+		}
+		fields = append(fields, word)
+	}
+	var cols, rows, maxVal int
+	_, err := fmt.Sscan(strings.Join(fields[1:], " "), &cols, &rows, &maxVal)
+	if err != nil || cols < 0 || rows < 0 || maxVal <= 0 || maxVal > 255 {
+		return nil, fmt.Errorf("bad or unsupported PGM header")
+	}
+	img := image.NewGray(image.Rect(0, 0, cols, rows))
+	switch fields[0] {
+	case "P5":
+		r.ReadByte() // The single white space character after the header.
+		_, err = io.ReadFull(r, img.Pix)
+	case "P2":
+		for i := range img.Pix {
+			if _, err = fmt.Fscan(r, &img.Pix[i]); err != nil {
+				break
+			}
+		}
+	default:
+		err = fmt.Errorf("not a PGM file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// writePGM writes img as a binary (P5) PGM image.
+func writePGM(w io.Writer, img *image.Gray) error {
+	b := img.Bounds()
+	if _, err := fmt.Fprintf(w, "P5\n%d %d\n255\n", b.Dx(), b.Dy()); err != nil {
+		return err
+	}
+	_, err := w.Write(img.Pix)
+	return err
+}
@@ -221,6 +221,7 @@ func Index(context Context, top, left Expr, index []Expr) Value {
 		return ix.vector.At(offset)
 	}
 
+	checkElems(context, int64(ix.outSize))
 	data := newVectorEditor(ix.outSize, nil)
 	copySize := int(size(ix.shape[ix.indexDim:]))
 	n := data.Len() / copySize
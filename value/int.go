@@ -58,6 +58,11 @@ func (i Int) Rank() int {
 	return 0
 }
 
+// Hash returns the hash of the integer.
+func (i Int) Hash() (uint64, bool) {
+	return hashMix(uint64(i)), true
+}
+
 func (i Int) shrink() Value {
 	return i
 }
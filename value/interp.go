@@ -0,0 +1,112 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import "sort"
+
+// interpolator holds the table for the binary interp operator.
+type interpolator struct {
+	c      Context
+	xs, ys []Value
+	policy string // "clamp", "extend", or "error", for x outside the table.
+}
+
+// interp implements the binary interp operator: u is the table, either
+// xs ys or xs ys 'policy', and v the values at which to interpolate.
+func interp(c Context, u, v Value) Value {
+	t := newInterpolator(c, u)
+	switch v := v.(type) {
+	case *Vector:
+		result := newVectorEditor(v.Len(), nil)
+		for i, x := range v.All() {
+			result.Set(i, t.at(x))
+		}
+		return result.Publish()
+	case *Matrix:
+		result := newVectorEditor(v.data.Len(), nil)
+		for i, x := range v.data.All() {
+			result.Set(i, t.at(x))
+		}
+		return NewMatrix(v.shape, result.Publish())
+	}
+	return t.at(v)
+}
+
+// newInterpolator returns the interpolator for the table u.
+func newInterpolator(c Context, u Value) *interpolator {
+	usage := func() {
+		Errorf("interp: left operand must be xs ys or xs ys 'clamp', 'extend', or 'error'")
+	}
+	table, ok := u.(*Vector)
+	if !ok || table.Len() != 2 && table.Len() != 3 {
+		usage()
+	}
+	xv, okx := table.At(0).(*Vector)
+	yv, oky := table.At(1).(*Vector)
+	if !okx || !oky {
+		usage()
+	}
+	t := &interpolator{c: c, policy: "clamp"}
+	if table.Len() == 3 {
+		p, ok := table.At(2).(*Vector)
+		if !ok || !p.AllChars() {
+			usage()
+		}
+		switch t.policy = vecText(p); t.policy {
+		case "clamp", "extend", "error":
+		default:
+			usage()
+		}
+	}
+	if xv.Len() != yv.Len() {
+		Errorf("interp: length mismatch: %d %d", xv.Len(), yv.Len())
+	}
+	if xv.Len() < 2 {
+		Errorf("interp: need at least two points")
+	}
+	for i, x := range xv.All() {
+		y := yv.At(i)
+		if !isRealScalar(x) || !isRealScalar(y) {
+			Errorf("interp: table must hold real numbers")
+		}
+		if i > 0 && OrderedCompare(c, t.xs[i-1], x) >= 0 {
+			Errorf("interp: xs must increase")
+		}
+		t.xs = append(t.xs, x)
+		t.ys = append(t.ys, y)
+	}
+	return t
+}
+
+// at returns the interpolated value at x.
+func (t *interpolator) at(x Value) Value {
+	c := t.c
+	if !isRealScalar(x) {
+		Errorf("interp: values must be real numbers")
+	}
+	n := len(t.xs)
+	// Find the first point above x; the segment ends there.
+	i := sort.Search(n, func(j int) bool {
+		return OrderedCompare(c, t.xs[j], x) > 0
+	})
+	outside := i == 0 || i == n && OrderedCompare(c, x, t.xs[n-1]) > 0
+	if outside {
+		switch t.policy {
+		case "clamp":
+			if i == 0 {
+				return t.ys[0]
+			}
+			return t.ys[n-1]
+		case "error":
+			Errorf("interp: %s is outside the table", x)
+		}
+	}
+	i = min(max(i, 1), n-1)
+	x0, x1 := t.xs[i-1], t.xs[i]
+	y0, y1 := t.ys[i-1], t.ys[i]
+	// y0 + (x-x0)*(y1-y0)/(x1-x0).
+	slope := c.EvalBinary(c.EvalBinary(y1, "-", y0), "/", c.EvalBinary(x1, "-", x0))
+	return c.EvalBinary(y0, "+", c.EvalBinary(c.EvalBinary(x, "-", x0), "*", slope))
+}
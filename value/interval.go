@@ -0,0 +1,51 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import "sort"
+
+// interval implements bins interval x: for each element of x, the
+// index of the half-open interval [bins[i], bins[i+1]) that holds it.
+// Elements below the first bin give origin-1. The bins must be sorted.
+func interval(c Context, u, v Value) Value {
+	var bins *Vector
+	switch u := u.(type) {
+	case *Vector:
+		bins = u
+	case *Matrix:
+		Errorf("interval: left operand must be a vector")
+	default:
+		bins = oneElemVector(u)
+	}
+	for i := 1; i < bins.Len(); i++ {
+		if OrderedCompare(c, bins.At(i-1), bins.At(i)) > 0 {
+			Errorf("interval: left operand must be sorted")
+		}
+	}
+	origin := c.Config().Origin()
+	index := func(x Value) Value {
+		// The number of bins <= x.
+		n := sort.Search(bins.Len(), func(i int) bool {
+			return OrderedCompare(c, bins.At(i), x) > 0
+		})
+		return Int(n + origin - 1)
+	}
+	switch v := v.(type) {
+	case *Vector:
+		return intervalVector(v, index)
+	case *Matrix:
+		return NewMatrix(v.shape, intervalVector(v.data, index))
+	}
+	return index(v)
+}
+
+// intervalVector applies index to each element of v.
+func intervalVector(v *Vector, index func(Value) Value) *Vector {
+	result := newVectorEditor(v.Len(), nil)
+	for i, x := range v.All() {
+		result.Set(i, index(x))
+	}
+	return result.Publish()
+}
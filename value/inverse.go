@@ -0,0 +1,150 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+// Inverses of the builtin operators, for the inverse operator. The
+// inverse of a binary operator is taken with respect to its right
+// argument: if A f X is B, then A inverse f B is X.
+
+// selfInverse lists the unary operators that undo themselves.
+var selfInverse = map[string]bool{
+	"+":      true,
+	"-":      true,
+	"/":      true,
+	"inv":    true,
+	"conj":   true,
+	"^":      true,
+	"not":    true,
+	"rot":    true,
+	"flip":   true,
+	"transp": true,
+}
+
+// unaryInverse maps unary operators to the operators that undo them.
+var unaryInverse = map[string]string{
+	"**":       "log",
+	"log":      "**",
+	"sin":      "asin",
+	"asin":     "sin",
+	"cos":      "acos",
+	"acos":     "cos",
+	"tan":      "atan",
+	"atan":     "tan",
+	"sinh":     "asinh",
+	"asinh":    "sinh",
+	"cosh":     "acosh",
+	"acosh":    "cosh",
+	"tanh":     "atanh",
+	"atanh":    "tanh",
+	"char":     "code",
+	"code":     "char",
+	"hex":      "unhex",
+	"unhex":    "hex",
+	"base64":   "unbase64",
+	"unbase64": "base64",
+	"box":      "first",
+	"split":    "mix",
+	"mix":      "split",
+}
+
+// unaryInverseFn holds the inverses of unary operators that have no
+// single operator to undo them.
+var unaryInverseFn = map[string]func(Context, Value) Value{
+	"j": func(c Context, v Value) Value {
+		return c.EvalUnary("-", c.EvalUnary("j", v))
+	},
+	"sqrt": func(c Context, v Value) Value {
+		return c.EvalBinary(v, "*", v)
+	},
+	`+\`: func(c Context, v Value) Value {
+		return unscan(c, "-", zero, v)
+	},
+	`*\`: func(c Context, v Value) Value {
+		return unscan(c, "/", one, v)
+	},
+}
+
+// binaryInverseFn holds the inverses of binary operators: given A and
+// B, each returns X such that A op X is B.
+var binaryInverseFn = map[string]func(Context, Value, Value) Value{
+	"+": func(c Context, u, v Value) Value {
+		return c.EvalBinary(v, "-", u)
+	},
+	"-": func(c Context, u, v Value) Value {
+		return c.EvalBinary(u, "-", v)
+	},
+	"*": func(c Context, u, v Value) Value {
+		return c.EvalBinary(v, "/", u)
+	},
+	"/": func(c Context, u, v Value) Value {
+		return c.EvalBinary(u, "/", v)
+	},
+	"**": func(c Context, u, v Value) Value {
+		return c.EvalBinary(u, "log", v)
+	},
+	"log": func(c Context, u, v Value) Value {
+		return c.EvalBinary(u, "**", v)
+	},
+	"root": func(c Context, u, v Value) Value {
+		return c.EvalBinary(v, "**", u)
+	},
+	"xor": func(c Context, u, v Value) Value {
+		return c.EvalBinary(u, "xor", v)
+	},
+	"rot": func(c Context, u, v Value) Value {
+		return c.EvalBinary(c.EvalUnary("-", u), "rot", v)
+	},
+	"flip": func(c Context, u, v Value) Value {
+		return c.EvalBinary(c.EvalUnary("-", u), "flip", v)
+	},
+	"transp": func(c Context, u, v Value) Value {
+		return c.EvalBinary(c.EvalUnary("up", u), "transp", v)
+	},
+	"encode": func(c Context, u, v Value) Value {
+		return c.EvalBinary(u, "decode", v)
+	},
+	"decode": func(c Context, u, v Value) Value {
+		return c.EvalBinary(u, "encode", v)
+	},
+	"part": func(c Context, u, v Value) Value {
+		return c.EvalUnary("raze", v)
+	},
+}
+
+// InverseUnary applies the inverse of the builtin unary operator op to v.
+func InverseUnary(c Context, op string, v Value) Value {
+	if selfInverse[op] {
+		return c.EvalUnary(op, v)
+	}
+	if inv, ok := unaryInverse[op]; ok {
+		return c.EvalUnary(inv, v)
+	}
+	if fn := unaryInverseFn[op]; fn != nil {
+		return fn(c, v)
+	}
+	Errorf("no inverse for unary %s", op)
+	panic("not reached")
+}
+
+// InverseBinary applies the inverse of the builtin binary operator op
+// to u and v, returning X such that u op X is v.
+func InverseBinary(c Context, u Value, op string, v Value) Value {
+	if fn := binaryInverseFn[op]; fn != nil {
+		return fn(c, u, v)
+	}
+	Errorf("no inverse for binary %s", op)
+	panic("not reached")
+}
+
+// unscan undoes the scan of v by the operator whose inverse is op,
+// combining each element with its predecessor; the first element is
+// combined with id, the identity.
+func unscan(c Context, op string, id, v Value) Value {
+	if v.Rank() > 1 {
+		Errorf("inverse of scan: rank %d argument", v.Rank())
+	}
+	prev := c.EvalBinary(id, ",", c.EvalBinary(Int(-1), "drop", v))
+	return c.EvalBinary(v, op, prev)
+}
@@ -27,13 +27,19 @@ type loop struct {
 func newLoop(conf *config.Config, name string, x *big.Float, itersPerBit uint) *loop {
 	return &loop{
 		name:          name,
-		arg:           newF(conf).Set(x),
+		arg:           tempF(conf).Set(x),
 		maxIterations: 10 + uint64(itersPerBit*conf.FloatPrec()),
-		prevZ:         newF(conf),
-		delta:         newF(conf),
+		prevZ:         tempF(conf),
+		delta:         tempF(conf),
 	}
 }
 
+// release returns the loop's temporaries to the pool.
+// The loop must not be used afterwards.
+func (l *loop) release() {
+	releaseF(l.arg, l.prevZ, l.delta)
+}
+
 // done reports whether the loop is done. If it does not converge
 // after the maximum number of iterations, it errors out.
 // It will not return before doing at least 3 iterations. Some
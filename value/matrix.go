@@ -38,6 +38,11 @@ func (m *Matrix) Rank() int {
 	return len(m.shape)
 }
 
+// Hash reports that a matrix has no hash; only scalars are hashed.
+func (m *Matrix) Hash() (uint64, bool) {
+	return 0, false
+}
+
 func (m *Matrix) shrink() Value {
 	if len(m.shape) == 1 {
 		return m.data
@@ -86,29 +91,27 @@ func (w *widths) column(i int) int {
 
 // elemStrs returns the formatted elements of the matrix and the width of the widest element.
 // Each element is represented by a slice of lines, that is, the return value is indexed by
-// [elem][line].
+// [elem][line]. The lines of an element all have the same width, and every element has the
+// same number of lines.
 func (m *Matrix) elemStrs(conf *config.Config) ([][]string, *widths) {
-	// Format the matrix as a vector, and then in write2d we rearrange the pieces.
-	// In the formatting, there's no need for spacing the elements as we'll cut
-	// them apart ourselves using column information. Spaces will be added
-	// when needed in write2d.
-	v := m.data
-	lines, cols := v.multiLineSprint(conf, v.allScalars(), v.AllChars(), !withSpaces, !trimTrailingSpace)
+	// Format each element on its own, and then in write2d we arrange the
+	// pieces. Spaces will be added when needed in write2d.
+	allScalars := m.data.allScalars()
 	strs := make([][]string, m.data.Len())
+	height := 0
+	for i, elem := range m.data.All() {
+		strs[i] = elemLines(conf, elem, !allScalars)
+		height = max(height, len(strs[i]))
+	}
 	wid := widths{}
 	lastDim := m.shape[len(m.shape)-1]
-	for i := range m.data.All() {
-		rows := make([]string, len(lines))
-		for j, line := range lines {
-			if i == 0 {
-				rows[j] = line[:cols[0]]
-				wid.addColumn(0, len(rows[j]))
-			} else {
-				rows[j] = line[cols[i-1]:cols[i]]
-				wid.addColumn(i%lastDim, len(rows[j]))
-			}
+	for i, rows := range strs {
+		w := textWidth(rows[0])
+		for len(rows) < height {
+			rows = append(rows, blanks(w))
 		}
 		strs[i] = rows
+		wid.addColumn(i%lastDim, w)
 	}
 	return strs, &wid
 }
@@ -116,9 +119,12 @@ func (m *Matrix) elemStrs(conf *config.Config) ([][]string, *widths) {
 // write2d prints the 2d matrix m into the buffer.
 // elems is a slice (of slices) of already-printed values.
 // The receiver provides only the shape of the matrix.
-func (m *Matrix) write2d(b *bytes.Buffer, elems [][]string, nested bool, wid *widths) {
+// Columns are aligned and separated as configured, and "..." marks
+// the rows and columns removed by elide.
+func (m *Matrix) write2d(conf *config.Config, b *bytes.Buffer, elems [][]string, nested bool, wid *widths, cut elision) {
 	nrows := m.shape[0]
 	ncols := m.shape[1]
+	sep := conf.ColumnSep()
 	index := 0
 	for row := 0; row < nrows; row++ {
 		if row > 0 {
@@ -145,19 +151,98 @@ func (m *Matrix) write2d(b *bytes.Buffer, elems [][]string, nested bool, wid *wi
 			if line > 0 {
 				b.WriteByte('\n')
 			}
+			var text strings.Builder
 			for col := 0; col < ncols; col++ {
 				str := elems[index+col][line]
-				b.WriteString(blanks(wid.column(col) - len(str)))
-				b.WriteString(str)
+				pad := wid.column(col) - textWidth(str)
+				switch conf.ColumnAlign(col) {
+				case "left":
+					text.WriteString(str)
+					text.WriteString(blanks(pad))
+				case "center":
+					text.WriteString(blanks(pad / 2))
+					text.WriteString(str)
+					text.WriteString(blanks(pad - pad/2))
+				default:
+					text.WriteString(blanks(pad))
+					text.WriteString(str)
+				}
+				if col == cut.col {
+					text.WriteString(sep)
+					if line == 0 {
+						text.WriteString("...")
+					} else {
+						text.WriteString("   ")
+					}
+				}
 				if (col+1)%ncols != 0 {
-					b.WriteString(" ")
+					text.WriteString(sep)
 				}
 			}
+			b.WriteString(strings.TrimRight(text.String(), " "))
+		}
+		if row == cut.row {
+			b.WriteString("\n...")
 		}
 		index += ncols
 	}
 }
 
+// elision records where to print "..." in place of the rows and columns
+// removed from a matrix too large to display in full. The marks go after
+// row and column number row and col of the smaller matrix; -1 means none.
+type elision struct {
+	row, col int
+}
+
+// elide returns the matrix m, of rank 2 or more, reduced so each 2d plane
+// has at most the number of rows and columns set by )display, keeping the
+// first and last ones, and reports where the removed ones were.
+func (m *Matrix) elide(conf *config.Config) (*Matrix, elision) {
+	cut := elision{-1, -1}
+	nrows := m.shape[len(m.shape)-2]
+	ncols := m.shape[len(m.shape)-1]
+	rows := keep(nrows, conf.MaxRows(), &cut.row)
+	cols := keep(ncols, conf.MaxCols(), &cut.col)
+	if len(rows) == nrows && len(cols) == ncols {
+		return m, cut
+	}
+	planeSize := nrows * ncols
+	data := newVectorEditor(0, nil)
+	for plane := 0; plane < m.data.Len(); plane += planeSize {
+		for _, r := range rows {
+			for _, c := range cols {
+				data.Append(m.data.At(plane + r*ncols + c))
+			}
+		}
+	}
+	shape := slices.Clone(m.shape)
+	shape[len(shape)-2] = len(rows)
+	shape[len(shape)-1] = len(cols)
+	return NewMatrix(shape, data.Publish()), cut
+}
+
+// keep returns the indexes of the first and last of n rows or columns
+// to print if at most max of them fit, setting *cut to the index, in
+// the result, of the last one before the gap. Max 0 means no limit.
+func keep(n, max int, cut *int) []int {
+	if max <= 0 || n <= max {
+		max = n
+	}
+	head := (max + 1) / 2
+	index := make([]int, 0, max)
+	for i := 0; i < head; i++ {
+		index = append(index, i)
+	}
+	for i := n - (max - head); i < n; i++ {
+		index = append(index, i)
+	}
+	if max < n {
+		*cut = head - 1
+	}
+	return index
+}
+
 func (m *Matrix) fprintf(c Context, w io.Writer, format string) {
 	rank := len(m.shape)
 	if rank == 0 || m.data.Len() == 0 {
@@ -195,6 +280,12 @@ func (m *Matrix) String() string {
 }
 
 func (m *Matrix) Sprint(conf *config.Config) string {
+	if conf.Raw() {
+		return rawSprint(conf, m)
+	}
+	if conf.Tree() && !m.data.allScalars() {
+		return treeSprint(conf, m)
+	}
 	// If the matrix is mostly nested elements, space it out a bit more.
 	numNested := 0
 	for _, e := range m.data.All() {
@@ -213,23 +304,31 @@ func (m *Matrix) Sprint(conf *config.Config) string {
 	case 1:
 		return m.data.Sprint(conf)
 	case 2:
-		nrows := m.shape[0]
-		ncols := m.shape[1]
-		if nrows == 0 || ncols == 0 {
+		if m.shape[0] == 0 || m.shape[1] == 0 {
 			return ""
 		}
+		m, cut := m.elide(conf)
+		nrows := m.shape[0]
+		ncols := m.shape[1]
 		// If it's all chars, print it without padding or quotes.
 		if m.data.AllChars() {
 			for i := 0; i < nrows; i++ {
 				if i > 0 {
 					b.WriteByte('\n')
 				}
-				fmt.Fprintf(&b, "%s", NewVectorSeq(m.data.Slice(i*ncols, (i+1)*ncols)).Sprint(conf))
+				row := []rune(NewVectorSeq(m.data.Slice(i*ncols, (i+1)*ncols)).Sprint(conf))
+				if cut.col >= 0 {
+					row = slices.Insert(row, cut.col+1, []rune("...")...)
+				}
+				b.WriteString(string(row))
+				if i == cut.row {
+					b.WriteString("\n...")
+				}
 			}
 			break
 		}
 		strs, width := m.elemStrs(conf)
-		m.write2d(&b, strs, nested, width)
+		m.write2d(conf, &b, strs, nested, width, cut)
 	case 3:
 		// If it's all chars, print it without padding or quotes.
 		if m.data.AllChars() {
@@ -247,6 +346,7 @@ func (m *Matrix) Sprint(conf *config.Config) string {
 		}
 		// As for 2d: print the vector elements, compute the
 		// global width, and use that to print each 2d submatrix.
+		m, cut := m.elide(conf)
 		n2d := m.shape[0]    // number of 2d submatrices.
 		size := m.ElemSize() // number of elems in each submatrix.
 		strs, width := m.elemStrs(conf)
@@ -259,7 +359,7 @@ func (m *Matrix) Sprint(conf *config.Config) string {
 				shape: m.shape[1:],
 				// no data; write2d uses strs, not data
 			}
-			m.write2d(&b, strs[start:start+size], nested, width)
+			m.write2d(conf, &b, strs[start:start+size], nested, width, cut)
 			start += size
 		}
 	default:
@@ -388,12 +488,6 @@ func (m *Matrix) toType(op string, conf *config.Config, which valueType) Value {
 	return nil
 }
 
-func (x *Matrix) sameShape(y *Matrix) {
-	if !sameShape(x.shape, y.shape) {
-		Errorf("shape mismatch: %s != %s", NewIntVector(x.shape...), NewIntVector(y.shape...))
-	}
-}
-
 func sameShape(x, y []int) bool {
 	if len(x) != len(y) {
 		return false
@@ -408,10 +502,10 @@ func sameShape(x, y []int) bool {
 
 // reshape implements binary rho
 // A⍴B: Array of shape A with data B
-func reshape(A, B *Vector) Value {
+func reshape(c Context, A, B *Vector) Value {
 	if B.Len() == 0 {
 		// Peculiar APL definition of reshape of empty vector: Use fill values.
-		B = NewIntVector(0)
+		B = NewVector(fillValue(B))
 	}
 	if A.Len() == 0 {
 		return NewVector()
@@ -429,6 +523,7 @@ func reshape(A, B *Vector) Value {
 		}
 		shape[i] = int(n)
 	}
+	checkElems(c, int64(nelems))
 	t := newVectorEditor(int(nelems), nil)
 	blen := B.Len()
 	for i := 0; i < blen && i < int(nelems); i++ {
@@ -743,6 +838,33 @@ func (x *Matrix) catenateFirst(y *Matrix) *Matrix {
 	return NewMatrix(shape, data.Publish())
 }
 
+// laminate implements A lam B, which joins A and B along a new first
+// axis, so the result has shape 2, rho A. The operands must have the
+// same shape, but a scalar is extended to the shape of the other.
+func laminate(c Context, u, v Value) Value {
+	if IsScalarType(u) && IsScalarType(v) {
+		return NewVector(u, v)
+	}
+	var x, y *Matrix
+	if !IsScalarType(u) {
+		x = u.toType("lam", c.Config(), matrixType).(*Matrix)
+	}
+	if !IsScalarType(v) {
+		y = v.toType("lam", c.Config(), matrixType).(*Matrix)
+	}
+	switch {
+	case x == nil:
+		x = NewMatrix(y.shape, NewVectorSeq(repeat(u, y.data.Len())))
+	case y == nil:
+		y = NewMatrix(x.shape, NewVectorSeq(repeat(v, x.data.Len())))
+	case !sameShape(x.shape, y.shape):
+		Errorf("lam: shape mismatch: %s and %s", NewIntVector(x.shape...), NewIntVector(y.shape...))
+	}
+	shape := append([]int{2}, x.shape...)
+	checkElems(c, int64(size(shape)))
+	return NewMatrix(shape, NewVectorSeq(x.data.All(), y.data.All()))
+}
+
 // sel returns the selection of m according to v.
 // The selection applies to the final axis.
 func (m *Matrix) sel(c Context, v *Vector) *Matrix {
@@ -773,9 +895,7 @@ func (m *Matrix) sel(c Context, v *Vector) *Matrix {
 	for _, dim := range shape[:len(shape)-1] {
 		count *= int64(dim)
 	}
-	if count > 1e8 {
-		Errorf("sel: result too large: %d elements", count)
-	}
+	checkElems(c, count)
 
 	result := newVectorEditor(0, nil)
 	for i, y := range m.data.All() {
@@ -792,6 +912,74 @@ func (m *Matrix) sel(c Context, v *Vector) *Matrix {
 	return NewMatrix(shape, result.Publish())
 }
 
+// fill returns the expansion of m according to v.
+// The expansion applies to the final axis.
+func (m *Matrix) fill(c Context, v *Vector) *Matrix {
+	cols := m.shape[len(m.shape)-1]
+	count := fillCount(v, cols)
+	shape := slices.Clone(m.shape)
+	shape[len(shape)-1] = int(count)
+	checkElems(c, int64(m.data.Len()/max(cols, 1))*count)
+	result := newVectorEditor(0, nil)
+	zeroVal := fillValue(m.data)
+	for i := 0; i < m.data.Len(); i += cols {
+		NewVectorSeq(m.data.Slice(i, i+cols)).appendFill(result, v, zeroVal)
+	}
+	return NewMatrix(shape, result.Publish())
+}
+
+// selEach returns the selection of m according to count, which has the
+// same shape as m. Each element of m is replicated by the corresponding
+// element of count. The rows of the result must all have the same length.
+func (m *Matrix) selEach(c Context, count *Matrix) *Matrix {
+	cols := m.shape[len(m.shape)-1]
+	checkElems(c, selCount(count.data, count.data.Len()))
+	result := newVectorEditor(0, nil)
+	rowLen := -1
+	for i := 0; i < m.data.Len(); i += cols {
+		row := NewVectorSeq(m.data.Slice(i, i+cols)).sel(c, NewVectorSeq(count.data.Slice(i, i+cols)), cols)
+		if rowLen >= 0 && row.Len() != rowLen {
+			Errorf("sel: rows of result differ in length: %d and %d", rowLen, row.Len())
+		}
+		rowLen = row.Len()
+		result.Append(row.ro...)
+	}
+	shape := slices.Clone(m.shape)
+	shape[len(shape)-1] = max(rowLen, 0)
+	return NewMatrix(shape, result.Publish())
+}
+
+// where returns the elements of m at the positions of the non-zero
+// elements of the matrix n, each repeated that many times, with their
+// coordinates. Each row of the result holds the coordinates of an
+// element followed by the element.
+func (m *Matrix) where(c Context, n *Matrix) *Matrix {
+	if !slices.Equal(m.shape, n.shape) {
+		Errorf("where: shape mismatch: %s %s", NewIntVector(m.shape...), NewIntVector(n.shape...))
+	}
+	checkElems(c, whereCount(n.data)*int64(len(m.shape)+1))
+	result := newVectorEditor(0, nil)
+	rows := 0
+	coords := make([]int, len(m.shape)) // Zero-indexed.
+	origin := c.Config().Origin()
+	for i := range n.data.Len() {
+		for range n.data.uintAt(i, "where argument") {
+			for _, x := range coords {
+				result.Append(Int(x + origin))
+			}
+			result.Append(m.data.At(i))
+			rows++
+		}
+		for j := len(coords) - 1; j >= 0; j-- {
+			if coords[j]++; coords[j] < m.shape[j] {
+				break
+			}
+			coords[j] = 0
+		}
+	}
+	return NewMatrix([]int{rows, len(m.shape) + 1}, result.Publish())
+}
+
 // take returns v take m.
 func (m *Matrix) take(c Context, v *Vector) *Matrix {
 	if !v.AllInts() {
@@ -850,6 +1038,7 @@ func (m *Matrix) take(c Context, v *Vector) *Matrix {
 	if count > maxInt { // Do this before allocating!
 		Errorf("take: result matrix too large")
 	}
+	checkElems(c, count)
 
 	// TODO Is there a faster way?
 	fill := fillValue(m.data)
@@ -914,6 +1103,30 @@ func (m *Matrix) partition(scoreM *Matrix) Value {
 	return NewMatrix(newShape, res)
 }
 
+// raze returns m with the items along its last axis joined, undoing a
+// partition. Each row must join to a vector of the same length.
+func (m *Matrix) raze(c Context) *Matrix {
+	cols := m.shape[len(m.shape)-1]
+	result := newVectorEditor(0, nil)
+	dim := -1
+	for i := 0; i < m.data.Len(); i += cols {
+		row, ok := NewVectorSeq(m.data.Slice(i, i+cols)).raze(c).(*Vector)
+		if !ok {
+			Errorf("raze: matrix item in a matrix")
+		}
+		if dim >= 0 && row.Len() != dim {
+			Errorf("raze: rows have different lengths %d and %d", dim, row.Len())
+		}
+		dim = row.Len()
+		for _, e := range row.All() {
+			result.Append(e)
+		}
+	}
+	shape := slices.Clone(m.shape)
+	shape[len(shape)-1] = max(dim, 0)
+	return NewMatrix(shape, result.Publish())
+}
+
 // drop returns v drop m.
 func (m *Matrix) drop(c Context, v *Vector) *Matrix {
 	// Extend short vector to full rank using zeros.
@@ -957,11 +1170,31 @@ func (m *Matrix) split() Value {
 		// TODO?
 		Errorf("cannot split rank %d matrix", len(m.shape))
 	}
-	// Matrix of vectors.
-	shape, n := m.shape[:len(m.shape)-1], m.shape[len(m.shape)-1]
+	return m.splitN(1)
+}
+
+// splitN reduces the matrix by n dimensions, returning the cells
+// formed by the last n axes, as a vector or matrix.
+func (m *Matrix) splitN(n int) Value {
+	if n < 0 || n > len(m.shape) {
+		Errorf("split: bad level %d for rank %d matrix", n, len(m.shape))
+	}
+	if n == 0 {
+		return m
+	}
+	shape, cellShape := m.shape[:len(m.shape)-n], m.shape[len(m.shape)-n:]
+	cellSize := size(cellShape)
 	mData := newVectorEditor(size(shape), nil)
 	for i := range mData.Len() {
-		mData.Set(i, NewVectorSeq(m.data.Slice(i*n, (i+1)*n)))
+		cell := NewVectorSeq(m.data.Slice(i*cellSize, (i+1)*cellSize))
+		if len(cellShape) == 1 {
+			mData.Set(i, cell)
+		} else {
+			mData.Set(i, NewMatrix(cellShape, cell))
+		}
+	}
+	if len(shape) == 0 {
+		return mData.Publish() // A single cell, enclosed.
 	}
 	return NewMatrix(shape, mData.Publish()).shrink()
 }
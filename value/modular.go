@@ -0,0 +1,83 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import "math/big"
+
+// Modular arithmetic on integers of any size. Results are always in
+// the range 0 to M-1 for modulus M.
+
+// invmod implements A invmod M, the inverse of A modulo M.
+func invmod(c Context, u, v Value) Value {
+	a, m := u.(BigInt), v.(BigInt)
+	if m.Sign() <= 0 {
+		Errorf("invmod: modulus must be positive")
+	}
+	z := new(big.Int).Mod(a.Int, m.Int)
+	if z.ModInverse(z, m.Int) == nil {
+		Errorf("invmod: %s has no inverse mod %s", a.Int, m.Int)
+	}
+	return BigInt{z}.shrink()
+}
+
+// powmod implements B powmod E M, which is (B**E) mod M computed without
+// forming B**E. A negative exponent uses the inverse of B.
+func powmod(c Context, u, v Value) Value {
+	e, m := powmodArgs(v)
+	var pow func(Value) Value
+	pow = func(x Value) Value {
+		var b *big.Int
+		switch x := x.(type) {
+		case Int:
+			b = big.NewInt(int64(x))
+		case BigInt:
+			b = x.Int
+		case *Vector:
+			result := newVectorEditor(x.Len(), nil)
+			for i, e := range x.All() {
+				result.Set(i, pow(e))
+			}
+			return result.Publish()
+		case *Matrix:
+			return NewMatrix(x.shape, pow(x.data).(*Vector))
+		default:
+			Errorf("powmod: non-integer value %s", x)
+		}
+		z := new(big.Int).Mod(b, m)
+		if z.Exp(z, e, m) == nil {
+			Errorf("powmod: %s has no inverse mod %s", b, m)
+		}
+		return BigInt{z}.shrink()
+	}
+	return pow(u)
+}
+
+// powmodArgs returns the exponent and modulus from the right operand of powmod.
+func powmodArgs(v Value) (e, m *big.Int) {
+	vec, ok := v.(*Vector)
+	if !ok || vec.Len() != 2 {
+		Errorf("powmod: right operand must be exponent and modulus")
+	}
+	for i, x := range vec.All() {
+		var b *big.Int
+		switch x := x.(type) {
+		case Int:
+			b = big.NewInt(int64(x))
+		case BigInt:
+			b = x.Int
+		default:
+			Errorf("powmod: right operand must be exponent and modulus")
+		}
+		if i == 0 {
+			e = b
+		} else {
+			m = b
+		}
+	}
+	if m.Sign() <= 0 {
+		Errorf("powmod: modulus must be positive")
+	}
+	return e, m
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+// plot implements plot B, which draws the vector B, or each row of the
+// matrix B, against the indices of its elements, using the Plotter in the
+// configuration. The result is the name of the file written.
+func plot(c Context, v Value) Value {
+	ys := plotSeries(c, v)
+	xs := make([]float64, len(ys[0]))
+	for i := range xs {
+		xs[i] = float64(i + c.Config().Origin())
+	}
+	return drawPlot(c, xs, ys)
+}
+
+// plotXY implements A plot B, which draws B as for plot B but against
+// the values of the vector A.
+func plotXY(c Context, u, v Value) Value {
+	xs := plotSeries(c, u)
+	ys := plotSeries(c, v)
+	if len(xs) != 1 || len(xs[0]) != len(ys[0]) {
+		Errorf("plot: length mismatch")
+	}
+	return drawPlot(c, xs[0], ys)
+}
+
+func drawPlot(c Context, xs []float64, ys [][]float64) Value {
+	conf := c.Config()
+	plotter := conf.Plotter()
+	if plotter == nil || conf.Sandbox() {
+		Errorf("plot: plotting not available")
+	}
+	file := conf.PlotFile()
+	if err := plotter.Plot(file, xs, ys); err != nil {
+		Errorf("plot: %v", err)
+	}
+	return newCharVector(file)
+}
+
+// plotSeries returns the real values of the vector v, or of each row of
+// the matrix v, as float64s.
+func plotSeries(c Context, v Value) [][]float64 {
+	conf := c.Config()
+	row := func(vec *Vector) []float64 {
+		if vec.Len() == 0 {
+			Errorf("plot: no data")
+		}
+		vals := make([]float64, vec.Len())
+		for i, x := range vec.All() {
+			switch x.(type) {
+			case Int, BigInt, BigRat, BigFloat:
+				vals[i], _ = x.toType("plot", conf, bigFloatType).(BigFloat).Float64()
+			default:
+				Errorf("plot: non-real value %s", x.Sprint(conf))
+			}
+		}
+		return vals
+	}
+	switch v := v.(type) {
+	case *Vector:
+		return [][]float64{row(v)}
+	case *Matrix:
+		if v.Rank() != 2 {
+			Errorf("plot: rank %d value", v.Rank())
+		}
+		ncols := v.shape[1]
+		var rows [][]float64
+		for i := 0; i < v.shape[0]; i++ {
+			rows = append(rows, row(NewVectorSeq(v.data.Slice(i*ncols, (i+1)*ncols))))
+		}
+		if len(rows) == 0 {
+			Errorf("plot: no data")
+		}
+		return rows
+	}
+	return [][]float64{row(NewVector(v))}
+}
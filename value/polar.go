@@ -0,0 +1,61 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math/big"
+
+	"robpike.io/ivy/config"
+)
+
+// polar implements r polar theta: the complex number with magnitude r
+// and phase theta, in radians.
+func polar(c Context, u, v Value) Value {
+	re := c.EvalBinary(u, "*", c.EvalUnary("cos", v))
+	im := c.EvalBinary(u, "*", c.EvalUnary("sin", v))
+	return NewComplex(re, im).shrink()
+}
+
+// polar returns the magnitude and phase of c. It needs only the
+// configuration, so complex numbers can be printed in polar form.
+func (c Complex) polar(conf *config.Config) (mag, phase Value) {
+	x := c.real.toType("polar", conf, bigFloatType).(BigFloat).Float
+	y := c.imag.toType("polar", conf, bigFloatType).(BigFloat).Float
+	m := newF(conf).Mul(x, x)
+	m.Add(m, newF(conf).Mul(y, y))
+	m.Sqrt(m)
+	var p *big.Float
+	switch {
+	case x.Sign() == 0 && y.Sign() == 0:
+		p = newF(conf)
+	case x.Sign() == 0 && y.Sign() > 0:
+		p = newF(conf).Set(floatPiBy2)
+	case x.Sign() == 0:
+		p = newF(conf).Set(floatMinusPiBy2)
+	default:
+		// Atan gives the phase in the right half plane; move it
+		// across for the left.
+		p = floatAtan(confContext{conf: conf}, newF(conf).Quo(y, x))
+		switch {
+		case x.Sign() < 0 && y.Sign() >= 0:
+			p.Add(p, floatPi)
+		case x.Sign() < 0:
+			p.Sub(p, floatPi)
+		}
+	}
+	return BigFloat{m}.shrink(), BigFloat{p}.shrink()
+}
+
+// confContext is a Context that provides only a configuration. It is
+// enough for floating-point functions such as floatAtan when there is
+// no Context to hand, as when printing.
+type confContext struct {
+	Context
+	conf *config.Config
+}
+
+func (c confContext) Config() *config.Config {
+	return c.conf
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math/big"
+	"sync"
+
+	"robpike.io/ivy/config"
+)
+
+// Pools of big.Int and big.Float temporaries for the series,
+// iterations, reductions and inner products that compute a single
+// result from many intermediates. A temporary must be released only
+// by the code that took it, once nothing refers to it; a value that
+// escapes into a result must not come from a pool. The counts of
+// temporaries taken are kept in the configuration; see Config.TempStats.
+
+var (
+	floatPool sync.Pool
+	intPool   sync.Pool
+)
+
+// tempF returns a zero big.Float temporary at the configured precision.
+func tempF(conf *config.Config) *big.Float {
+	f, ok := floatPool.Get().(*big.Float)
+	conf.CountTemp(ok)
+	if ok {
+		return f.SetPrec(conf.FloatPrec()).SetInt64(0)
+	}
+	return newF(conf)
+}
+
+// tempI returns a zero big.Int temporary.
+func tempI(conf *config.Config) *big.Int {
+	i, ok := intPool.Get().(*big.Int)
+	conf.CountTemp(ok)
+	if ok {
+		return i.SetInt64(0)
+	}
+	return new(big.Int)
+}
+
+// releaseF returns temporaries from tempF to the pool.
+func releaseF(fs ...*big.Float) {
+	for _, f := range fs {
+		floatPool.Put(f)
+	}
+}
+
+// releaseI returns temporaries from tempI to the pool.
+func releaseI(is ...*big.Int) {
+	for _, i := range is {
+		intPool.Put(i)
+	}
+}
@@ -102,13 +102,16 @@ func floatPower(c Context, bx, bexp BigFloat) Value {
 func exponential(conf *config.Config, x *big.Float) *big.Float {
 	// The Taylor series for e**x, exp(x), is 1 + x + x²/2! + x³/3! ...
 
-	xN := newF(conf).Set(x)
-	term := newF(conf)
-	n := newF(conf)
-	nFactorial := newF(conf).SetUint64(1)
+	xN := tempF(conf).Set(x)
+	term := tempF(conf)
+	n := tempF(conf)
+	nFactorial := tempF(conf).SetUint64(1)
 	z := newF(conf).SetInt64(1)
 
-	for loop := newLoop(conf, "exponential", x, 10); ; { // Big exponentials converge slowly.
+	defer releaseF(xN, term, n, nFactorial)
+	loop := newLoop(conf, "exponential", x, 10) // Big exponentials converge slowly.
+	defer loop.release()
+	for {
 		term.Set(xN)
 		term.Quo(term, nFactorial)
 		z.Add(z, term)
@@ -0,0 +1,94 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import "math/big"
+
+// Digit representations of integers in unusual bases. Unlike encode,
+// these produce as many digits as the value needs, and the digits are
+// such that decode turns them back into the value: A decode A radix B
+// is B, as is 3 decode baltern B.
+
+// radix implements A radix B, the digits of the integer B in base A, most
+// significant first. The base may be negative, so -2 radix 6 is 1 1 0 1 0.
+// In a positive base, the digits of a negative number are all negative.
+func radix(c Context, u, v Value) Value {
+	base := radixBase(u)
+	return eachInt("radix", v, func(n *big.Int) []*big.Int {
+		neg := base.Sign() > 0 && n.Sign() < 0
+		if neg {
+			n.Neg(n)
+		}
+		var digits []*big.Int
+		for n.Sign() != 0 {
+			// DivMod is Euclidean, so the digit is in [0, |base|).
+			d := new(big.Int)
+			n.DivMod(n, base, d)
+			if neg {
+				d.Neg(d)
+			}
+			digits = append(digits, d)
+		}
+		return digits
+	})
+}
+
+// radixBase returns the base given as the left operand of radix.
+func radixBase(u Value) *big.Int {
+	if b, ok := u.(Int); ok && (b >= 2 || b <= -2) {
+		return big.NewInt(int64(b))
+	}
+	Errorf("radix: base must be small integer with magnitude at least 2")
+	panic("not reached")
+}
+
+// baltern returns the balanced ternary digits of the integer v, most
+// significant first. Each digit is -1, 0, or 1, so baltern 5 is 1 -1 -1.
+func baltern(c Context, v Value) Value {
+	three := big.NewInt(3)
+	return eachInt("baltern", v, func(n *big.Int) []*big.Int {
+		var digits []*big.Int
+		for n.Sign() != 0 {
+			d := new(big.Int)
+			n.DivMod(n, three, d)
+			if d.Int64() == 2 {
+				d.SetInt64(-1)
+				n.Add(n, big.NewInt(1))
+			}
+			digits = append(digits, d)
+		}
+		return digits
+	})
+}
+
+// eachInt applies fn, which returns digits least significant first, to the
+// integer v or to each element of the vector v. The result for an integer is
+// its digit vector, which is a single 0 for zero.
+func eachInt(op string, v Value, fn func(*big.Int) []*big.Int) Value {
+	var n *big.Int
+	switch v := v.(type) {
+	case Int:
+		n = big.NewInt(int64(v))
+	case BigInt:
+		n = new(big.Int).Set(v.Int)
+	case *Vector:
+		result := newVectorEditor(v.Len(), nil)
+		for i, x := range v.All() {
+			result.Set(i, eachInt(op, x, fn))
+		}
+		return result.Publish()
+	default:
+		Errorf("%s: non-integer value %s", op, v)
+	}
+	digits := fn(n)
+	if len(digits) == 0 {
+		return NewIntVector(0)
+	}
+	result := newVectorEditor(len(digits), nil)
+	for i, d := range digits {
+		result.Set(len(digits)-1-i, BigInt{d}.shrink())
+	}
+	return result.Publish()
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"strings"
+
+	"robpike.io/ivy/config"
+)
+
+// rawSprint returns the formatting of v for )display raw, which is meant
+// to be read by other programs. Text prints as is. Otherwise a vector
+// prints one element per line, and a matrix one row per line with its
+// elements separated by tabs. Nothing is padded or aligned.
+func rawSprint(conf *config.Config, v Value) string {
+	var rowLen int
+	var data *Vector
+	switch v := v.(type) {
+	case *Vector:
+		if v.AllChars() {
+			return charsText(v.All())
+		}
+		rowLen, data = 1, v
+	case *Matrix:
+		rowLen, data = v.shape[len(v.shape)-1], v.data
+	default:
+		return v.Sprint(conf)
+	}
+	if rowLen == 0 {
+		return ""
+	}
+	sep := "\t"
+	if data.AllChars() {
+		sep = ""
+	}
+	var b strings.Builder
+	for i, elem := range data.All() {
+		switch {
+		case i == 0:
+		case i%rowLen == 0:
+			b.WriteByte('\n')
+		default:
+			b.WriteString(sep)
+		}
+		b.WriteString(rawElem(conf, elem))
+	}
+	return b.String()
+}
+
+// rawElem returns the formatting of an element for rawSprint. A nested
+// vector or matrix prints on one line, its elements within parentheses.
+func rawElem(conf *config.Config, v Value) string {
+	var data *Vector
+	switch v := v.(type) {
+	case *Vector:
+		if v.AllChars() {
+			return charsText(v.All())
+		}
+		data = v
+	case *Matrix:
+		data = v.data
+	default:
+		return v.Sprint(conf)
+	}
+	elems := make([]string, data.Len())
+	for i, elem := range data.All() {
+		elems[i] = rawElem(conf, elem)
+	}
+	return "(" + strings.Join(elems, " ") + ")"
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+// Terms of linear recurrences and powers of matrices, computed by
+// repeated squaring so the work grows with the log of the power.
+// The arithmetic is that of the elements, so integers and rationals
+// give exact results.
+
+// mpow implements the binary mpow operator, raising the square matrix
+// u to the non-negative integer power v by matrix product.
+func mpow(c Context, u, v Value) Value {
+	m, ok := u.(*Matrix)
+	if !ok || m.Rank() != 2 || m.shape[0] != m.shape[1] {
+		Errorf("mpow: left operand must be a square matrix")
+	}
+	n, ok := v.(Int)
+	if !ok || n < 0 {
+		Errorf("mpow: exponent must be a non-negative integer")
+	}
+	return matrixPower(c, m, int(n))
+}
+
+// matrixPower returns m raised to the power n, where m is square
+// and n is non-negative.
+func matrixPower(c Context, m *Matrix, n int) *Matrix {
+	size := m.shape[0]
+	data := newVectorEditor(size*size, zero)
+	for i := range size {
+		data.Set(i*size+i, one)
+	}
+	z := NewMatrix([]int{size, size}, data.Publish())
+	for ; n > 0; n >>= 1 {
+		if n&1 != 0 {
+			z = innerProduct(c, z, "+", "*", m).(*Matrix)
+		}
+		if n > 1 {
+			m = innerProduct(c, m, "+", "*", m).(*Matrix)
+		}
+	}
+	return z
+}
+
+// recurrence implements the binary recurrence operator. The left
+// operand holds the coefficients and the initial values of a sequence
+// in which each term after the initial ones is
+//
+//	c[1]*a[k-1] + c[2]*a[k-2] + ... + c[d]*a[k-d]
+//
+// The right operand is the index, or indexes, of the terms to return,
+// counting from the index origin.
+func recurrence(c Context, u, v Value) Value {
+	coeffs, init := recurrenceArgs(u)
+	// The companion matrix takes the d terms ending at a[k-1] to those
+	// ending at a[k]: it shifts them along and computes the new one.
+	d := len(coeffs)
+	data := newVectorEditor(d*d, zero)
+	for i := range d - 1 {
+		data.Set(i*d+i+1, one)
+	}
+	for i, x := range coeffs {
+		data.Set((d-1)*d+d-1-i, x)
+	}
+	companion := NewMatrix([]int{d, d}, data.Publish())
+	origin := c.Config().Origin()
+	term := func(x Value) Value {
+		n, ok := x.(Int)
+		if !ok || int(n) < origin {
+			Errorf("recurrence: index must be an integer at least %d", origin)
+		}
+		k := int(n) - origin
+		if k < d {
+			return init[k]
+		}
+		// The first row of companion**k holds the weights of the
+		// initial values in a[k].
+		row := matrixPower(c, companion, k).data
+		z := c.EvalBinary(row.At(d-1), "*", init[d-1])
+		for i := d - 2; i >= 0; i-- {
+			z = c.EvalBinary(c.EvalBinary(row.At(i), "*", init[i]), "+", z)
+		}
+		return z
+	}
+	if v, ok := v.(*Vector); ok {
+		result := newVectorEditor(v.Len(), nil)
+		for i, x := range v.All() {
+			result.Set(i, term(x))
+		}
+		return result.Publish()
+	}
+	return term(v)
+}
+
+// recurrenceArgs returns the coefficients and initial values held by u,
+// each a number or a vector of numbers, of the same length.
+func recurrenceArgs(u Value) (coeffs, init []Value) {
+	pair, ok := u.(*Vector)
+	if !ok || pair.Len() != 2 {
+		Errorf("recurrence: left operand must be coefficients and initial values")
+	}
+	elems := func(v Value) []Value {
+		var elems []Value
+		switch v := v.(type) {
+		case *Vector:
+			for _, x := range v.All() {
+				elems = append(elems, x)
+			}
+		default:
+			elems = []Value{v}
+		}
+		for _, x := range elems {
+			if !isRealScalar(x) {
+				Errorf("recurrence: coefficients and initial values must be real numbers")
+			}
+		}
+		return elems
+	}
+	coeffs, init = elems(pair.At(0)), elems(pair.At(1))
+	if len(coeffs) != len(init) || len(coeffs) == 0 {
+		Errorf("recurrence: length mismatch: %d coefficients, %d initial values", len(coeffs), len(init))
+	}
+	return coeffs, init
+}
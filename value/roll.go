@@ -0,0 +1,114 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/big"
+	"math/rand/v2"
+
+	"robpike.io/ivy/config"
+)
+
+// roll implements the binary roll operator: like unary ?, it returns
+// for each element of v a random integer from the origin up to that
+// element, but it draws them from the random stream named by u.
+func roll(c Context, u, v Value) Value {
+	var name string
+	switch u := u.(type) {
+	case Char:
+		name = string(u)
+	case *Vector:
+		if !u.AllChars() {
+			Errorf("roll: stream name must be text")
+		}
+		name = vecText(u)
+	default:
+		Errorf("roll: stream name must be text")
+	}
+	conf := c.Config()
+	conf.LockRandom()
+	defer conf.UnlockRandom()
+	return rollEach(conf, conf.Stream(name), v)
+}
+
+// rollEach returns the roll of v, or of each of its elements, using the stream.
+func rollEach(conf *config.Config, stream *rand.ChaCha8, v Value) Value {
+	switch v := v.(type) {
+	case Int:
+		if v <= 0 {
+			Errorf("illegal roll value %v", v)
+		}
+		return Int(conf.Origin()) + Int(rand.New(stream).Int64N(int64(v)))
+	case BigInt:
+		if v.Sign() <= 0 {
+			Errorf("illegal roll value %v", v)
+		}
+		i, err := crand.Int(stream, v.Int)
+		if err != nil {
+			Errorf("roll: %v", err)
+		}
+		return BigInt{i.Add(i, big.NewInt(int64(conf.Origin())))}.shrink()
+	case *Vector:
+		result := newVectorEditor(v.Len(), nil)
+		for i, x := range v.All() {
+			result.Set(i, rollEach(conf, stream, x))
+		}
+		return result.Publish()
+	case *Matrix:
+		return NewMatrix(v.Shape(), rollEach(conf, stream, v.Data()).(*Vector))
+	}
+	Errorf("illegal roll value %v", v)
+	panic("not reached")
+}
+
+// rollBlock is the number of elements of a vector that unary ? rolls
+// using a single stream.
+const rollBlock = 1024
+
+// rollVector implements unary ? on the elements of v. It takes one number
+// from the generator and derives from it and the index of each block of
+// rollBlock elements a separate stream to roll that block. The blocks can
+// then be rolled in parallel, yet the result depends only on the seed.
+func rollVector(c Context, v *Vector) *Vector {
+	conf := c.Config()
+	conf.LockRandom()
+	base := conf.Random().Uint64()
+	conf.UnlockRandom()
+	result := newVectorEditor(v.Len(), nil)
+	blocks := (v.Len() + rollBlock - 1) / rollBlock
+	pfor(true, rollBlock, blocks, func(lo, hi int) {
+		var seed [32]byte
+		binary.LittleEndian.PutUint64(seed[0:], base)
+		for b := lo; b < hi; b++ {
+			binary.LittleEndian.PutUint64(seed[8:], uint64(b))
+			stream := rand.NewChaCha8(seed)
+			for i := b * rollBlock; i < min((b+1)*rollBlock, v.Len()); i++ {
+				result.Set(i, rollEach(conf, stream, v.At(i)))
+			}
+		}
+	})
+	return result.Publish()
+}
+
+// sysRandState implements sys "randstate": with no argument, it returns
+// the state of the random number generator and its named streams as
+// text; given such a text, it restores that state.
+func sysRandState(conf *config.Config, args []Value) Value {
+	switch len(args) {
+	case 0:
+		return newCharVector(conf.RandomState())
+	case 1:
+		if v, ok := args[0].(*Vector); ok && v.AllChars() {
+			if err := conf.SetRandomState(vecText(v)); err != nil {
+				Errorf("randstate: %v", err)
+			}
+			return empty
+		}
+	}
+	Errorf(`usage: sys "randstate" [state]`)
+	panic("not reached")
+}
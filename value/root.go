@@ -0,0 +1,133 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math/big"
+
+	"robpike.io/ivy/config"
+)
+
+// Integer roots, which are exact when the argument is a perfect power.
+
+// iroot returns the k-th root of non-negative x, rounded down, and
+// whether it is exact.
+func iroot(conf *config.Config, x *big.Int, k uint) (*big.Int, bool) {
+	var r *big.Int
+	switch {
+	case x.Sign() == 0 || k == 1:
+		return new(big.Int).Set(x), true
+	case k == 2:
+		r = new(big.Int).Sqrt(x)
+	default:
+		// Newton's method, from a power of two no smaller than the root.
+		r = new(big.Int).Lsh(big.NewInt(1), uint(x.BitLen())/k+1)
+		km1 := big.NewInt(int64(k - 1))
+		bk := big.NewInt(int64(k))
+		t, prod := tempI(conf), tempI(conf)
+		defer releaseI(t, prod)
+		for {
+			// next = ((k-1)r + x/r**(k-1)) / k
+			t.Exp(r, km1, nil)
+			t.Quo(x, t)
+			t.Add(t, prod.Mul(km1, r))
+			t.Quo(t, bk)
+			if t.Cmp(r) >= 0 {
+				break
+			}
+			r.Set(t)
+		}
+	}
+	p := tempI(conf).Exp(r, big.NewInt(int64(k)), nil)
+	defer releaseI(p)
+	return r, p.Cmp(x) == 0
+}
+
+// isqrt returns the square root of the integer v, rounded down.
+func isqrt(c Context, v Value) Value {
+	x := v.toType("isqrt", c.Config(), bigIntType).(BigInt)
+	if x.Sign() < 0 {
+		Errorf("isqrt of negative number")
+	}
+	return BigInt{new(big.Int).Sqrt(x.Int)}.shrink()
+}
+
+// isPower returns 1 if the integer v is m**k for some integers m and
+// k>1, and 0 otherwise.
+func isPower(c Context, v Value) Value {
+	x := new(big.Int).Abs(v.toType("ispower", c.Config(), bigIntType).(BigInt).Int)
+	if x.Cmp(big.NewInt(1)) <= 0 {
+		return one
+	}
+	neg := isNegative(v)
+	for k := uint(2); k <= uint(x.BitLen()); k++ {
+		if neg && k%2 == 0 {
+			continue // A negative number is only an odd power.
+		}
+		if _, ok := iroot(c.Config(), x, k); ok {
+			return one
+		}
+	}
+	return zero
+}
+
+// rootDegree returns the degree of a root, which must be a positive
+// integer but may have been promoted to match the radicand.
+func rootDegree(u Value) uint {
+	switch k := u.(type) {
+	case Int:
+		if k > 0 && k < maxInt {
+			return uint(k)
+		}
+	case BigInt:
+		if r := k.shrink(); r != u {
+			return rootDegree(r)
+		}
+	case BigRat:
+		if k.IsInt() {
+			return rootDegree(BigInt{k.Num()})
+		}
+	case BigFloat:
+		if k.IsInt() {
+			i, _ := k.Int(nil)
+			return rootDegree(BigInt{i})
+		}
+	case Complex:
+		if isZero(k.imag) {
+			return rootDegree(k.real)
+		}
+	}
+	Errorf("root: degree must be a positive integer")
+	panic("not reached")
+}
+
+// root returns u root v, the u-th root of v. The result is exact if
+// v is an integer or rational whose root is one too. A negative v has
+// a negative real root if u is odd, and a complex one if it is even.
+func root(c Context, u, v Value) Value {
+	k := rootDegree(u)
+	if k == 1 {
+		return v
+	}
+	if isNegative(v) && k%2 == 1 {
+		return c.EvalUnary("-", root(c, u, c.EvalUnary("-", v)))
+	}
+	if !isNegative(v) {
+		switch x := v.(type) {
+		case Int, BigInt:
+			r, ok := iroot(c.Config(), v.toType("root", c.Config(), bigIntType).(BigInt).Int, k)
+			if ok {
+				return BigInt{r}.shrink()
+			}
+		case BigRat:
+			num, numOK := iroot(c.Config(), x.Num(), k)
+			den, denOK := iroot(c.Config(), x.Denom(), k)
+			if numOK && denOK {
+				return BigRat{new(big.Rat).SetFrac(num, den)}.shrink()
+			}
+		}
+	}
+	return c.EvalBinary(v, "**", BigRat{big.NewRat(1, int64(k))})
+}
@@ -4,7 +4,10 @@
 
 package value
 
-import "sort"
+import (
+	"slices"
+	"sort"
+)
 
 // Operations on "sets", which are really just lists that
 // can contain duplicates rather than in the mathematical
@@ -104,6 +107,34 @@ func intersect(c Context, u, v Value) Value {
 	return elems.Publish()
 }
 
+// without returns the elements of u not present in v.
+func without(c Context, u, v Value) Value {
+	uType := whichType(u)
+	vType := whichType(v)
+	if uType == matrixType || vType == matrixType {
+		Errorf("binary without not implemented on type matrix")
+	}
+	var uu, vv *Vector
+	if uType < vectorType {
+		uu = oneElemVector(u)
+	} else {
+		uu = u.(*Vector)
+	}
+	if vType < vectorType {
+		vv = oneElemVector(v)
+	} else {
+		vv = v.(*Vector)
+	}
+	present := membership(c, uu, vv)
+	elems := newVectorEditor(0, nil)
+	for i, x := range uu.All() {
+		if present.At(i) != one {
+			elems.Append(x)
+		}
+	}
+	return elems.Publish()
+}
+
 func unique(c Context, v Value) Value {
 	vType := whichType(v)
 	if vType < vectorType {
@@ -117,6 +148,9 @@ func unique(c Context, v Value) Value {
 	if vv.Len() == 0 {
 		return vv
 	}
+	if hs, ok := hashes(vv, 1); ok {
+		return uniqueHashed(c, vv, hs)
+	}
 	// We could just sort and dedup, but that loses the original
 	// order of elements in the vector, which must be preserved.
 	type indexedValue struct {
@@ -128,7 +162,8 @@ func unique(c Context, v Value) Value {
 		sorted[i] = indexedValue{i, x}
 	}
 	// Sort based on the values, preserving index information.
-	sort.Slice(sorted, func(i, j int) bool {
+	// The sort must be stable so the first of equal values is kept.
+	sort.SliceStable(sorted, func(i, j int) bool {
 		c := OrderedCompare(c, sorted[i].v, sorted[j].v)
 		if c == 0 {
 			// Choose lower type. You need to choose one, so pick lowest.
@@ -156,6 +191,47 @@ func unique(c Context, v Value) Value {
 	return elems.Publish()
 }
 
+// uniqueWithin implements binary unique: the elements of v, which must
+// be real numbers, omitting each that is within tol of an element
+// already kept, so near-duplicates are removed in order of appearance.
+func uniqueWithin(c Context, tol, v Value) Value {
+	if !isRealScalar(tol) || OrderedCompare(c, tol, zero) < 0 {
+		Errorf("unique: tolerance must be a non-negative real number: %s", tol)
+	}
+	vv, ok := v.(*Vector)
+	if !ok {
+		return v // A real scalar.
+	}
+	near := func(x, y Value) bool {
+		return OrderedCompare(c, c.EvalBinary(x, "-", y), tol) <= 0
+	}
+	var kept []Value // Sorted.
+	elems := newVectorEditor(0, nil)
+	for _, x := range vv.All() {
+		if !isRealScalar(x) {
+			Errorf("unique: right operand must be real numbers")
+		}
+		pos := sort.Search(len(kept), func(j int) bool {
+			return OrderedCompare(c, kept[j], x) >= 0
+		})
+		if pos < len(kept) && near(kept[pos], x) || pos > 0 && near(x, kept[pos-1]) {
+			continue
+		}
+		kept = slices.Insert(kept, pos, x)
+		elems.Append(x)
+	}
+	return elems.Publish()
+}
+
+// isRealScalar reports whether v is a real number.
+func isRealScalar(v Value) bool {
+	switch v.(type) {
+	case Int, BigInt, BigRat, BigFloat:
+		return true
+	}
+	return false
+}
+
 // scalarEqual is faster(ish) comparison to make set ops more efficient.
 // The arguments must be scalars.
 func scalarEqual(c Context, u, v Value) bool {
@@ -173,6 +249,11 @@ func notEqual(c Context, u, v Value) Value {
 	return toInt(OrderedCompare(c, u, v) != 0)
 }
 
+// threeWay implements the elementwise cmp operator on scalars.
+func threeWay(c Context, u, v Value) Value {
+	return Int(OrderedCompare(c, u, v))
+}
+
 // OrderedCompare returns -1, 0, or 1 according to whether u is less than, equal
 // to, or greater than v, according to total ordering rules. Total ordering is not
 // the usual mathematical definition, as we honor things like 1.0 == 1, comparison
@@ -190,7 +271,9 @@ func notEqual(c Context, u, v Value) Value {
 // When comparing identically-typed values:
 //   - Complex is ordered first by real component, then by imaginary.
 //   - Vector and Matrix are ordered first by number of elements,
-//     then in lexical order of elements.
+//     then, for Matrix, by rank and shape, then in lexical order of
+//     elements. Elements may themselves be vectors or matrices, to
+//     any depth.
 //
 // These are unusual rules, but they are provide a unique ordering of elements
 // sufficient for set membership. // Exported for testing, which is done by the
@@ -235,6 +318,13 @@ func OrderedCompare(c Context, u, v Value) int {
 		if vType == complexType {
 			return -1
 		}
+		// A Time orders above all real numbers.
+		if uType == timeType {
+			return 1
+		}
+		if vType == timeType {
+			return -1
+		}
 		return sgn2(c, u, v)
 	}
 	switch uType {
@@ -248,6 +338,8 @@ func OrderedCompare(c Context, u, v Value) int {
 		return u.(BigRat).Cmp(v.(BigRat).Rat)
 	case bigFloatType:
 		return u.(BigFloat).Cmp(v.(BigFloat).Float)
+	case timeType:
+		return u.(Time).Compare(v.(Time).Time)
 	case complexType:
 		// We can choose an ordering for Complex, even if math can't.
 		// Order by the real part, then the imaginary part.
@@ -276,6 +368,14 @@ func OrderedCompare(c Context, u, v Value) int {
 		if uu.data.Len() != vv.data.Len() {
 			return sgn2Int(uu.data.Len(), vv.data.Len())
 		}
+		if uu.Rank() != vv.Rank() {
+			return sgn2Int(uu.Rank(), vv.Rank())
+		}
+		for i, n := range uu.shape {
+			if n != vv.shape[i] {
+				return sgn2Int(n, vv.shape[i])
+			}
+		}
 		for i, x := range uu.data.All() {
 			s := OrderedCompare(c, x, vv.data.At(i))
 			if s != 0 {
@@ -310,3 +410,77 @@ func sgn2Int(a, b int) int {
 	}
 	return 1
 }
+
+// groups returns the indexes of the elements of keys, grouped by key.
+// The groups are in order of the first appearance of their key, and the
+// indexes within a group are ascending.
+func groups(c Context, keys *Vector) [][]int {
+	x := make([]int, keys.Len())
+	for i := range x {
+		x[i] = i
+	}
+	sort.SliceStable(x, func(i, j int) bool {
+		return OrderedCompare(c, keys.At(x[i]), keys.At(x[j])) < 0
+	})
+	var result [][]int
+	for i := 0; i < len(x); {
+		j := i + 1
+		for j < len(x) && scalarEqual(c, keys.At(x[i]), keys.At(x[j])) {
+			j++
+		}
+		result = append(result, x[i:j])
+		i = j
+	}
+	// Stable sorting leaves the first appearance at the start of each group.
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+	return result
+}
+
+// group implements keys group data, a vector holding a vector of the
+// elements of data for each unique key, in order of first appearance.
+// The keys in the same order are unique keys.
+func group(c Context, u, v Value) Value {
+	keys, data := groupVector(u), groupVector(v)
+	if keys.Len() != data.Len() {
+		Errorf("group: length mismatch: %d keys, %d values", keys.Len(), data.Len())
+	}
+	g := groups(c, keys)
+	result := newVectorEditor(len(g), nil)
+	for i, indexes := range g {
+		elems := newVectorEditor(len(indexes), nil)
+		for j, x := range indexes {
+			elems.Set(j, data.At(x))
+		}
+		result.Set(i, elems.Publish())
+	}
+	return result.Publish()
+}
+
+// groupIndexes implements group keys, a vector holding a vector of the
+// indexes of each unique key, in order of first appearance.
+func groupIndexes(c Context, v Value) Value {
+	g := groups(c, groupVector(v))
+	origin := c.Config().Origin()
+	result := newVectorEditor(len(g), nil)
+	for i, indexes := range g {
+		elems := newVectorEditor(len(indexes), nil)
+		for j, x := range indexes {
+			elems.Set(j, Int(x+origin))
+		}
+		result.Set(i, elems.Publish())
+	}
+	return result.Publish()
+}
+
+// groupVector returns v as a vector for grouping.
+func groupVector(v Value) *Vector {
+	switch v := v.(type) {
+	case *Vector:
+		return v
+	case *Matrix:
+		Errorf("group not implemented on type matrix")
+	}
+	return oneElemVector(v)
+}
@@ -106,7 +106,9 @@ func sincos(name string, c Context, index int, x *big.Float, z *big.Float, exp u
 	x2 := newFloat(c).Mul(x, x)
 	n := newFloat(c)
 
-	for loop := newLoop(c.Config(), name, x, 4); ; {
+	loop := newLoop(c.Config(), name, x, 4)
+	defer loop.release()
+	for {
 		// Invariant: factorial holds -1ⁿ*exponent!.
 		factorial.Neg(factorial)
 		term.Quo(term, factorial)
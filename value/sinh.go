@@ -43,13 +43,16 @@ func floatSinh(c Context, x *big.Float) *big.Float {
 	// The Taylor series for sinh(x) is the odd terms of exp(x): x + x³/3! + x⁵/5!...
 
 	conf := c.Config()
-	xN := newF(conf).Set(x)
-	term := newF(conf)
-	n := newF(conf)
-	nFactorial := newF(conf).SetUint64(1)
+	xN := tempF(conf).Set(x)
+	term := tempF(conf)
+	n := tempF(conf)
+	nFactorial := tempF(conf).SetUint64(1)
 	z := newF(conf).SetInt64(0)
 
-	for loop := newLoop(conf, "sinh", x, 10); ; { // Big exponentials converge slowly.
+	defer releaseF(xN, term, n, nFactorial)
+	loop := newLoop(conf, "sinh", x, 10) // Big exponentials converge slowly.
+	defer loop.release()
+	for {
 		term.Set(xN)
 		term.Quo(term, nFactorial)
 		z.Add(z, term)
@@ -73,14 +76,17 @@ func floatCosh(c Context, x *big.Float) *big.Float {
 	// The Taylor series for cosh(x) is the even terms of exp(x): 1 + x²/2! + x⁴/4!...
 
 	conf := c.Config()
-	xN := newF(conf).Set(x)
+	xN := tempF(conf).Set(x)
 	xN.Mul(xN, x) // x²
-	term := newF(conf)
-	n := newF(conf)
-	nFactorial := newF(conf).SetUint64(2)
+	term := tempF(conf)
+	n := tempF(conf)
+	nFactorial := tempF(conf).SetUint64(2)
 	z := newF(conf).SetInt64(1)
 
-	for loop := newLoop(conf, "cosh", x, 10); ; { // Big exponentials converge slowly.
+	defer releaseF(xN, term, n, nFactorial)
+	loop := newLoop(conf, "cosh", x, 10) // Big exponentials converge slowly.
+	defer loop.release()
+	for {
 		term.Set(xN)
 		term.Quo(term, nFactorial)
 		z.Add(z, term)
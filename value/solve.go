@@ -0,0 +1,144 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math"
+	"math/big"
+)
+
+// Root finding and minimization of a unary op, named by text, over
+// an interval given as a two-element vector. Both work in floating
+// point at the configured precision and stop when the interval can
+// shrink no further.
+
+// maxSolveSteps bounds the iterations of findroot and minimize, in case
+// the op misbehaves.
+const maxSolveSteps = 100000
+
+// solveArgs returns the name of the op u and the interval v, in order.
+func solveArgs(c Context, name string, u, v Value) (string, *big.Float, *big.Float) {
+	op := opName(name, u)
+	lo, hi := bounds(c, name, v)
+	if lo.Cmp(hi) > 0 {
+		lo, hi = hi, lo
+	}
+	return op, lo, hi
+}
+
+// opName returns the name of the unary op held as text by u.
+func opName(name string, u Value) string {
+	var op string
+	switch u := u.(type) {
+	case Char:
+		op = string(u)
+	case *Vector:
+		if u.AllChars() {
+			op = vecText(u)
+		}
+	}
+	if op == "" {
+		Errorf("%s: left operand must be the name of a unary op", name)
+	}
+	return op
+}
+
+// bounds returns the elements of v, a pair of real numbers.
+func bounds(c Context, name string, v Value) (*big.Float, *big.Float) {
+	b, ok := v.(*Vector)
+	if !ok || b.Len() != 2 || !isRealScalar(b.At(0)) || !isRealScalar(b.At(1)) {
+		Errorf("%s: right operand must be the bounds lo hi", name)
+	}
+	return floatSelf(c, b.At(0)).Float, floatSelf(c, b.At(1)).Float
+}
+
+// apply returns op x, which must be a real number.
+func apply(c Context, name, op string, x *big.Float) Value {
+	y := c.EvalUnary(op, BigFloat{x}.shrink())
+	if !isRealScalar(y) {
+		Errorf("%s: %s %s is not a real number: %s", name, op, BigFloat{x}, y)
+	}
+	return y
+}
+
+// findRoot implements the binary findroot operator, finding by
+// bisection a zero of the op in an interval where its sign changes.
+func findRoot(c Context, u, v Value) Value {
+	op, lo, hi := solveArgs(c, "findroot", u, v)
+	sLo := sgn(c, apply(c, "findroot", op, lo))
+	sHi := sgn(c, apply(c, "findroot", op, hi))
+	switch {
+	case sLo == 0:
+		return BigFloat{lo}.shrink()
+	case sHi == 0:
+		return BigFloat{hi}.shrink()
+	case sLo == sHi:
+		Errorf("findroot: %s has the same sign at both bounds", op)
+	}
+	two := big.NewFloat(2)
+	for range maxSolveSteps {
+		mid := newFloat(c).Add(lo, hi)
+		mid.Quo(mid, two)
+		if mid.Cmp(lo) == 0 || mid.Cmp(hi) == 0 {
+			break
+		}
+		s := sgn(c, apply(c, "findroot", op, mid))
+		switch s {
+		case 0:
+			return BigFloat{mid}.shrink()
+		case sLo:
+			lo = mid
+		default:
+			hi = mid
+		}
+	}
+	// Return the bound nearer zero.
+	yLo := c.EvalUnary("abs", apply(c, "findroot", op, lo))
+	yHi := c.EvalUnary("abs", apply(c, "findroot", op, hi))
+	if OrderedCompare(c, yLo, yHi) <= 0 {
+		return BigFloat{lo}.shrink()
+	}
+	return BigFloat{hi}.shrink()
+}
+
+// minimize implements the binary minimize operator, finding by
+// golden-section search the minimum of an op that has only one
+// minimum in the interval.
+func minimize(c Context, u, v Value) Value {
+	op, lo, hi := solveArgs(c, "minimize", u, v)
+	conf := c.Config()
+	// invPhi is 1/φ, (√5-1)/2, at the configured precision.
+	invPhi := newF(conf).Sqrt(newF(conf).SetInt64(5))
+	invPhi.Sub(invPhi, big.NewFloat(1))
+	invPhi.Quo(invPhi, big.NewFloat(2))
+	// Each step shrinks the interval by 1/φ.
+	steps := int(float64(conf.FloatPrec())/math.Log2(math.Phi)) + 10
+	probe := func(a, b *big.Float) (*big.Float, *big.Float) {
+		// Returns the points b-(b-a)/φ and a+(b-a)/φ.
+		d := newF(conf).Sub(b, a)
+		d.Mul(d, invPhi)
+		return newF(conf).Sub(b, d), newF(conf).Add(a, d)
+	}
+	x1, x2 := probe(lo, hi)
+	f1, f2 := apply(c, "minimize", op, x1), apply(c, "minimize", op, x2)
+	for range min(steps, maxSolveSteps) {
+		if x1.Cmp(x2) >= 0 {
+			break
+		}
+		if OrderedCompare(c, f1, f2) <= 0 {
+			hi, x2, f2 = x2, x1, f1
+			x1, _ = probe(lo, hi)
+			f1 = apply(c, "minimize", op, x1)
+		} else {
+			lo, x1, f1 = x1, x2, f2
+			_, x2 = probe(lo, hi)
+			f2 = apply(c, "minimize", op, x2)
+		}
+	}
+	if OrderedCompare(c, f1, f2) <= 0 {
+		return BigFloat{x1}.shrink()
+	}
+	return BigFloat{x2}.shrink()
+}
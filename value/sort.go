@@ -0,0 +1,145 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import "container/heap"
+
+// Sorting: sort v is v[up v], with rows of a matrix sorted as units,
+// key sort v sorts v by the parallel key, and k topk v selects the
+// k largest elements.
+
+// sortValue returns v sorted into ascending order, or descending
+// if reverse is set.
+func sortValue(c Context, v Value, reverse bool) Value {
+	return sortByKey(c, v, v, reverse)
+}
+
+// sortByKey returns v reordered by the grade of key, whose elements
+// (or rows, if key is a matrix) correspond to the elements (or rows) of v.
+func sortByKey(c Context, key, v Value, reverse bool) Value {
+	var grade *Vector
+	switch k := key.(type) {
+	case *Vector:
+		grade = k.grade(c)
+	case *Matrix:
+		if k.shape[0] == 0 {
+			grade = empty
+			break
+		}
+		grade = k.grade(c)
+	default:
+		grade = NewIntVector(c.Config().Origin())
+	}
+	if reverse {
+		grade = grade.reverse()
+	}
+	origin := c.Config().Origin()
+	switch v := v.(type) {
+	case *Vector:
+		if v.Len() != grade.Len() {
+			Errorf("sort: key length %d does not match length %d", grade.Len(), v.Len())
+		}
+		result := newVectorEditor(v.Len(), nil)
+		for i, g := range grade.All() {
+			result.Set(i, v.At(int(g.(Int))-origin))
+		}
+		return result.Publish()
+	case *Matrix:
+		if v.shape[0] != grade.Len() {
+			Errorf("sort: key length %d does not match row count %d", grade.Len(), v.shape[0])
+		}
+		stride := v.data.Len() / max(v.shape[0], 1)
+		result := newVectorEditor(0, nil)
+		for _, g := range grade.All() {
+			row := int(g.(Int)) - origin
+			result.Append(v.data.ro[row*stride : (row+1)*stride]...)
+		}
+		return NewMatrix(v.shape, result.Publish())
+	}
+	if grade.Len() != 1 {
+		Errorf("sort: key length %d does not match length 1", grade.Len())
+	}
+	return v
+}
+
+func sortKeyUp(c Context, u, v Value) Value {
+	return sortByKey(c, u, v, false)
+}
+
+func sortKeyDown(c Context, u, v Value) Value {
+	return sortByKey(c, u, v, true)
+}
+
+// topk implements k topk v: the k largest elements of v in descending
+// order or, if k is negative, the -k smallest in ascending order. Equal
+// elements appear in the order they occur in v. Rather than grading all
+// of v, it keeps the best k elements seen so far in a heap.
+func topk(c Context, u, v Value) Value {
+	if vec, ok := u.(*Vector); ok && vec.Len() == 1 {
+		u = vec.At(0)
+	}
+	count, ok := u.(Int)
+	if !ok {
+		Errorf("topk: count must be small integer")
+	}
+	k := int(count)
+	var vec *Vector
+	switch v := v.(type) {
+	case *Vector:
+		vec = v
+	case *Matrix:
+		Errorf("topk: right operand must be a vector")
+	default:
+		vec = oneElemVector(v)
+	}
+	h := &topkHeap{c: c, v: vec, largest: k > 0}
+	k = min(max(k, -k), vec.Len())
+	for i := range vec.Len() {
+		if h.Len() < k {
+			heap.Push(h, i)
+		} else if k > 0 && h.better(i, h.x[0]) {
+			h.x[0] = i
+			heap.Fix(h, 0)
+		}
+	}
+	// Pop the worst first, filling the result from the end.
+	result := newVectorEditor(h.Len(), nil)
+	for i := h.Len() - 1; i >= 0; i-- {
+		result.Set(i, vec.At(heap.Pop(h).(int)))
+	}
+	return result.Publish()
+}
+
+// topkHeap is a heap of indexes into v, with the worst of the
+// candidate elements at the top.
+type topkHeap struct {
+	c       Context
+	v       *Vector
+	largest bool
+	x       []int
+}
+
+// better reports whether element i of v ranks ahead of element j.
+func (h *topkHeap) better(i, j int) bool {
+	cmp := OrderedCompare(h.c, h.v.At(i), h.v.At(j))
+	if !h.largest {
+		cmp = -cmp
+	}
+	if cmp != 0 {
+		return cmp > 0
+	}
+	return i < j // Stable: earlier elements win ties.
+}
+
+func (h *topkHeap) Len() int           { return len(h.x) }
+func (h *topkHeap) Less(i, j int) bool { return h.better(h.x[j], h.x[i]) }
+func (h *topkHeap) Swap(i, j int)      { h.x[i], h.x[j] = h.x[j], h.x[i] }
+func (h *topkHeap) Push(x any)         { h.x = append(h.x, x.(int)) }
+func (h *topkHeap) Pop() any {
+	n := len(h.x) - 1
+	x := h.x[n]
+	h.x = h.x[:n]
+	return x
+}
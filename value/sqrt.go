@@ -18,6 +18,12 @@ func sqrt(c Context, v Value) Value {
 	if isNegative(v) {
 		return NewComplex(zero, evalFloatFunc(c, c.EvalUnary("-", v), floatSqrt))
 	}
+	if i, ok := v.(BigInt); ok {
+		// Keep the roots of perfect squares exact, however large.
+		if r, exact := iroot(c.Config(), i.Int, 2); exact {
+			return BigInt{r}.shrink()
+		}
+	}
 	return evalFloatFunc(c, v, floatSqrt)
 }
 
@@ -70,7 +76,9 @@ func floatSqrt(c Context, x *big.Float) *big.Float {
 	num := newFloat(c)
 	den := newFloat(c)
 
-	for loop := newLoop(c.Config(), "sqrt", x, 1); ; {
+	loop := newLoop(c.Config(), "sqrt", x, 1)
+	defer loop.release()
+	for {
 		zSquared.Mul(z, z)
 		num.Sub(zSquared, x)
 		den.Mul(floatTwo, z)
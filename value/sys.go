@@ -6,10 +6,15 @@ package value
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"math"
 	"math/big"
+	"net/http"
 	"os"
+	"os/exec"
+	"strings"
 	"time"
 
 	"robpike.io/ivy/config"
@@ -17,49 +22,91 @@ import (
 
 const sysHelp = `
 "help":      print this text and return iota 0
+"args":      the arguments following -- on the command line, as a vector of texts
 "base":      the input and output base settings as a vector of two integers
 "cpu":       the processor timing for the last evaluation
              as a vector in units of seconds:
                real user(cpu) system(cpu)
 "date":      the current time in Unix date format
                year month day hour minute second
+"env" name:  the value of the named environment variable; empty if not set
+"exec" command:
+             run the command, given as a single text or as the command and its
+             arguments, and return its standard output as text; not available
+             on mobile platforms or, by default, in programs that embed ivy
 "format":    the output format setting
+"get" url:   fetch the URL and return the body of the response as text; not
+             available on mobile platforms or, by default, in programs that embed ivy
 "ibase":     the input base (ibase) setting
 "maxbits":   the maxbits setting
 "maxdigits": the maxdigits setting
+"maxelems":  the maxelems setting
 "maxstack":  the maxstack setting
 "obase":     the output base (obase) setting
 "origin":    the index origin setting
+"paste":     the text on the system clipboard
 "prompt":    the prompt setting
+"randstate": the state of the random number generator and its named streams, as
+             text; "randstate" state restores the state saved in that text
 "read" file: read the named file and return a vector of lines, with line termination stripped
+"readimage" file:
+             read the named PNG or PGM image and return a matrix of pixel values
+             from 0 to 255, of shape rows cols if gray or rows cols 3 if color
 "sec":       the time in seconds since
                Jan 1 00:00:00 1970 UTC
+"symbols":   the names of the defined variables and ops, as a vector of texts
 "time":      the current time in the configured time zone as a vector; the last
              element is the time zone in which the other values apply:
                year month day hour minute second seconds-east-of-UTC
+"writeimage" file matrix:
+             write a matrix of pixel values, as for "readimage", to the named
+             file as a PNG image, or as PGM if the name ends .pgm
 
 To convert seconds to a time vector:
   'T' encode sys 'sec'
 To convert a time vector to a seconds value:
   'T' decode sys 'time'
 To print seconds in Unix date format:
-  'T' text sys 'sec'`
+  'T' text sys 'sec'
+To convert seconds to a time value:
+  time sys 'sec'`
 
 func vecText(v *Vector) string {
 	s := fmt.Sprint(v) // will print as "(text)"
 	return s[1 : len(s)-1]
 }
 
+// sysSandboxed holds the sys calls that reach beyond ivy itself, to
+// files, the environment, the clipboard, other programs, or the network.
+var sysSandboxed = map[string]bool{
+	"env":        true,
+	"exec":       true,
+	"get":        true,
+	"paste":      true,
+	"read":       true,
+	"readimage":  true,
+	"writeimage": true,
+}
+
 // sys implements the variegated "sys" unary operator.
 func sys(c Context, v Value) Value {
 	vv := v.(*Vector)
 	conf := c.Config()
+	checkSandbox := func(verb string) {
+		if conf.Sandbox() && sysSandboxed[verb] {
+			Errorf("sys %q not allowed in sandbox", verb)
+		}
+	}
 
 	if vv.AllChars() { // single argument
 		verb := vecText(vv)
+		checkSandbox(verb)
 		if fn, ok := sys1[verb]; ok {
 			return fn(conf)
 		}
+		if fn, ok := sysC[verb]; ok {
+			return fn(c)
+		}
 		if fn, ok := sysN[verb]; ok {
 			return fn(conf, []Value{})
 		}
@@ -68,6 +115,7 @@ func sys(c Context, v Value) Value {
 
 	if v1, ok := vv.At(0).(*Vector); ok && v1.AllChars() { // multiple arguments, verb first
 		verb := vecText(v1)
+		checkSandbox(verb)
 		if fn, ok := sysN[verb]; ok {
 			var args []Value
 			for _, v := range vv.Slice(1, vv.Len()) {
@@ -78,6 +126,9 @@ func sys(c Context, v Value) Value {
 		if _, ok := sys1[verb]; ok {
 			Errorf("sys %q takes no arguments", verb)
 		}
+		if _, ok := sysC[verb]; ok {
+			Errorf("sys %q takes no arguments", verb)
+		}
 		Errorf("sys %q not defined", verb)
 	}
 
@@ -86,6 +137,13 @@ func sys(c Context, v Value) Value {
 }
 
 var sys1 = map[string]func(conf *config.Config) Value{
+	"args": func(conf *config.Config) Value {
+		args := newVectorEditor(0, nil)
+		for _, arg := range conf.Args() {
+			args.Append(newCharVector(arg))
+		}
+		return args.Publish()
+	},
 	"help": func(conf *config.Config) Value {
 		fmt.Fprint(conf.Output(), sysHelp)
 		return empty
@@ -118,6 +176,9 @@ var sys1 = map[string]func(conf *config.Config) Value{
 	"maxdigits": func(conf *config.Config) Value {
 		return Int(conf.MaxDigits())
 	},
+	"maxelems": func(conf *config.Config) Value {
+		return Int(conf.MaxElems())
+	},
 	"maxstack": func(conf *config.Config) Value {
 		return Int(conf.MaxStack())
 	},
@@ -139,10 +200,127 @@ var sys1 = map[string]func(conf *config.Config) Value{
 	"time": func(conf *config.Config) Value {
 		return timeVec(time.Now().In(conf.Location()))
 	},
+	"paste": func(conf *config.Config) Value {
+		clip := conf.Clipboard()
+		if clip == nil {
+			Errorf("no clipboard available")
+		}
+		text, err := clip.Paste()
+		if err != nil {
+			Errorf("paste: %v", err)
+		}
+		return newCharVector(text)
+	},
+}
+
+// sysC holds the calls that take no arguments but need the context.
+var sysC = map[string]func(Context) Value{
+	"symbols": func(c Context) Value {
+		names := newVectorEditor(0, nil)
+		for _, name := range c.Symbols() {
+			names.Append(newCharVector(name))
+		}
+		return names.Publish()
+	},
 }
 
 var sysN = map[string]func(*config.Config, []Value) Value{
-	"read": sysRead,
+	"env":        sysEnv,
+	"exec":       sysExec,
+	"get":        sysGet,
+	"randstate":  sysRandState,
+	"read":       sysRead,
+	"readimage":  sysReadImage,
+	"writeimage": sysWriteImage,
+}
+
+func sysEnv(conf *config.Config, args []Value) Value {
+	if len(args) != 1 {
+		Errorf(`usage: sys "env" "name"`)
+	}
+	v, ok := args[0].(*Vector)
+	if !ok || !v.AllChars() {
+		Errorf(`usage: sys "env" "name"`)
+	}
+	return newCharVector(os.Getenv(vecText(v)))
+}
+
+func sysExec(conf *config.Config, args []Value) Value {
+	usage := func() {
+		Errorf(`usage: sys "exec" "command args..."`)
+	}
+	if !conf.Exec() || conf.Mobile() {
+		Errorf(`sys "exec" not enabled`)
+	}
+	// A single text holds the command and its arguments, separated by
+	// spaces; several give them one by one, so an argument may hold spaces.
+	var words []string
+	for _, arg := range args {
+		v, ok := arg.(*Vector)
+		if !ok || !v.AllChars() {
+			usage()
+		}
+		if len(args) == 1 {
+			words = strings.Fields(vecText(v))
+		} else {
+			words = append(words, vecText(v))
+		}
+	}
+	if len(words) == 0 {
+		usage()
+	}
+	cmd := exec.Command(words[0], words[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			Errorf("exec %s: %v: %s", words[0], err, msg)
+		}
+		Errorf("exec %s: %v", words[0], err)
+	}
+	return newCharVector(strings.TrimSuffix(string(out), "\n"))
+}
+
+// getTimeout bounds the time sys "get" waits for a response.
+const getTimeout = 30 * time.Second
+
+func sysGet(conf *config.Config, args []Value) Value {
+	if !conf.Network() || conf.Mobile() {
+		Errorf(`sys "get" not enabled`)
+	}
+	if len(args) != 1 {
+		Errorf(`usage: sys "get" "url"`)
+	}
+	v, ok := args[0].(*Vector)
+	if !ok || !v.AllChars() {
+		Errorf(`usage: sys "get" "url"`)
+	}
+	url := vecText(v)
+	client := http.Client{Timeout: getTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		Errorf("%v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		Errorf("get %s: %s", url, resp.Status)
+	}
+	// The body becomes a character vector, so it may have no more
+	// bytes than the element limit allows characters.
+	var r io.Reader = resp.Body
+	max := int64(conf.MaxElems())
+	if max != 0 {
+		r = io.LimitReader(resp.Body, max+1)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		Errorf("get %s: %v", url, err)
+	}
+	if max != 0 && int64(len(body)) > max {
+		Errorf("get %s: response too large (more than %d bytes)", url, max)
+	}
+	return newCharVector(string(body))
 }
 
 func sysRead(conf *config.Config, args []Value) Value {
@@ -177,7 +355,7 @@ func sysRead(conf *config.Config, args []Value) Value {
 	return edit.Publish()
 }
 
-// encodeTime returns a sys "time" vector given a seconds value.
+// encodeTime returns a sys "time" vector given a seconds value or Time.
 // We know the first argument is all chars and not empty.
 func encodeTime(c Context, u, v *Vector) Value {
 	r := rune(u.At(0).(Char))
@@ -292,10 +470,13 @@ func secNsec(fs *big.Float) (sec, nsec int64) {
 	return s.Int64(), ns
 }
 
-// timeFromValue converts a seconds value into a time.Time, for
+// timeFromValue converts a seconds value or Time into a time.Time, for
 // the 'text' operator.
 func timeFromValue(c Context, v Value) time.Time {
 	conf := c.Config()
+	if t, ok := v.(Time); ok {
+		return t.In(conf.LocationAt(t.Time))
+	}
 	var fs big.Float
 	fs.Set(v.toType("encode", conf, bigFloatType).(BigFloat).Float)
 	t := time.Unix(secNsec(&fs))
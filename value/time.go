@@ -0,0 +1,231 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	"robpike.io/ivy/config"
+)
+
+// Time is a moment in time. Its literal form is a date, such as
+// 2024.01.15, optionally followed by a time of day, as in
+// 2024.01.15T10:30:00. The literal is interpreted, and the value is
+// printed, in the time zone set by )timezone unless it ends in Z, which
+// denotes UTC. Subtracting two times yields the seconds between them,
+// and adding seconds to a time yields a time.
+type Time struct {
+	time.Time
+}
+
+const (
+	timeDateLayout = "2006.01.02"
+	timeLayout     = "2006.01.02T15:04:05.999999999"
+)
+
+func (t Time) String() string {
+	return "(" + t.ProgString() + ")"
+}
+
+func (t Time) Rank() int {
+	return 0
+}
+
+// Hash returns the hash of the time, whatever its location.
+func (t Time) Hash() (uint64, bool) {
+	return hashCombine(hashCombine(hashTagTime, uint64(t.Unix())), uint64(t.Nanosecond())), true
+}
+
+func (t Time) shrink() Value {
+	return t
+}
+
+// Sprint prints the time in the configured time zone, omitting the
+// time of day if it is midnight.
+func (t Time) Sprint(conf *config.Config) string {
+	local := t.In(conf.LocationAt(t.Time))
+	if local.Hour() == 0 && local.Minute() == 0 && local.Second() == 0 && local.Nanosecond() == 0 {
+		return local.Format(timeDateLayout)
+	}
+	return local.Format(timeLayout)
+}
+
+// ProgString prints the time in UTC, so it reads back the same
+// regardless of the time zone.
+func (t Time) ProgString() string {
+	return t.UTC().Format(timeLayout) + "Z"
+}
+
+func (t Time) Eval(Context) Value {
+	return t
+}
+
+func (t Time) Inner() Value {
+	return t
+}
+
+func (t Time) toType(op string, conf *config.Config, which valueType) Value {
+	switch which {
+	case timeType:
+		return t
+	case vectorType:
+		return oneElemVector(t)
+	case matrixType:
+		return NewMatrix([]int{1}, NewVector(t))
+	}
+	Errorf("%s: cannot convert time to %s", op, which)
+	return nil
+}
+
+// isTimeLiteral reports whether s has the form of a time literal
+// rather than a number: it begins with a date of digits separated
+// by two periods.
+func isTimeLiteral(s string) bool {
+	date, _, _ := strings.Cut(strings.TrimSuffix(s, "Z"), "T")
+	if strings.Count(date, ".") != 2 {
+		return false
+	}
+	for _, r := range date {
+		if r != '.' && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTime parses a time literal.
+func parseTime(conf *config.Config, s string) (Value, error) {
+	loc := conf.Location()
+	if str, ok := strings.CutSuffix(s, "Z"); ok {
+		s = str
+		loc = time.UTC
+	}
+	layout := timeDateLayout
+	if date, clock, ok := strings.Cut(s, "T"); ok {
+		layout += "T15:04"
+		if strings.Count(clock, ":") > 1 {
+			layout += ":05" // Parsing accepts fractional seconds without a layout.
+		}
+		s = date + "T" + clock
+	}
+	t, err := time.ParseInLocation(layout, s, loc)
+	if err != nil {
+		Errorf("bad time syntax: %q", s)
+	}
+	if loc != time.UTC {
+		// Use the zone offset in effect at that time, not now.
+		t, _ = time.ParseInLocation(layout, s, conf.LocationAt(t))
+	}
+	return Time{t}, nil
+}
+
+// timeOfSeconds returns the time the seconds value v after the Unix epoch.
+func timeOfSeconds(c Context, v Value) Value {
+	sec, nsec := secondsOf(c, "time", v)
+	return Time{time.Unix(sec, nsec)}
+}
+
+// secondsOf splits the seconds value v into whole seconds and nanoseconds.
+func secondsOf(c Context, op string, v Value) (sec, nsec int64) {
+	if whichType(v) > bigFloatType || whichType(v) == charType {
+		Errorf("%s: cannot use %s as seconds", op, whichType(v))
+	}
+	var r *big.Rat
+	if f, ok := v.(BigFloat); ok {
+		r, _ = f.Rat(nil)
+	} else {
+		r = v.toType(op, c.Config(), bigRatType).(BigRat).Rat
+	}
+	ns := new(big.Rat).Mul(r, big.NewRat(1e9, 1))
+	n := new(big.Int).Quo(ns.Num(), ns.Denom()) // Truncates toward zero.
+	s, ns9 := new(big.Int).QuoRem(n, big.NewInt(1e9), new(big.Int))
+	if !s.IsInt64() {
+		Errorf("%s: seconds value out of range", op)
+	}
+	return s.Int64(), ns9.Int64()
+}
+
+// secondsBetween returns the exact number of seconds from u to t.
+func secondsBetween(t, u time.Time) Value {
+	ns := big.NewInt(t.Unix() - u.Unix())
+	ns.Mul(ns, big.NewInt(1e9))
+	ns.Add(ns, big.NewInt(int64(t.Nanosecond()-u.Nanosecond())))
+	return BigRat{new(big.Rat).SetFrac(ns, big.NewInt(1e9))}.shrink()
+}
+
+// addSeconds returns t plus the seconds value v.
+func addSeconds(c Context, op string, t Time, v Value, sign int64) Value {
+	sec, nsec := secondsOf(c, op, v)
+	return Time{time.Unix(t.Unix()+sign*sec, int64(t.Nanosecond())+sign*nsec)}
+}
+
+// timeBinary implements the elementwise binary operators on a pair of
+// scalars, at least one of which is a Time. A time may be compared with
+// another, or may have seconds added or subtracted; the difference of two
+// times is the number of seconds between them.
+func timeBinary(c Context, u Value, op string, v Value) Value {
+	if fn := calendarOps[op]; fn != nil {
+		return fn(c, u, v)
+	}
+	if op == "cmp" {
+		return threeWay(c, u, v)
+	}
+	ut, uIsTime := u.(Time)
+	vt, vIsTime := v.(Time)
+	switch {
+	case uIsTime && vIsTime:
+		cmp := ut.Compare(vt.Time)
+		switch op {
+		case "-":
+			return secondsBetween(ut.Time, vt.Time)
+		case "==":
+			return toInt(cmp == 0)
+		case "!=":
+			return toInt(cmp != 0)
+		case "<":
+			return toInt(cmp < 0)
+		case "<=":
+			return toInt(cmp <= 0)
+		case ">":
+			return toInt(cmp > 0)
+		case ">=":
+			return toInt(cmp >= 0)
+		case "min":
+			if cmp <= 0 {
+				return ut
+			}
+			return vt
+		case "max":
+			if cmp >= 0 {
+				return ut
+			}
+			return vt
+		}
+	case uIsTime:
+		switch op {
+		case "+":
+			return addSeconds(c, op, ut, v, 1)
+		case "-":
+			return addSeconds(c, op, ut, v, -1)
+		case "==":
+			return zero
+		case "!=":
+			return one
+		}
+	default:
+		switch op {
+		case "+":
+			return addSeconds(c, op, vt, u, 1)
+		case "==":
+			return zero
+		case "!=":
+			return one
+		}
+	}
+	Errorf("binary %s not implemented on type time", op)
+	panic("not reached")
+}
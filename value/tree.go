@@ -0,0 +1,81 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"fmt"
+	"strings"
+
+	"robpike.io/ivy/config"
+)
+
+// treeSprint returns the formatting of v for )display tree. Each vector
+// or matrix is shown as a line giving its shape, followed by its elements
+// indented beneath it; the elements of a matrix are labeled with their
+// indexes. A vector or matrix with no nested elements is shown as usual.
+func treeSprint(conf *config.Config, v Value) string {
+	var lines []string
+	writeTree(conf, &lines, v, "", "")
+	return strings.Join(lines, "\n")
+}
+
+// writeTree appends to lines the tree for v, with each line indented and
+// the label before the first.
+func writeTree(conf *config.Config, lines *[]string, v Value, label, indent string) {
+	prefix := indent + label
+	inner := indent + blanks(len(label)) + "  " // Indentation of the elements.
+	var shape []int
+	var data *Vector
+	switch v := v.(type) {
+	case *Vector:
+		shape, data = []int{v.Len()}, v
+	case *Matrix:
+		shape, data = v.shape, v.data
+	default:
+		*lines = append(*lines, prefix+v.Sprint(conf))
+		return
+	}
+	kind := "vector"
+	if len(shape) > 1 {
+		kind = "matrix"
+	}
+	head := prefix + kind + " " + strings.Trim(fmt.Sprint(shape), "[]")
+	if data.allScalars() {
+		body := strings.Split(v.Sprint(conf), "\n")
+		if len(shape) == 1 {
+			// A simple vector fits on the line with its shape.
+			*lines = append(*lines, strings.TrimRight(head+": "+body[0], " "))
+			body = body[1:]
+		} else {
+			*lines = append(*lines, head)
+		}
+		for _, line := range body {
+			*lines = append(*lines, strings.TrimRight(inner+line, " "))
+		}
+		return
+	}
+	*lines = append(*lines, head)
+	index := make([]int, len(shape))
+	for _, elem := range data.All() {
+		elemLabel := ""
+		if len(shape) > 1 {
+			elemLabel = "["
+			for k, i := range index {
+				if k > 0 {
+					elemLabel += " "
+				}
+				elemLabel += fmt.Sprint(i + conf.Origin())
+			}
+			elemLabel += "] "
+		}
+		writeTree(conf, lines, elem, elemLabel, inner)
+		for k := len(index) - 1; k >= 0; k-- {
+			if index[k]++; index[k] < shape[k] {
+				break
+			}
+			index[k] = 0
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"slices"
 )
 
 // Unary operators.
@@ -118,7 +119,19 @@ func floatSelf(c Context, v Value) BigFloat {
 // text returns a vector of Chars holding the string representation
 // of the value.
 func text(c Context, v Value) Value {
-	return newCharVector(v.Sprint(c.Config()))
+	// The result is data, so it must hold all of v
+	// even if large values are elided when displayed.
+	conf := c.Config()
+	rows, cols := conf.MaxRows(), conf.MaxCols()
+	if rows != 0 || cols != 0 {
+		conf.SetMaxRows(0)
+		conf.SetMaxCols(0)
+		defer func() {
+			conf.SetMaxRows(rows)
+			conf.SetMaxCols(cols)
+		}()
+	}
+	return newCharVector(v.Sprint(conf))
 }
 
 // newCharVector takes a string and returns its representation as a Vector of Chars.
@@ -140,6 +153,70 @@ func printValue(c Context, v Value) Value {
 	return QuietValue{v}
 }
 
+// typeOf returns the name of the type of v as text.
+func typeOf(c Context, v Value) Value {
+	return newCharVector(whichType(v).String())
+}
+
+// arity returns the ways the named op, builtin or user-defined, may be
+// invoked: a vector holding 1 if it is unary and 2 if it is binary.
+// The vector is empty if there is no such op.
+func arity(c Context, op string) Value {
+	var n []int
+	if UnaryOps[op] != nil || c.UserDefined(op, false) {
+		n = append(n, 1)
+	}
+	if BinaryOps[op] != nil || c.UserDefined(op, true) {
+		n = append(n, 2)
+	}
+	return NewIntVector(n...)
+}
+
+// cumMax returns the running maximum of v, as for max\ v.
+func cumMax(c Context, v Value) Value {
+	return Scan(c, "max", v)
+}
+
+// cumMin returns the running minimum of v, as for min\ v.
+func cumMin(c Context, v Value) Value {
+	return Scan(c, "min", v)
+}
+
+// diff returns the successive differences of v, each element minus
+// the one before, along the last axis. A scalar has none.
+func diff(c Context, v Value) Value {
+	switch v := v.(type) {
+	case *Vector:
+		if v.Len() == 0 {
+			return v
+		}
+		return diffVector(c, v, 0, v.Len())
+	case *Matrix:
+		n := v.shape[len(v.shape)-1]
+		if n == 0 {
+			return v
+		}
+		data := newVectorEditor(0, nil)
+		for i := 0; i < v.data.Len(); i += n {
+			data.Append(diffVector(c, v.data, i, i+n).ro...)
+		}
+		shape := slices.Clone(v.shape)
+		shape[len(shape)-1] = n - 1
+		return NewMatrix(shape, data.Publish())
+	}
+	return empty
+}
+
+// diffVector returns the successive differences of v[lo:hi], which
+// is not empty.
+func diffVector(c Context, v *Vector, lo, hi int) *Vector {
+	result := newVectorEditor(hi-lo-1, nil)
+	for i := lo + 1; i < hi; i++ {
+		result.Set(i-lo-1, c.EvalBinary(v.At(i), "-", v.At(i-1)))
+	}
+	return result.Publish()
+}
+
 // bigFloatRand returns a uniformly distributed BigFloat in the range [0, f).
 // For [0, 1), the mean should be 0.5 and 𝛔 should be 1/√12, or 0.2887.
 // A test of a million values yielded 0.500161824174 0.288777488704.
@@ -185,6 +262,13 @@ func init() {
 					}
 					return unaryBigIntOp(c, bigIntRand, v)
 				},
+				vectorType: func(c Context, v Value) Value {
+					return rollVector(c, v.(*Vector))
+				},
+				matrixType: func(c Context, v Value) Value {
+					m := v.(*Matrix)
+					return NewMatrix(m.shape, rollVector(c, m.data))
+				},
 			},
 		},
 
@@ -406,6 +490,9 @@ func init() {
 				intType: func(c Context, v Value) Value {
 					return BigInt{factorial(int64(v.(Int)))}.shrink()
 				},
+				bigRatType:   factorialGamma,
+				bigFloatType: factorialGamma,
+				complexType:  factorialGamma,
 			},
 		},
 
@@ -528,6 +615,30 @@ func init() {
 			},
 		},
 
+		{
+			name:        "isinf",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      returnZero,
+				bigIntType:   returnZero,
+				bigRatType:   returnZero,
+				bigFloatType: func(c Context, v Value) Value { return toInt(isInf(v)) },
+				complexType:  func(c Context, v Value) Value { return toInt(isInf(v)) },
+			},
+		},
+
+		{
+			name:        "isnan",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      returnZero,
+				bigIntType:   returnZero,
+				bigRatType:   returnZero,
+				bigFloatType: func(c Context, v Value) Value { return toInt(isNaN(v)) },
+				complexType:  func(c Context, v Value) Value { return toInt(isNaN(v)) },
+			},
+		},
+
 		{
 			name:        "floor",
 			elementwise: true,
@@ -557,6 +668,9 @@ func init() {
 				bigFloatType: func(c Context, v Value) Value {
 					f := v.(BigFloat)
 					if f.Float.IsInf() {
+						if c.Config().IEEE() {
+							return f
+						}
 						Errorf("floor of %s", v.Sprint(c.Config()))
 					}
 					i, acc := f.Int(nil)
@@ -604,6 +718,9 @@ func init() {
 				bigFloatType: func(c Context, v Value) Value {
 					f := v.(BigFloat)
 					if f.Float.IsInf() {
+						if c.Config().IEEE() {
+							return f
+						}
 						Errorf("ceil of %s", v.Sprint(c.Config()))
 					}
 					i, acc := f.Int(nil)
@@ -625,6 +742,7 @@ func init() {
 			name: "iota",
 			fn: [numType]unaryFn{
 				intType: func(c Context, v Value) Value {
+					checkElems(c, int64(v.(Int)))
 					return newIota(c.Config().Origin(), int(v.(Int)))
 				},
 				vectorType: func(c Context, v Value) Value {
@@ -634,7 +752,9 @@ func init() {
 						return empty
 					}
 					if vv.Len() == 1 {
-						return newIota(c.Config().Origin(), vv.intAt(0, "iota argument"))
+						n := vv.intAt(0, "iota argument")
+						checkElems(c, int64(n))
+						return newIota(c.Config().Origin(), n)
 					}
 					nElems := 1
 					shape := make([]int, vv.Len())
@@ -649,6 +769,7 @@ func init() {
 							Errorf("shape too large in iota %s", vv)
 						}
 					}
+					checkElems(c, int64(nElems))
 					origin := c.Config().Origin()
 					elems := newVectorEditor(nElems, nil)
 					counter := make([]int, vv.Len())
@@ -795,6 +916,25 @@ func init() {
 				},
 			},
 		},
+		{
+			name: "raze",
+			fn: [numType]unaryFn{
+				intType:      self,
+				charType:     self,
+				bigIntType:   self,
+				bigRatType:   self,
+				bigFloatType: self,
+				complexType:  self,
+				timeType:     self,
+				vectorType: func(c Context, v Value) Value {
+					return v.(*Vector).raze(c)
+				},
+				matrixType: func(c Context, v Value) Value {
+					return v.(*Matrix).raze(c)
+				},
+			},
+		},
+
 		{
 			name: "flatten",
 			fn: [numType]unaryFn{
@@ -847,6 +987,7 @@ func init() {
 				bigRatType:   vectorSelf,
 				bigFloatType: vectorSelf,
 				complexType:  vectorSelf,
+				timeType:     vectorSelf,
 				vectorType:   self,
 				matrixType: func(c Context, v Value) Value {
 					return v.(*Matrix).data
@@ -888,6 +1029,59 @@ func init() {
 			},
 		},
 
+		{
+			name: "group",
+			fn: [numType]unaryFn{
+				intType:      groupIndexes,
+				charType:     groupIndexes,
+				bigIntType:   groupIndexes,
+				bigRatType:   groupIndexes,
+				bigFloatType: groupIndexes,
+				complexType:  groupIndexes,
+				timeType:     groupIndexes,
+				vectorType:   groupIndexes,
+				matrixType:   groupIndexes,
+			},
+		},
+
+		{
+			name: "sort",
+			fn: [numType]unaryFn{
+				intType:      self,
+				charType:     self,
+				bigIntType:   self,
+				bigRatType:   self,
+				bigFloatType: self,
+				complexType:  self,
+				timeType:     self,
+				vectorType: func(c Context, v Value) Value {
+					return sortValue(c, v, false)
+				},
+				matrixType: func(c Context, v Value) Value {
+					return sortValue(c, v, false)
+				},
+			},
+		},
+
+		{
+			name: "rsort",
+			fn: [numType]unaryFn{
+				intType:      self,
+				charType:     self,
+				bigIntType:   self,
+				bigRatType:   self,
+				bigFloatType: self,
+				complexType:  self,
+				timeType:     self,
+				vectorType: func(c Context, v Value) Value {
+					return sortValue(c, v, true)
+				},
+				matrixType: func(c Context, v Value) Value {
+					return sortValue(c, v, true)
+				},
+			},
+		},
+
 		{
 			name: "rot",
 			fn: [numType]unaryFn{
@@ -897,6 +1091,7 @@ func init() {
 				bigRatType:   self,
 				bigFloatType: self,
 				complexType:  self,
+				timeType:     self,
 				vectorType: func(c Context, v Value) Value {
 					return v.(*Vector).reverse()
 				},
@@ -932,6 +1127,7 @@ func init() {
 				bigRatType:   self,
 				bigFloatType: self,
 				complexType:  self,
+				timeType:     self,
 				vectorType: func(c Context, v Value) Value {
 					return v.(*Vector).reverse()
 				},
@@ -971,6 +1167,7 @@ func init() {
 				bigRatType:   self,
 				bigFloatType: self,
 				complexType:  self,
+				timeType:     self,
 				vectorType:   self,
 				matrixType: func(c Context, v Value) Value {
 					m := v.(*Matrix)
@@ -1162,6 +1359,24 @@ func init() {
 			},
 		},
 
+		{
+			name:        "isqrt",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:    isqrt,
+				bigIntType: isqrt,
+			},
+		},
+
+		{
+			name:        "ispower",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:    isPower,
+				bigIntType: isPower,
+			},
+		},
+
 		{
 			name:        "char",
 			elementwise: true,
@@ -1186,6 +1401,7 @@ func init() {
 				bigRatType:   func(c Context, v Value) Value { return text(c, v) },
 				bigFloatType: func(c Context, v Value) Value { return text(c, v) },
 				complexType:  func(c Context, v Value) Value { return text(c, v) },
+				timeType:     func(c Context, v Value) Value { return text(c, v) },
 				vectorType:   func(c Context, v Value) Value { return text(c, v) },
 				matrixType:   func(c Context, v Value) Value { return text(c, v) },
 			},
@@ -1209,6 +1425,37 @@ func init() {
 			},
 		},
 
+		{
+			name: "type",
+			fn: [numType]unaryFn{
+				intType:      typeOf,
+				charType:     typeOf,
+				bigIntType:   typeOf,
+				bigRatType:   typeOf,
+				bigFloatType: typeOf,
+				complexType:  typeOf,
+				timeType:     typeOf,
+				vectorType:   typeOf,
+				matrixType:   typeOf,
+			},
+		},
+
+		{
+			name: "arity",
+			fn: [numType]unaryFn{
+				charType: func(c Context, v Value) Value {
+					return arity(c, string(v.(Char)))
+				},
+				vectorType: func(c Context, v Value) Value {
+					text := v.(*Vector)
+					if !text.AllChars() {
+						Errorf("arity: value is not a vector of char")
+					}
+					return arity(c, vecText(text))
+				},
+			},
+		},
+
 		{
 			name:        "float",
 			elementwise: true,
@@ -1224,6 +1471,198 @@ func init() {
 			},
 		},
 
+		{
+			name:        "time",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      timeOfSeconds,
+				bigIntType:   timeOfSeconds,
+				bigRatType:   timeOfSeconds,
+				bigFloatType: timeOfSeconds,
+				timeType:     self,
+			},
+		},
+
+		{
+			name: "upper",
+			fn: [numType]unaryFn{
+				charType:   upper,
+				vectorType: upper,
+			},
+		},
+
+		{
+			name: "lower",
+			fn: [numType]unaryFn{
+				charType:   lower,
+				vectorType: lower,
+			},
+		},
+
+		{
+			name: "nfc",
+			fn: [numType]unaryFn{
+				charType:   nfc,
+				vectorType: nfc,
+			},
+		},
+
+		{
+			name: "nfd",
+			fn: [numType]unaryFn{
+				charType:   nfd,
+				vectorType: nfd,
+			},
+		},
+
+		{
+			name: "decimal",
+			fn: [numType]unaryFn{
+				intType:    decimal,
+				bigIntType: decimal,
+				bigRatType: decimal,
+				vectorType: decimal,
+			},
+		},
+
+		{
+			name: "baltern",
+			fn: [numType]unaryFn{
+				intType:    baltern,
+				bigIntType: baltern,
+				vectorType: baltern,
+			},
+		},
+
+		{
+			name: "plot",
+			fn: [numType]unaryFn{
+				intType:      plot,
+				bigIntType:   plot,
+				bigRatType:   plot,
+				bigFloatType: plot,
+				vectorType:   plot,
+				matrixType:   plot,
+			},
+		},
+
+		{
+			name: "hex",
+			fn: [numType]unaryFn{
+				intType:    hexEncode,
+				charType:   hexEncode,
+				vectorType: hexEncode,
+			},
+		},
+
+		{
+			name: "unhex",
+			fn: [numType]unaryFn{
+				charType:   hexDecode,
+				vectorType: hexDecode,
+			},
+		},
+
+		{
+			name: "base64",
+			fn: [numType]unaryFn{
+				intType:    base64Encode,
+				charType:   base64Encode,
+				vectorType: base64Encode,
+			},
+		},
+
+		{
+			name: "unbase64",
+			fn: [numType]unaryFn{
+				charType:   base64Decode,
+				vectorType: base64Decode,
+			},
+		},
+
+		{
+			name:        "popcount",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:    popcount,
+				bigIntType: popcount,
+			},
+		},
+
+		{
+			name:        "bitlen",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:    bitlen,
+				bigIntType: bitlen,
+			},
+		},
+
+		{
+			name:        "weekday",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				timeType: weekday,
+			},
+		},
+
+		{
+			name: "cross",
+			fn: [numType]unaryFn{
+				vectorType: crossAll,
+			},
+		},
+
+		{
+			name: "cummax",
+			fn: [numType]unaryFn{
+				intType:      cumMax,
+				bigIntType:   cumMax,
+				bigRatType:   cumMax,
+				bigFloatType: cumMax,
+				vectorType:   cumMax,
+				matrixType:   cumMax,
+			},
+		},
+
+		{
+			name: "cummin",
+			fn: [numType]unaryFn{
+				intType:      cumMin,
+				bigIntType:   cumMin,
+				bigRatType:   cumMin,
+				bigFloatType: cumMin,
+				vectorType:   cumMin,
+				matrixType:   cumMin,
+			},
+		},
+
+		{
+			name: "diff",
+			fn: [numType]unaryFn{
+				intType:      diff,
+				bigIntType:   diff,
+				bigRatType:   diff,
+				bigFloatType: diff,
+				complexType:  diff,
+				timeType:     diff,
+				vectorType:   diff,
+				matrixType:   diff,
+			},
+		},
+
+		{
+			name: "hist",
+			fn: [numType]unaryFn{
+				intType:      autoHist,
+				bigIntType:   autoHist,
+				bigRatType:   autoHist,
+				bigFloatType: autoHist,
+				vectorType:   autoHist,
+				matrixType:   autoHist,
+			},
+		},
+
 		{
 			name:        "unique",
 			elementwise: false,
@@ -1234,6 +1673,7 @@ func init() {
 				bigRatType:   unique,
 				bigFloatType: unique,
 				complexType:  unique,
+				timeType:     unique,
 				vectorType:   unique,
 			},
 		},
@@ -1248,11 +1688,28 @@ func init() {
 				bigRatType:   box,
 				bigFloatType: box,
 				complexType:  box,
+				timeType:     box,
 				vectorType:   box,
 				matrixType:   box,
 			},
 		},
 
+		{
+			name:        "depth",
+			elementwise: false,
+			fn: [numType]unaryFn{
+				intType:      depth,
+				charType:     depth,
+				bigIntType:   depth,
+				bigRatType:   depth,
+				bigFloatType: depth,
+				complexType:  depth,
+				timeType:     depth,
+				vectorType:   depth,
+				matrixType:   depth,
+			},
+		},
+
 		{
 			name:        "first",
 			elementwise: false,
@@ -1263,17 +1720,18 @@ func init() {
 				bigRatType:   self,
 				bigFloatType: self,
 				complexType:  self,
+				timeType:     self,
 				vectorType: func(c Context, v Value) Value {
 					u := v.(*Vector)
 					if u.Len() == 0 {
-						return zero // TODO: If we add prototypes, this is a place it matters.
+						return fillValue(u)
 					}
 					return u.At(0)
 				},
 				matrixType: func(c Context, v Value) Value {
 					u := v.(*Matrix).data
 					if u.Len() == 0 {
-						return zero // TODO: If we add prototypes, this is a place it matters.
+						return fillValue(u)
 					}
 					return u.At(0)
 				},
@@ -1292,4 +1750,9 @@ func init() {
 	for _, op := range ops {
 		UnaryOps[op.name] = op
 	}
+
+	// Unary sel is a synonym for where, as sel b is (,b) sel , iota rho b.
+	sel := *UnaryOps["where"].(*unaryOp)
+	sel.name = "sel"
+	UnaryOps["sel"] = &sel
 }
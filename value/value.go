@@ -31,6 +31,10 @@ type Value interface {
 	// Rank returns the rank of the value: 0 for scalar, 1 for vector, etc.
 	Rank() int
 
+	// Hash returns a hash of the value and reports whether the value
+	// has one. Equal values that have hashes have equal hashes.
+	Hash() (uint64, bool)
+
 	// shrink returns a simpler form of the value, such as an
 	// integer for an integral BigFloat. For some types it is
 	// the identity. It does not modify the receiver.
@@ -45,15 +49,64 @@ type Value interface {
 }
 
 // Error is the type we recognize as a recoverable run-time error.
-type Error string
+// Besides the message, it records where the error happened: the
+// input location, filled in when the error is reported, and the
+// user-defined ops that were active, filled in as the stack unwinds.
+type Error struct {
+	Msg    string
+	File   string   // Name of the input, if known.
+	Line   int      // Line number in the input, if known.
+	Stack  []string // Active user-defined ops, innermost first.
+	Elided int      // Number of ops omitted from Stack because it was too deep.
+}
+
+// maxErrorStack bounds the length of Error.Stack so a stack overflow
+// does not produce an enormous traceback.
+const maxErrorStack = 50
 
 func (err Error) Error() string {
-	return string(err)
+	return err.Msg
+}
+
+// Op returns the name of the innermost user-defined op active
+// when the error occurred, or the empty string if there was none.
+func (err Error) Op() string {
+	if len(err.Stack) == 0 {
+		return ""
+	}
+	return err.Stack[0]
+}
+
+// AddCaller records that the error passed through the user-defined op
+// with the given name. The ops are added innermost first.
+func (err *Error) AddCaller(name string) {
+	if len(err.Stack) < maxErrorStack {
+		err.Stack = append(err.Stack, name)
+	} else {
+		err.Elided++
+	}
+}
+
+// Traceback returns a multi-line description of the error: the location
+// and message, followed by the active user-defined ops, innermost first.
+func (err Error) Traceback() string {
+	var b strings.Builder
+	if err.File != "" {
+		fmt.Fprintf(&b, "%s:%d: ", err.File, err.Line)
+	}
+	b.WriteString(err.Msg)
+	for _, name := range err.Stack {
+		fmt.Fprintf(&b, "\n\tin %s", name)
+	}
+	if err.Elided > 0 {
+		fmt.Fprintf(&b, "\n\t... and %d more", err.Elided)
+	}
+	return b.String()
 }
 
 // Errorf panics with the formatted string, with type Error.
 func Errorf(format string, args ...interface{}) {
-	panic(Error(fmt.Sprintf(format, args...)))
+	panic(Error{Msg: fmt.Sprintf(format, args...)})
 }
 
 func parseTwo(conf *config.Config, s string) (Value, Value, string, error) {
@@ -85,6 +138,10 @@ func parseTwo(conf *config.Config, s string) (Value, Value, string, error) {
 }
 
 func Parse(conf *config.Config, s string) (Value, error) {
+	s = strings.ReplaceAll(s, "¯", "-") // APL high minus.
+	if isTimeLiteral(s) {
+		return parseTime(conf, s)
+	}
 	// Is it a complex or rational?
 	v1, v2, sep, err := parseTwo(conf, s)
 	if err != nil {
@@ -117,6 +174,13 @@ func Parse(conf *config.Config, s string) (Value, error) {
 	if err == nil {
 		return b.shrink(), nil
 	}
+	if base := conf.InputBase(); base != 0 && base != 10 {
+		r, err := setBigRatFromBaseString(conf, s)
+		if err != nil {
+			return nil, err
+		}
+		return r.shrink(), nil
+	}
 	r, err := setBigRatFromFloatString(s) // We know there is no slash.
 	if err == nil {
 		return r.shrink(), nil
@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"unicode/utf8"
 
 	"robpike.io/ivy/config"
 )
@@ -48,6 +49,15 @@ type vectorEditor struct {
 	data []Value
 }
 
+// checkElems errors out if n is more than the maximum number of elements
+// the configuration allows in a vector or matrix. Operations that can
+// build results larger than their operands call it before allocating.
+func checkElems(c Context, n int64) {
+	if max := int64(c.Config().MaxElems()); max != 0 && n > max {
+		Errorf("result too large (%d elements, max %d)", n, max)
+	}
+}
+
 // newVectorEditor returns a vectorEditor editing a vector of length size
 // with all elements set to def.
 func newVectorEditor(size int, def Value) *vectorEditor {
@@ -123,8 +133,17 @@ func repeat(v Value, n int) iter.Seq2[int, Value] {
 
 // Sprint returns the formatting of v according to conf.
 func (v *Vector) Sprint(conf *config.Config) string {
+	if conf.Raw() {
+		return rawSprint(conf, v)
+	}
+	if conf.Tree() && !v.allScalars() {
+		return treeSprint(conf, v)
+	}
 	allChars := v.AllChars()
-	lines, _ := v.multiLineSprint(conf, v.allScalars(), allChars, !allChars, trimTrailingSpace)
+	if n := conf.MaxCols(); n > 0 && v.Len() > n && !allChars {
+		return v.elidedSprint(conf, n)
+	}
+	lines := v.multiLineSprint(conf, v.allScalars(), allChars, !allChars, trimTrailingSpace)
 	switch len(lines) {
 	case 0:
 		return ""
@@ -142,10 +161,53 @@ func (v *Vector) Sprint(conf *config.Config) string {
 	}
 }
 
+// elidedSprint returns the formatting of v, which has more than max
+// elements, with "..." in place of all but the first and last ones.
+func (v *Vector) elidedSprint(conf *config.Config, n int) string {
+	head := (n + 1) / 2
+	left := strings.Split(NewVectorSeq(v.Slice(0, head)).Sprint(conf), "\n")
+	right := []string{""}
+	if head < n {
+		right = strings.Split(NewVectorSeq(v.Slice(v.Len()-(n-head), v.Len())).Sprint(conf), "\n")
+	}
+	wid := 0
+	for _, line := range left {
+		wid = max(wid, len(line))
+	}
+	var b strings.Builder
+	for i := range max(len(left), len(right)) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		var line strings.Builder
+		if i < len(left) {
+			line.WriteString(left[i])
+			line.WriteString(blanks(wid - len(left[i])))
+		} else {
+			line.WriteString(blanks(wid))
+		}
+		if i == 0 {
+			line.WriteString(" ... ")
+		} else {
+			line.WriteString("     ")
+		}
+		if i < len(right) {
+			line.WriteString(right[i])
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+	}
+	return b.String()
+}
+
 func (v *Vector) Rank() int {
 	return 1
 }
 
+// Hash reports that a vector has no hash; only scalars are hashed.
+func (v *Vector) Hash() (uint64, bool) {
+	return 0, false
+}
+
 func (v *Vector) ProgString() string {
 	// There is no such thing as a vector in program listings; they
 	// are represented as a VectorExpr.
@@ -192,106 +254,96 @@ func (v *Vector) oneLineSprint(conf *config.Config, parens, spaces bool) (string
 //	spaces: put spaces between elements.
 //	trim: remove trailing spaces from each line.
 //
-// If trim is not set, the lines are all of equal length, bytewise.
-//
-// The return values are the printed lines and, along the other axis,
-// byte positions after each column.
-func (v *Vector) multiLineSprint(conf *config.Config, allScalars, allChars, spaces, trim bool) ([]string, []int) {
+// If trim is not set, the lines are all of equal width.
+func (v *Vector) multiLineSprint(conf *config.Config, allScalars, allChars, spaces, trim bool) []string {
 	if allScalars {
 		// Easy case, might as well be efficient.
-		str, cols := v.oneLineSprint(conf, false, spaces)
-		return []string{str}, cols
+		str, _ := v.oneLineSprint(conf, false, spaces)
+		return []string{str}
 	}
-	cols := make([]int, v.Len())
 	if allChars {
 		// Special handling as the array may contain newlines.
 		// Ignore all the other flags.
-		// TODO: We can still get newlines for individual elements
-		// in the general case handled below.
 		b := strings.Builder{}
-		for i, c := range v.All() {
+		for _, c := range v.All() {
 			b.WriteRune(rune(c.Inner().(Char)))
-			cols[i] = b.Len()
 		}
-		return strings.Split(b.String(), "\n"), cols // We shouldn't need cols, but be safe.
+		return strings.Split(b.String(), "\n")
 	}
+	// Lay out each element as a block of lines of equal width and
+	// place the blocks side by side, extending the shorter ones with
+	// blank lines so every line stays the same width.
 	lines := []*strings.Builder{}
-	lastColumn := []int{} // For each line, last column with a non-padding character.
+	width := 0 // Width of every line so far.
 	for i, elem := range v.All() {
-		strs := strings.Split(elem.Sprint(conf), "\n")
-		if len(strs) > len(lines) {
-			wid := 0
-			for _, line := range lines {
-				if line.Len() > wid {
-					wid = line.Len()
-				}
-			}
-			leading := blanks(wid)
-			for j := range strs {
-				if j >= len(lines) {
-					lastColumn = append(lastColumn, 0)
-					lines = append(lines, &strings.Builder{})
-					if j > 0 {
-						lines[j].WriteString(leading)
-					}
-				}
-			}
+		block := elemLines(conf, elem, !allScalars)
+		for len(lines) < len(block) {
+			line := &strings.Builder{}
+			line.WriteString(blanks(width))
+			lines = append(lines, line)
 		}
 		if spaces && i > 0 {
 			for _, line := range lines {
 				line.WriteString(" ")
 			}
+			width++
 		}
-		doParens := !allScalars && !IsScalarType(elem)
-		if doParens {
-			lines[0].WriteString("(")
-			lastColumn[0] = lines[0].Len()
-		}
-		for n, s := range strs {
-			if s == "" {
-				if _, ok := elem.(*Matrix); ok {
-					// Blank line in matrix output; ignore
-					continue
-				}
-			}
-			line := lines[n]
-			if doParens && n > 0 {
-				line.WriteString("|")
-				lastColumn[n] = line.Len()
-			}
-			line.WriteString(s)
-			lastColumn[n] = line.Len()
-			if doParens && n < len(strs)-1 {
-				line.WriteString("|")
-				lastColumn[n] = line.Len()
-			}
-		}
-		cols[i] = lines[0].Len() // By construction all lines have same length.
-		if len(strs) < len(lines) {
-			// Right-fill the lines below this element.
-			padding := blanks(cols[i] - lines[len(lines)-1].Len())
-			for j := len(strs); j < len(lines); j++ {
-				lines[j].WriteString(padding)
-			}
-		}
-		if doParens {
-			last := len(strs) - 1
-			line := lines[last]
-			line.WriteString(")")
-			lastColumn[last] = line.Len()
-			if line.Len() > cols[i] {
-				cols[i] = line.Len()
+		blockWidth := textWidth(block[0])
+		for j, line := range lines {
+			if j < len(block) {
+				line.WriteString(block[j])
+			} else {
+				line.WriteString(blanks(blockWidth))
 			}
 		}
+		width += blockWidth
 	}
 	s := make([]string, len(lines))
 	for i := range s {
 		s[i] = lines[i].String()
 		if trim {
-			s[i] = s[i][:lastColumn[i]]
+			s[i] = strings.TrimRight(s[i], " ")
 		}
 	}
-	return s, cols
+	return s
+}
+
+// elemLines returns the lines of the formatted element, padded to equal
+// width. If parens is set and the element is not a scalar, the lines are
+// enclosed in parentheses, with bars marking the lines that continue it.
+// The blank lines that separate the planes of a matrix stay blank.
+func elemLines(conf *config.Config, elem Value, parens bool) []string {
+	strs := strings.Split(elem.Sprint(conf), "\n")
+	wid := 0
+	for _, s := range strs {
+		wid = max(wid, textWidth(s))
+	}
+	doParens := parens && !IsScalarType(elem)
+	_, isMatrix := elem.(*Matrix)
+	for j, s := range strs {
+		s += blanks(wid - textWidth(s))
+		switch {
+		case !doParens:
+		case s == blanks(wid) && isMatrix && len(strs) > 1:
+			s = blanks(wid + 2)
+		default:
+			open, close := "|", "|"
+			if j == 0 {
+				open = "("
+			}
+			if j == len(strs)-1 {
+				close = ")"
+			}
+			s = open + s + close
+		}
+		strs[j] = s
+	}
+	return strs
+}
+
+// textWidth returns the number of columns occupied by s.
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s)
 }
 
 var (
@@ -318,33 +370,17 @@ func blanks(n int) string {
 
 }
 
-// fillValue returns a zero or a space as the appropriate fill type for the data
+// fillValue returns the fill element for the data, used to pad it when
+// it is overtaken, expanded or reshaped from empty. As in APL, it is the
+// prototype of the first element, or zero if there is none.
 func fillValue(v *Vector) Value {
 	if v.Len() == 0 {
 		return zero
 	}
-	var fill Value = zero
-	if v.AllChars() {
-		fill = Char(' ')
-	}
-	first := v.At(0)
-	if IsScalarType(first) {
-		return fill
-	}
-	switch v := first.(type) {
-	case *Vector:
-		data := make([]Value, v.Len())
-		for i := range data {
-			data[i] = fill
-		}
-		return newVectorEditor(v.Len(), fill).Publish()
-	case *Matrix:
-		return NewMatrix(v.shape, newVectorEditor(v.data.Len(), fill).Publish())
-	}
-	return zero
+	return prototype(v.At(0))
 }
 
-// fillValue returns a zero or a space as the appropriate fill type for the vector
+// fillValue returns the fill element for the vector.
 func (v *Vector) fillValue() Value {
 	return fillValue(v)
 }
@@ -443,17 +479,18 @@ func (v *Vector) rotate(n int) Value {
 // integer or a vector of the same length as v. elemCount is the number of elements
 // we are to duplicate; this will be number of columns for a matrix's data.
 // If the count is negative, we replicate zeros of the appropriate shape.
-func (v *Vector) sel(n *Vector, elemCount int) *Vector {
+func (v *Vector) sel(c Context, n *Vector, elemCount int) *Vector {
 	if n.Len() != 1 && n.Len() != elemCount {
 		Errorf("sel length mismatch")
 	}
+	checkElems(c, selCount(n, v.Len()))
 	result := newVectorEditor(0, nil)
 	for i := range v.Len() {
 		count := n.intAt(i%n.Len(), "sel count")
 		val := v.At(i)
 		if count < 0 { // Thanks, APL.
 			count = -count
-			val = allZeros(val)
+			val = prototype(val)
 		}
 		for range count {
 			result.Append(val)
@@ -462,19 +499,121 @@ func (v *Vector) sel(n *Vector, elemCount int) *Vector {
 	return result.Publish()
 }
 
-// zeros returns a value with the shape of v, but all zeroed out.
-func allZeros(v Value) Value {
+// where returns the elements of v at the positions of the non-zero
+// elements of n, each repeated that many times, in a single pass.
+func (v *Vector) where(c Context, n *Vector) *Vector {
+	if n.Len() != v.Len() {
+		Errorf("where: length mismatch: %d %d", v.Len(), n.Len())
+	}
+	checkElems(c, whereCount(n))
+	result := newVectorEditor(0, nil)
+	for i := range n.Len() {
+		for range n.uintAt(i, "where argument") {
+			result.Append(v.At(i))
+		}
+	}
+	return result.Publish()
+}
+
+// selCount returns the number of elements sel selects from a vector of
+// length len by the counts in n.
+func selCount(n *Vector, len int) int64 {
+	var count int64
+	for i := range len {
+		k := int64(n.intAt(i%n.Len(), "sel count"))
+		count += max(k, -k)
+	}
+	return count
+}
+
+// whereCount returns the number of elements where selects by n, the sum
+// of its elements.
+func whereCount(n *Vector) int64 {
+	var count int64
+	for i := range n.Len() {
+		count += int64(n.uintAt(i, "where argument"))
+	}
+	return count
+}
+
+// fill returns v expanded according to the counts in n. Each positive count
+// consumes the next element of v and repeats it that many times; a zero or
+// negative count inserts that many fill elements, with zero inserting one.
+func (v *Vector) fill(c Context, n *Vector) *Vector {
+	if n.Len() == 0 {
+		return empty
+	}
+	count := fillCount(n, v.Len())
+	checkElems(c, count)
+	result := newVectorEditor(0, nil)
+	v.appendFill(result, n, fillValue(v))
+	return result.Publish()
+}
+
+// fillCount checks that the counts in n are small integers whose positive
+// entries match the length len of the data, and returns the resulting length.
+func fillCount(n *Vector, len int) int64 {
+	var count int64
+	numLeft := 0
+	for _, x := range n.All() {
+		y, ok := x.(Int)
+		if !ok {
+			Errorf("fill: left operand must be small integers")
+		}
+		switch {
+		case y == 0:
+			count++
+		case y < 0:
+			count -= int64(y)
+		default:
+			numLeft++
+			count += int64(y)
+		}
+	}
+	if numLeft != len {
+		Errorf("fill: count > 0 on left (%d) must equal length of right (%d)", numLeft, len)
+	}
+	return count
+}
+
+// appendFill appends to result the expansion of v according to the
+// counts in n, which have been checked by fillCount, inserting zeroVal
+// for zero and negative counts.
+func (v *Vector) appendFill(result *vectorEditor, n *Vector, zeroVal Value) {
+	jx := 0
+	for _, x := range n.All() {
+		y := x.(Int)
+		switch {
+		case y == 0:
+			result.Append(zeroVal)
+		case y < 0:
+			for y = -y; y > 0; y-- {
+				result.Append(zeroVal)
+			}
+		default:
+			for ; y > 0; y-- {
+				result.Append(v.At(jx))
+			}
+			jx++
+		}
+	}
+}
+
+// prototype returns a value with the structure of v but all zeroed out:
+// a blank for a char, zero for any other scalar, and for an array, an
+// array of the same shape holding the prototypes of its elements.
+func prototype(v Value) Value {
 	switch v := v.(type) {
 	case Char:
 		return Char(' ')
 	case *Vector:
 		u := newVectorEditor(v.Len(), nil)
 		for i := range u.Len() {
-			u.Set(i, allZeros(v.At(i)))
+			u.Set(i, prototype(v.At(i)))
 		}
 		return u.Publish()
 	case *Matrix:
-		return &Matrix{shape: v.shape, data: allZeros(v.data).(*Vector)}
+		return &Matrix{shape: v.shape, data: prototype(v.data).(*Vector)}
 	default:
 		return zero
 	}
@@ -547,6 +686,40 @@ func (v *Vector) doPartition(score *Vector) (*Vector, int) {
 	return result.Publish(), dim
 }
 
+// raze returns the items of v joined into a single vector, undoing a
+// partition: the elements of vector items, and scalar items themselves.
+// If any item is a matrix, the items are instead catenated along the
+// first axis.
+func (v *Vector) raze(c Context) Value {
+	result := newVectorEditor(0, nil)
+	for _, x := range v.All() {
+		switch x := x.(type) {
+		case *Vector:
+			for _, e := range x.All() {
+				result.Append(e)
+			}
+		case *Matrix:
+			return v.razeFirst(c)
+		default:
+			result.Append(x)
+		}
+	}
+	return result.Publish()
+}
+
+// razeFirst returns the items of v catenated along the first axis.
+func (v *Vector) razeFirst(c Context) Value {
+	var result Value
+	for i, x := range v.All() {
+		if i == 0 {
+			result = x
+			continue
+		}
+		result = c.EvalBinary(result, ",%", x)
+	}
+	return result
+}
+
 // grade returns as a Vector the indexes that sort the vector into increasing order
 func (v *Vector) grade(c Context) *Vector {
 	x := make([]int, v.Len())
@@ -607,9 +780,16 @@ func (v *Vector) inverse(c Context) Value {
 
 // membership creates a vector of size len(u) reporting
 // whether each element of u is an element of v.
-// Algorithm is O(nV log nV + nU log nV) where nU==len(u) and nV==len(V).
+// Algorithm is O(nV log nV + nU log nV) where nU==len(u) and nV==len(V),
+// or O(nU + nV) if the elements are hashable.
 func membership(c Context, u, v *Vector) *Vector {
 	values := newVectorEditor(u.Len(), nil)
+	if pos, ok := hashFind(c, v, u, 1); ok {
+		for i, p := range pos {
+			values.Set(i, toInt(p >= 0))
+		}
+		return values.Publish()
+	}
 	sortedV := v.sortedCopy(c)
 	work := 2 * (1 + int(math.Log2(float64(v.Len()))))
 	pfor(true, work, values.Len(), func(lo, hi int) {
@@ -620,6 +800,60 @@ func membership(c Context, u, v *Vector) *Vector {
 	return values.Publish()
 }
 
+// countIn returns, for each element of u, the number of times it
+// appears in v.
+func countIn(c Context, u, v *Vector) *Vector {
+	counts := newVectorEditor(u.Len(), nil)
+	sortedV := v.sortedCopy(c)
+	work := 4 * (1 + int(math.Log2(float64(v.Len()))))
+	pfor(true, work, counts.Len(), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			x := u.At(i)
+			first := sort.Search(sortedV.Len(), func(j int) bool {
+				return OrderedCompare(c, sortedV.At(j), x) >= 0
+			})
+			last := sort.Search(sortedV.Len(), func(j int) bool {
+				return OrderedCompare(c, sortedV.At(j), x) > 0
+			})
+			counts.Set(i, Int(last-first))
+		}
+	})
+	return counts.Publish()
+}
+
+// progressiveIndex returns, for each element of v, the index in u of its
+// next unused occurrence, so the second occurrence of a value in v finds
+// the second occurrence in u. Elements with no unused occurrence yield
+// origin-1, as for iota.
+func progressiveIndex(c Context, u, v *Vector) *Vector {
+	type indexed struct {
+		v     Value
+		index int
+	}
+	origin := c.Config().Origin()
+	sortedU := make([]indexed, u.Len())
+	for i, x := range u.All() {
+		sortedU[i] = indexed{x, i + origin}
+	}
+	sort.SliceStable(sortedU, func(i, j int) bool {
+		return OrderedCompare(c, sortedU[i].v, sortedU[j].v) < 0
+	})
+	used := make(map[int]int) // Occurrences used, by position of the first in sortedU.
+	indices := newVectorEditor(v.Len(), nil)
+	for i, x := range v.All() {
+		indices.Set(i, Int(origin-1))
+		pos := sort.Search(len(sortedU), func(j int) bool {
+			return OrderedCompare(c, sortedU[j].v, x) >= 0
+		})
+		next := pos + used[pos]
+		if next < len(sortedU) && OrderedCompare(c, sortedU[next].v, x) == 0 {
+			indices.Set(i, Int(sortedU[next].index))
+			used[pos]++
+		}
+	}
+	return indices.Publish()
+}
+
 type vectorByOrderedCompare struct {
 	c Context
 	e *vectorEditor